@@ -119,7 +119,7 @@ func executeListCreation(invoker nodemanager.OperationInvoker) {
 			fmt.Printf("   - %s: %s\n", errMsg.Field, errMsg.Message)
 		}
 	} else {
-		fmt.Printf("   ✅ Created list: %s (ID: %d)\n", result.Node.Title, result.Node.ID)
+		fmt.Printf("   ✅ Created list: %s (ID: %d)\n", result.Value.Title, result.Value.ID)
 	}
 }
 
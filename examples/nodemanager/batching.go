@@ -0,0 +1,60 @@
+package nodemanager
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// WithShowNodeBatching makes the bus batch ShowNodeQuery executions: when the
+// resolved NodeService also implements BatchNodeService, ShowNode calls made within
+// window of each other are served by a single BatchShowNode call. Services without
+// a batch method are called directly.
+func WithShowNodeBatching(window time.Duration) NodeManagerOption {
+	return func(b *NodeManagerBus) {
+		b.batchWindow = window
+	}
+}
+
+// BatchingNodeService implements NodeService by collecting ShowNode calls made
+// within a short window into a single BatchShowNode call.
+type BatchingNodeService struct {
+	batcher *commandment.Batcher[int64, Node]
+}
+
+// NewBatchingNodeService creates a NodeService batching ShowNode calls to service.
+// A bus created with WithShowNodeBatching wraps batch-capable services itself.
+func NewBatchingNodeService(service BatchNodeService, window time.Duration) *BatchingNodeService {
+	return &BatchingNodeService{
+		batcher: commandment.NewBatcher(window, service.BatchShowNode),
+	}
+}
+
+// ShowNode implements NodeService.ShowNode via the batcher.
+func (s *BatchingNodeService) ShowNode(ctx context.Context, params ShowNodeQueryParams) (Node, error) {
+	return s.batcher.Load(ctx, params.Ref)
+}
+
+// batchingService returns the batching NodeService shared by every query resolving
+// service, or service itself if batching is disabled or it has no batch method.
+// One batcher is kept per service, so a re-registered service gets its own.
+func (b *NodeManagerBus) batchingService(service NodeService) NodeService {
+	if b.batchWindow <= 0 {
+		return service
+	}
+	batch, ok := service.(BatchNodeService)
+	if !ok || !reflect.TypeOf(batch).Comparable() {
+		return service
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batching, ok := b.batching[batch]
+	if !ok {
+		batching = NewBatchingNodeService(batch, b.batchWindow)
+		b.batching[batch] = batching
+	}
+	return batching
+}
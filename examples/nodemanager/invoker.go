@@ -1,6 +1,12 @@
 package nodemanager
 
-import "github.com/davidlee/commandment/pkg/commandment"
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
 
 // QueryInvoker provides methods for creating read-only query operations.
 type QueryInvoker interface {
@@ -22,16 +28,35 @@ type OperationInvoker interface {
 // NodeManagerBus wraps the operation framework bus and provides domain-specific operation creation.
 type NodeManagerBus struct {
 	bus *commandment.OperationBus
+
+	batchWindow time.Duration
+	mu          sync.Mutex // guards batching
+	batching    map[BatchNodeService]*BatchingNodeService
 }
 
+// NodeManagerOption configures a NodeManagerBus.
+type NodeManagerOption func(*NodeManagerBus)
+
 // NewNodeManagerBus creates a new NodeManagerBus wrapping the operation framework.
-func NewNodeManagerBus(bus *commandment.OperationBus) *NodeManagerBus {
-	return &NodeManagerBus{bus: bus}
+func NewNodeManagerBus(bus *commandment.OperationBus, opts ...NodeManagerOption) *NodeManagerBus {
+	b := &NodeManagerBus{
+		bus:      bus,
+		batching: make(map[BatchNodeService]*BatchingNodeService),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 // NewShowNodeQuery creates a new ShowNodeQuery commandment.
 func (b *NodeManagerBus) NewShowNodeQuery(params ShowNodeQueryParams) (*ShowNodeQuery, error) {
-	return commandment.CreateOperation[*ShowNodeQuery](b.bus, params)
+	query, err := commandment.CreateOperation[*ShowNodeQuery](b.bus, params)
+	if err != nil {
+		return nil, err
+	}
+	query.Service = b.batchingService(query.Service)
+	return query, nil
 }
 
 // NewDisplayNodeTreeCommand creates a new DisplayNodeTreeCommand commandment.
@@ -43,3 +68,43 @@ func (b *NodeManagerBus) NewDisplayNodeTreeCommand(params DisplayNodeTreeCommand
 func (b *NodeManagerBus) NewCreateListCommand(params CreateListCommandParams) (*CreateListCommand, error) {
 	return commandment.CreateOperation[*CreateListCommand](b.bus, params)
 }
+
+// RegisterDescriptorFactories registers factories for every nodemanager operation,
+// so they can be recreated from serialized descriptors and jobs.
+func (b *NodeManagerBus) RegisterDescriptorFactories() {
+	b.bus.RegisterDescriptorFactory(commandment.QualifiedTypeName(&ShowNodeQuery{}), descriptorFactory(b.NewShowNodeQuery))
+	b.bus.RegisterDescriptorFactory(commandment.QualifiedTypeName(&DisplayNodeTreeCommand{}), descriptorFactory(b.NewDisplayNodeTreeCommand))
+	b.bus.RegisterDescriptorFactory(commandment.QualifiedTypeName(&CreateListCommand{}), descriptorFactory(b.NewCreateListCommand))
+}
+
+// RegisterResultTypes registers the result type of every nodemanager operation, so
+// generic clients can decode serialized results with DecodeResult.
+func (b *NodeManagerBus) RegisterResultTypes() {
+	commandment.RegisterResultType[Node](b.bus, commandment.QualifiedTypeName(&ShowNodeQuery{}))
+	commandment.RegisterResultType[NodeTree](b.bus, commandment.QualifiedTypeName(&DisplayNodeTreeCommand{}))
+	commandment.RegisterResultType[NodeCommandResult](b.bus, commandment.QualifiedTypeName(&CreateListCommand{}))
+}
+
+// RegisterOperationTypes adds every nodemanager operation to the bus catalog, which
+// also registers their result types.
+func (b *NodeManagerBus) RegisterOperationTypes() {
+	commandment.RegisterOperationType[*ShowNodeQuery](b.bus)
+	commandment.RegisterOperationType[*DisplayNodeTreeCommand](b.bus)
+	commandment.RegisterOperationType[*CreateListCommand](b.bus)
+}
+
+// descriptorFactory adapts an operation constructor to decode its params from JSON,
+// restoring the recorded metadata on the operation it creates.
+func descriptorFactory[P, TOp any](create func(P) (TOp, error)) commandment.DescriptorFactoryFunc {
+	return func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var p P
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		op, err := create(p)
+		if err != nil {
+			return nil, err
+		}
+		return commandment.RestoreMetadata(op, meta), nil
+	}
+}
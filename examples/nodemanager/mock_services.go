@@ -5,6 +5,9 @@ package nodemanager
 import (
 	"context"
 	"fmt"
+	"sync"
+
+	"github.com/davidlee/commandment/pkg/commandment"
 )
 
 // MockTreeService provides a mock implementation of TreeService.
@@ -17,6 +20,9 @@ func NewMockTreeService() *MockTreeService {
 
 // DisplayTree implements TreeService.DisplayTree with mock behavior.
 func (s *MockTreeService) DisplayTree(ctx context.Context, params DisplayNodeTreeCommandParams) (NodeTree, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeTree{}, err
+	}
 	nodes := []Node{
 		{ID: 1, Title: "Root Node", Description: "The root of the tree"},
 		{ID: 2, Title: "Child Node 1", Description: "First child"},
@@ -36,8 +42,12 @@ func (s *MockTreeService) DisplayTree(ctx context.Context, params DisplayNodeTre
 	}, nil
 }
 
-// MockListService provides a mock implementation of ListService.
-type MockListService struct{}
+// MockListService provides a mock implementation of ListService, remembering the
+// lists it has created.
+type MockListService struct {
+	mu    sync.Mutex
+	lists map[int64]Node
+}
 
 // NewMockListService creates a new MockListService.
 func NewMockListService() *MockListService {
@@ -46,6 +56,9 @@ func NewMockListService() *MockListService {
 
 // CreateList implements ListService.CreateList with mock behavior.
 func (s *MockListService) CreateList(ctx context.Context, params CreateListCommandParams) (NodeCommandResult, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeCommandResult{}, err
+	}
 	if params.Title == "" {
 		return NodeCommandResult{
 			Errors: []ValidationError{
@@ -60,12 +73,40 @@ func (s *MockListService) CreateList(ctx context.Context, params CreateListComma
 		Description: params.Description,
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lists == nil {
+		s.lists = make(map[int64]Node)
+	}
+	s.lists[node.ID] = node
+
 	return NodeCommandResult{
-		Node:   node,
+		Value:  node,
 		Errors: nil,
 	}, nil
 }
 
+// DeleteList implements ListService.DeleteList with mock behavior.
+func (s *MockListService) DeleteList(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.lists[id]; !ok {
+		return fmt.Errorf("list %d not found", id)
+	}
+	delete(s.lists, id)
+	return nil
+}
+
+// Lists returns the number of lists created and not deleted.
+func (s *MockListService) Lists() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.lists)
+}
+
 // MockNodeService provides a mock implementation of NodeService.
 type MockNodeService struct{}
 
@@ -75,16 +116,35 @@ func NewMockNodeService() *MockNodeService {
 }
 
 // ShowNode implements NodeService.ShowNode with mock behavior.
+// Calls are visible to the bus service interceptors.
 func (s *MockNodeService) ShowNode(ctx context.Context, params ShowNodeQueryParams) (Node, error) {
-	if params.Ref <= 0 {
-		return Node{}, fmt.Errorf("invalid node reference: %d", params.Ref)
-	}
+	return commandment.InterceptServiceCall[NodeService](ctx, "ShowNode", func() (Node, error) {
+		if err := ctx.Err(); err != nil {
+			return Node{}, err
+		}
+		if params.Ref <= 0 {
+			return Node{}, fmt.Errorf("invalid node reference: %d", params.Ref)
+		}
+
+		return Node{
+			ID:          params.Ref,
+			Title:       fmt.Sprintf("Node %d", params.Ref),
+			Description: fmt.Sprintf("This is node with ID %d", params.Ref),
+		}, nil
+	})
+}
 
-	return Node{
-		ID:          params.Ref,
-		Title:       fmt.Sprintf("Node %d", params.Ref),
-		Description: fmt.Sprintf("This is node with ID %d", params.Ref),
-	}, nil
+// BatchShowNode implements BatchNodeService.BatchShowNode with mock behavior.
+func (s *MockNodeService) BatchShowNode(ctx context.Context, refs []int64) ([]Node, error) {
+	nodes := make([]Node, 0, len(refs))
+	for _, ref := range refs {
+		node, err := s.ShowNode(ctx, ShowNodeQueryParams{Ref: ref})
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
 }
 
 func minInt(a, b int) int {
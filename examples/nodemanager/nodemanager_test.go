@@ -2,7 +2,15 @@ package nodemanager_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/davidlee/commandment/examples/nodemanager"
 	"github.com/davidlee/commandment/pkg/commandment"
@@ -16,6 +24,35 @@ func (l *TestLogger) Warn(msg string, keysAndValues ...any)  {}
 func (l *TestLogger) Error(msg string, keysAndValues ...any) {}
 func (l *TestLogger) Debug(msg string, keysAndValues ...any) {}
 
+// Logger capturing the fields of each message
+type CapturingLogger struct {
+	TestLogger
+	mu     sync.Mutex
+	fields map[string][]any
+}
+
+func (l *CapturingLogger) Info(msg string, keysAndValues ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.fields == nil {
+		l.fields = make(map[string][]any)
+	}
+	l.fields[msg] = keysAndValues
+}
+
+// Field returns the value logged under key with msg
+func (l *CapturingLogger) Field(msg, key string) (any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	kv := l.fields[msg]
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i] == key {
+			return kv[i+1], true
+		}
+	}
+	return nil, false
+}
+
 func TestNodeManagerBasicFlow(t *testing.T) {
 	// Setup framework
 	registry := commandment.NewServiceRegistry()
@@ -93,3 +130,915 @@ func TestQueryOnlyInterface(t *testing.T) {
 		t.Errorf("Expected node ID 42, got %d", result.ID)
 	}
 }
+
+func TestOversizedCreateListCommandRejected(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, nodemanager.NewMockListService())
+
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{})
+	operationBus.Use(commandment.MaxPayloadSize(1024))
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	params := nodemanager.CreateListCommandParams{
+		Title:       "Oversized",
+		Description: strings.Repeat("x", 2048),
+	}
+	cmd, err := nodeManagerBus.NewCreateListCommand(params)
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+
+	_, err = cmd.Execute(context.Background())
+	if !errors.Is(err, commandment.ErrPayloadTooLarge) {
+		t.Errorf("Expected ErrPayloadTooLarge, got %v", err)
+	}
+}
+
+// Versioned API representation of a node with renamed fields
+type NodeV2DTO struct {
+	NodeID  int64  `json:"node_id"`
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+}
+
+func TestShowNodeQueryResultConvertedForAPIVersion(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, nodemanager.NewMockNodeService())
+
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{})
+	commandment.RegisterResultConverter(operationBus, "v2", func(node nodemanager.Node) (NodeV2DTO, error) {
+		return NodeV2DTO{NodeID: node.ID, Name: node.Title, Summary: node.Description}, nil
+	})
+	commandment.RegisterResultVersion(operationBus, "v1")
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 7})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+
+	formatted, err := commandment.ExecuteFormatted(context.Background(), query, "v2")
+	if err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+	dto, ok := formatted.(NodeV2DTO)
+	if !ok {
+		t.Fatalf("Expected NodeV2DTO, got %T", formatted)
+	}
+	if dto.NodeID != 7 || dto.Name != "Node 7" {
+		t.Errorf("Unexpected converted node: %+v", dto)
+	}
+
+	body, err := commandment.ExecuteJSON(context.Background(), query, "v2")
+	if err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+	expected := `{"node_id":7,"name":"Node 7","summary":"This is node with ID 7"}`
+	if string(body) != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+
+	body, err = commandment.ExecuteJSON(context.Background(), query, "v1")
+	if err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+	if !strings.Contains(string(body), `"Title":"Node 7"`) {
+		t.Errorf("Expected unconverted node for version without a converter, got %s", body)
+	}
+}
+
+func TestCreateListCommandDescriptorRoundTripWithPointerParams(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, nodemanager.NewMockListService())
+
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+	nodeManagerBus.RegisterDescriptorFactories()
+
+	parentID := int64(7)
+	tests := []struct {
+		name     string
+		parentID *int64
+	}{
+		{"nil parent", nil},
+		{"with parent", &parentID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{
+				Title:    "Groceries",
+				ParentID: tt.parentID,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create command: %v", err)
+			}
+
+			data, err := json.Marshal(cmd.Descriptor())
+			if err != nil {
+				t.Fatalf("Failed to marshal descriptor: %v", err)
+			}
+			if _, err := cmd.Descriptor().Hash(); err != nil {
+				t.Fatalf("Failed to hash descriptor: %v", err)
+			}
+
+			var descriptor commandment.OperationDescriptor
+			if err := json.Unmarshal(data, &descriptor); err != nil {
+				t.Fatalf("Failed to unmarshal descriptor: %v", err)
+			}
+			recreated, err := operationBus.CreateFromDescriptor(descriptor)
+			if err != nil {
+				t.Fatalf("Failed to recreate command: %v", err)
+			}
+
+			got, ok := recreated.(*nodemanager.CreateListCommand)
+			if !ok {
+				t.Fatalf("Expected *CreateListCommand, got %T", recreated)
+			}
+			if got.Meta.UUID != cmd.Meta.UUID || !got.Meta.Created.Equal(cmd.Meta.Created) {
+				t.Errorf("Expected recreated command to keep metadata %+v, got %+v", cmd.Meta, got.Meta)
+			}
+			switch {
+			case tt.parentID == nil && got.Params.ParentID != nil:
+				t.Errorf("Expected nil ParentID, got %d", *got.Params.ParentID)
+			case tt.parentID != nil && (got.Params.ParentID == nil || *got.Params.ParentID != *tt.parentID):
+				t.Errorf("Expected ParentID %d, got %v", *tt.parentID, got.Params.ParentID)
+			}
+
+			if _, err := got.Execute(context.Background()); err != nil {
+				t.Errorf("Recreated command execution failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateListCommandDescriptorReencodesIdentically(t *testing.T) {
+	operationBus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+	nodeManagerBus.RegisterOperationTypes()
+
+	parentID := int64(7)
+	data, err := json.Marshal(commandment.OperationDescriptor{
+		Type:     "CreateListCommand",
+		Params:   nodemanager.CreateListCommandParams{Title: "Groceries", ParentID: &parentID},
+		Metadata: commandment.OperationMetadata{UUID: "op-1", Created: time.Date(2026, 3, 1, 12, 0, 0, 5, time.UTC)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+
+	descriptor, err := operationBus.DecodeDescriptor(data, nil)
+	if err != nil {
+		t.Fatalf("Failed to decode descriptor: %v", err)
+	}
+	params, ok := descriptor.Params.(nodemanager.CreateListCommandParams)
+	if !ok {
+		t.Fatalf("Expected CreateListCommandParams, got %T", descriptor.Params)
+	}
+	if params.Title != "Groceries" || params.ParentID == nil || *params.ParentID != parentID {
+		t.Errorf("Unexpected decoded params %+v", params)
+	}
+
+	again, err := json.Marshal(descriptor)
+	if err != nil {
+		t.Fatalf("Failed to re-marshal descriptor: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("Expected identical JSON after round-trip:\n%s\n%s", data, again)
+	}
+}
+
+func TestDisplayNodeTreeCommandMaxDepthDefault(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.TreeService](registry, nodemanager.NewMockTreeService())
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, &TestLogger{}))
+
+	cmd, err := nodeManagerBus.NewDisplayNodeTreeCommand(nodemanager.DisplayNodeTreeCommandParams{
+		RootReference: "test-root",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if cmd.Params.MaxDepth != 3 {
+		t.Errorf("Expected MaxDepth to default to 3, got %d", cmd.Params.MaxDepth)
+	}
+
+	explicit, err := nodeManagerBus.NewDisplayNodeTreeCommand(nodemanager.DisplayNodeTreeCommandParams{
+		RootReference: "test-root",
+		MaxDepth:      1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if explicit.Params.MaxDepth != 1 {
+		t.Errorf("Expected explicit MaxDepth to be kept, got %d", explicit.Params.MaxDepth)
+	}
+
+	if _, err := cmd.Execute(context.Background()); err != nil {
+		t.Errorf("Expected defaulted command to execute: %v", err)
+	}
+}
+
+func TestCreateListCommandRunsAsJob(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, nodemanager.NewMockListService())
+
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+	nodeManagerBus.RegisterDescriptorFactories()
+
+	cmd, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{
+		Title:       "Groceries",
+		Description: "Weekly shop",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+
+	data, err := json.Marshal(operationBus.ToJob(cmd))
+	if err != nil {
+		t.Fatalf("Failed to serialize job: %v", err)
+	}
+
+	job, err := operationBus.LoadJob(data)
+	if err != nil {
+		t.Fatalf("Failed to load job: %v", err)
+	}
+	if job.Descriptor.Type != commandment.QualifiedTypeName(&nodemanager.CreateListCommand{}) {
+		t.Errorf("Expected CreateListCommand job, got %q", job.Descriptor.Type)
+	}
+
+	out, err := job.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Job run failed: %v", err)
+	}
+	result, ok := out.(nodemanager.NodeCommandResult)
+	if !ok {
+		t.Fatalf("Expected NodeCommandResult, got %T", out)
+	}
+	if result.Value.Title != "Groceries" || result.Value.Description != "Weekly shop" {
+		t.Errorf("Unexpected job result: %+v", result.Value)
+	}
+}
+
+func TestJobDecodedWithoutBusIsUnbound(t *testing.T) {
+	var job commandment.Job
+	if err := json.Unmarshal([]byte(`{"descriptor":{"type":"CreateListCommand","params":{}}}`), &job); err != nil {
+		t.Fatalf("Failed to decode job: %v", err)
+	}
+	if _, err := job.Run(context.Background()); !errors.Is(err, commandment.ErrJobUnbound) {
+		t.Errorf("Expected ErrJobUnbound, got %v", err)
+	}
+}
+
+func TestDisplayNodeTreeCommandLogsRootReference(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.TreeService](registry, nodemanager.NewMockTreeService())
+	logger := &CapturingLogger{}
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, logger))
+
+	cmd, err := nodeManagerBus.NewDisplayNodeTreeCommand(nodemanager.DisplayNodeTreeCommandParams{
+		RootReference: "inbox",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := cmd.Execute(context.Background()); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	for _, msg := range []string{"Operation execution started", "Operation execution completed"} {
+		if ref, _ := logger.Field(msg, "root_reference"); ref != "inbox" {
+			t.Errorf("Expected %q log to include root_reference %q, got %v", msg, "inbox", ref)
+		}
+		if _, ok := logger.Field(msg, "max_depth"); ok {
+			t.Errorf("Expected untagged MaxDepth to be omitted from %q log", msg)
+		}
+	}
+}
+
+func TestResultObserverVetoesMatchingNode(t *testing.T) {
+	errRestricted := errors.New("restricted node")
+	restricted := regexp.MustCompile(`^Node 13$`)
+
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, nodemanager.NewMockNodeService())
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithResultObserver(func(ctx context.Context, descriptor commandment.OperationDescriptor, result any) error {
+			if node, ok := result.(nodemanager.Node); ok && restricted.MatchString(node.Title) {
+				return fmt.Errorf("%w: %s", errRestricted, node.Title)
+			}
+			return nil
+		}),
+	)
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	show := func(ref int64) (nodemanager.Node, error) {
+		query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: ref})
+		if err != nil {
+			t.Fatalf("Failed to create query: %v", err)
+		}
+		return query.Execute(context.Background())
+	}
+
+	node, err := show(13)
+	if !errors.Is(err, errRestricted) {
+		t.Errorf("Expected observer to veto restricted node, got %v", err)
+	}
+	if node != (nodemanager.Node{}) {
+		t.Errorf("Expected vetoed result to be withheld, got %+v", node)
+	}
+
+	if node, err := show(12); err != nil || node.Title != "Node 12" {
+		t.Errorf("Expected unrestricted node to pass, got %+v, %v", node, err)
+	}
+}
+
+// Node service that takes a while to answer
+type SlowNodeService struct {
+	nodemanager.MockNodeService
+	delay time.Duration
+}
+
+func (s *SlowNodeService) ShowNode(ctx context.Context, params nodemanager.ShowNodeQueryParams) (nodemanager.Node, error) {
+	select {
+	case <-time.After(s.delay):
+		return s.MockNodeService.ShowNode(ctx, params)
+	case <-ctx.Done():
+		return nodemanager.Node{}, ctx.Err()
+	}
+}
+
+func TestShowNodeQueryWithTimeout(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, &SlowNodeService{delay: time.Second})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, &TestLogger{}))
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 1})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+
+	bounded := commandment.WithTimeout[nodemanager.Node](query, 20*time.Millisecond)
+	node, err := bounded.Execute(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if node != (nodemanager.Node{}) {
+		t.Errorf("Expected zero node on timeout, got %+v", node)
+	}
+}
+
+// Batch node service counting batch calls
+type CountingBatchNodeService struct {
+	nodemanager.MockNodeService
+	mu      sync.Mutex
+	batches [][]int64
+}
+
+func (s *CountingBatchNodeService) BatchShowNode(ctx context.Context, refs []int64) ([]nodemanager.Node, error) {
+	s.mu.Lock()
+	s.batches = append(s.batches, refs)
+	s.mu.Unlock()
+	return s.MockNodeService.BatchShowNode(ctx, refs)
+}
+
+func TestConcurrentShowNodeQueriesBatched(t *testing.T) {
+	batchService := &CountingBatchNodeService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, batchService)
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, &TestLogger{}),
+		nodemanager.WithShowNodeBatching(20*time.Millisecond))
+
+	refs := []int64{1, 2, 3}
+	nodes := make([]nodemanager.Node, len(refs))
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: ref})
+		if err != nil {
+			t.Fatalf("Failed to create query: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			node, err := query.Execute(context.Background())
+			if err != nil {
+				t.Errorf("Query execution failed: %v", err)
+			}
+			nodes[i] = node
+		}()
+	}
+	wg.Wait()
+
+	if len(batchService.batches) != 1 || len(batchService.batches[0]) != 3 {
+		t.Fatalf("Expected one batched call for 3 refs, got %v", batchService.batches)
+	}
+	for i, ref := range refs {
+		if nodes[i].ID != ref {
+			t.Errorf("Expected query %d to receive node %d, got %+v", i, ref, nodes[i])
+		}
+	}
+}
+
+func TestTypedMiddlewareInspectsShowNodeResult(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, nodemanager.NewMockListService())
+	commandment.RegisterService[nodemanager.NodeService](registry, nodemanager.NewMockNodeService())
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	var seenRefs []int64
+	commandment.UseTyped(operationBus, func(
+		ctx context.Context,
+		query *nodemanager.ShowNodeQuery,
+		next func(context.Context) (nodemanager.Node, error),
+	) (nodemanager.Node, error) {
+		seenRefs = append(seenRefs, query.Params.Ref)
+		node, err := next(ctx)
+		if err != nil {
+			return node, err
+		}
+		node.Title = strings.ToUpper(node.Title)
+		return node, nil
+	})
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 5})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	node, err := query.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+	if node.Title != "NODE 5" {
+		t.Errorf("Expected typed middleware to transform node title, got %q", node.Title)
+	}
+
+	cmd, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{Title: "list"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := cmd.Execute(context.Background()); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if len(seenRefs) != 1 || seenRefs[0] != 5 {
+		t.Errorf("Expected typed middleware to see only the ShowNodeQuery, got %v", seenRefs)
+	}
+}
+
+func TestCreateListCommandRequiresListWrite(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, nodemanager.NewMockListService())
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithMiddleware(commandment.RequirePermissions()))
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	create := func(ctx context.Context) error {
+		cmd, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{Title: "Groceries"})
+		if err != nil {
+			t.Fatalf("Failed to create command: %v", err)
+		}
+		_, err = cmd.Execute(ctx)
+		return err
+	}
+
+	err := create(commandment.WithPermissions(context.Background(), "list:read"))
+	if !errors.Is(err, commandment.ErrForbidden) {
+		t.Fatalf("Expected ErrForbidden without list:write, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "list:write") {
+		t.Errorf("Expected error to name the missing permission, got %q", err)
+	}
+
+	if err := create(commandment.WithPermissions(context.Background(), "list:read", "list:write")); err != nil {
+		t.Errorf("Expected command to run with list:write, got %v", err)
+	}
+}
+
+// Node service whose nodes only become visible after a number of reads
+type LaggingNodeService struct {
+	nodemanager.MockNodeService
+	mu       sync.Mutex
+	lagReads int
+	reads    int
+}
+
+func (s *LaggingNodeService) ShowNode(ctx context.Context, params nodemanager.ShowNodeQueryParams) (nodemanager.Node, error) {
+	s.mu.Lock()
+	s.reads++
+	visible := s.reads > s.lagReads
+	s.mu.Unlock()
+	if !visible {
+		return nodemanager.Node{}, fmt.Errorf("node %d: %w", params.Ref, commandment.ErrNotFound)
+	}
+	return s.MockNodeService.ShowNode(ctx, params)
+}
+
+func TestEventualConsistencyRetriesUntilNodeAppears(t *testing.T) {
+	service := &LaggingNodeService{MockNodeService: *nodemanager.NewMockNodeService(), lagReads: 1}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, service)
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute),
+		commandment.WithNegativeCacheTTL(time.Minute),
+	)
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 9})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	node, err := commandment.ExecuteWithEventualConsistency(context.Background(), query, time.Second)
+	if err != nil {
+		t.Fatalf("Expected node to appear within the wait, got %v", err)
+	}
+	if node.Title != "Node 9" {
+		t.Errorf("Expected node 9, got %+v", node)
+	}
+	if service.reads != 2 {
+		t.Errorf("Expected the node on the second attempt, got %d reads", service.reads)
+	}
+}
+
+func TestEventualConsistencyGivesUpAfterMaxWait(t *testing.T) {
+	service := &LaggingNodeService{MockNodeService: *nodemanager.NewMockNodeService(), lagReads: 1000}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, service)
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, &TestLogger{}))
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 9})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	_, err = commandment.ExecuteWithEventualConsistency(context.Background(), query, 50*time.Millisecond)
+	if !errors.Is(err, commandment.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after max wait, got %v", err)
+	}
+}
+
+func TestQueryCacheHitsNodeServiceOnce(t *testing.T) {
+	service := &LaggingNodeService{MockNodeService: *nodemanager.NewMockNodeService()}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, service)
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute)))
+
+	for range 2 {
+		query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 3})
+		if err != nil {
+			t.Fatalf("Failed to create query: %v", err)
+		}
+		if node, err := query.Execute(context.Background()); err != nil || node.Title != "Node 3" {
+			t.Fatalf("Expected node 3, got %+v, %v", node, err)
+		}
+	}
+	if service.reads != 1 {
+		t.Errorf("Expected identical queries to reach the service once, got %d reads", service.reads)
+	}
+}
+
+func TestPostHookReceivesShowNodeResult(t *testing.T) {
+	readModel := make(map[int64]nodemanager.Node)
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, nodemanager.NewMockNodeService())
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithPostHook(func(ctx context.Context, descriptor commandment.OperationDescriptor, node nodemanager.Node) {
+			if descriptor.Type == commandment.QualifiedTypeName(&nodemanager.ShowNodeQuery{}) {
+				readModel[node.ID] = node
+			}
+		}),
+	)
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 4})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	node, err := query.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+
+	if cached, ok := readModel[node.ID]; !ok || cached != node {
+		t.Errorf("Expected post-hook to receive node %+v, read model has %+v", node, readModel)
+	}
+}
+
+func TestResultSizeMetricObservedForNode(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, nodemanager.NewMockNodeService())
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithResultSizeMetrics(nil))
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 3})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	node, err := query.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+	encoded, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Failed to encode node: %v", err)
+	}
+
+	histogram := operationBus.ResultSizeHistogram("ShowNodeQuery")
+	if histogram.Count != 1 {
+		t.Fatalf("Expected one size observation, got %d", histogram.Count)
+	}
+	if histogram.Sum != float64(len(encoded)) {
+		t.Errorf("Expected observed size %d bytes, got %v", len(encoded), histogram.Sum)
+	}
+	if histogram.Counts[0] != 1 {
+		t.Errorf("Expected a small node to land in the smallest bucket, got %v", histogram.Counts)
+	}
+}
+
+func TestDecodeShowNodeResultByTypeName(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, nodemanager.NewMockNodeService())
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+	nodeManagerBus.RegisterResultTypes()
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 8})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	node, err := query.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+	data, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Failed to encode node: %v", err)
+	}
+
+	decoded, err := operationBus.DecodeResult(query.Descriptor().Type, data)
+	if err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if got, ok := decoded.(nodemanager.Node); !ok || got != node {
+		t.Errorf("Expected decoded %+v, got %#v", node, decoded)
+	}
+
+	if _, err := operationBus.DecodeResult("RetiredQuery", data); !errors.Is(err, commandment.ErrUnknownResultType) {
+		t.Errorf("Expected ErrUnknownResultType, got %v", err)
+	}
+}
+
+func TestCatalogDescribesExampleOperations(t *testing.T) {
+	operationBus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+	nodeManagerBus.RegisterOperationTypes()
+
+	catalog := operationBus.Catalog()
+	if len(catalog) != 3 {
+		t.Fatalf("Expected 3 catalog entries, got %d", len(catalog))
+	}
+
+	expected := map[string]struct {
+		kind    string
+		service string
+		result  string
+	}{
+		commandment.QualifiedTypeName(&nodemanager.CreateListCommand{}): {
+			commandment.KindCommand, "nodemanager.ListService",
+			reflect.TypeFor[nodemanager.NodeCommandResult]().String(),
+		},
+		commandment.QualifiedTypeName(&nodemanager.DisplayNodeTreeCommand{}): {
+			commandment.KindCommand, "nodemanager.TreeService", "nodemanager.NodeTree",
+		},
+		commandment.QualifiedTypeName(&nodemanager.ShowNodeQuery{}): {
+			commandment.KindQuery, "nodemanager.NodeService", "nodemanager.Node",
+		},
+	}
+	for _, info := range catalog {
+		want, ok := expected[info.Type]
+		if !ok {
+			t.Errorf("Unexpected catalog entry %q", info.Type)
+			continue
+		}
+		if info.Kind != want.kind {
+			t.Errorf("Expected %s to be a %s, got %s", info.Type, want.kind, info.Kind)
+		}
+		if len(info.Services) != 1 || info.Services[0] != want.service {
+			t.Errorf("Expected %s to require %s, got %v", info.Type, want.service, info.Services)
+		}
+		if info.ResultType != want.result {
+			t.Errorf("Expected %s to return %s, got %s", info.Type, want.result, info.ResultType)
+		}
+	}
+
+	display := catalog[1]
+	properties, _ := display.ParamsSchema["properties"].(map[string]any)
+	maxDepth, _ := properties["MaxDepth"].(map[string]any)
+	if maxDepth["type"] != "integer" || maxDepth["default"] != "3" {
+		t.Errorf("Expected MaxDepth schema with default 3, got %v", maxDepth)
+	}
+}
+
+func TestCreateListCommandUndoDeletesList(t *testing.T) {
+	lists := nodemanager.NewMockListService()
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, lists)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(bus)
+
+	cmd, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{Title: "Groceries"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := commandment.ExecuteWithUndo(context.Background(), bus, cmd); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if lists.Lists() != 1 {
+		t.Fatalf("Expected one list after execution, got %d", lists.Lists())
+	}
+
+	if err := bus.UndoLast(context.Background()); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if lists.Lists() != 0 {
+		t.Errorf("Expected undo to delete the list, got %d", lists.Lists())
+	}
+	if err := bus.UndoLast(context.Background()); !errors.Is(err, commandment.ErrUndoStackEmpty) {
+		t.Errorf("Expected ErrUndoStackEmpty, got %v", err)
+	}
+}
+
+func TestCreateListCommandDryRunDoesNotPersist(t *testing.T) {
+	service := nodemanager.NewMockListService()
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, service)
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{})
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+	ctx := commandment.WithDryRun(context.Background())
+
+	cmd, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{Title: "Groceries"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	result, err := commandment.ExecuteWithUndo(ctx, operationBus, cmd)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if !result.Valid() || result.Value.Title != "Groceries" {
+		t.Errorf("Expected previewed list, got %+v", result)
+	}
+	if service.Lists() != 0 {
+		t.Errorf("Expected dry run not to create a list, got %d", service.Lists())
+	}
+	if err := operationBus.UndoLast(context.Background()); !errors.Is(err, commandment.ErrUndoStackEmpty) {
+		t.Errorf("Expected dry run not to be recorded for undo, got %v", err)
+	}
+
+	invalid, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if result, err := invalid.Execute(ctx); err != nil || result.Valid() {
+		t.Errorf("Expected dry run to report validation errors, got %+v, %v", result, err)
+	}
+}
+
+// Interceptor recording the service calls it observes
+type RecordingInterceptor struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (i *RecordingInterceptor) Before(ctx context.Context, serviceType reflect.Type, method string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.calls = append(i.calls, fmt.Sprintf("before %s.%s", serviceType.Name(), method))
+}
+
+func (i *RecordingInterceptor) After(ctx context.Context, serviceType reflect.Type, method string, err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.calls = append(i.calls, fmt.Sprintf("after %s.%s err=%v", serviceType.Name(), method, err != nil))
+}
+
+func TestServiceInterceptorObservesShowNodeCall(t *testing.T) {
+	interceptor := &RecordingInterceptor{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, nodemanager.NewMockNodeService())
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithServiceInterceptor(interceptor)))
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 4})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	if _, err := query.Execute(context.Background()); err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+
+	expected := []string{"before NodeService.ShowNode", "after NodeService.ShowNode err=false"}
+	if !reflect.DeepEqual(interceptor.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, interceptor.calls)
+	}
+}
+
+func TestPipelineShowsCreatedList(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, nodemanager.NewMockListService())
+	commandment.RegisterService[nodemanager.NodeService](registry, nodemanager.NewMockNodeService())
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, &TestLogger{}))
+
+	pipeline := commandment.NewPipeline().
+		Then(func(any) commandment.Operation[any] {
+			cmd, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{Title: "Groceries"})
+			if err != nil {
+				return nil
+			}
+			return commandment.AsAnyOperation(cmd)
+		}).
+		Then(func(prev any) commandment.Operation[any] {
+			created := prev.(nodemanager.NodeCommandResult)
+			query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: created.Value.ID})
+			if err != nil {
+				return nil
+			}
+			return commandment.AsAnyOperation(query)
+		})
+
+	result, steps, err := pipeline.RunWithSteps(context.Background())
+	if err != nil {
+		t.Fatalf("Pipeline failed: %v", err)
+	}
+	if node, ok := result.(nodemanager.Node); !ok || node.ID != 42 {
+		t.Errorf("Expected the created node 42, got %#v", result)
+	}
+	if len(steps) != 2 || steps[0].Type != commandment.QualifiedTypeName(&nodemanager.CreateListCommand{}) ||
+		steps[1].Type != commandment.QualifiedTypeName(&nodemanager.ShowNodeQuery{}) {
+		t.Fatalf("Expected CreateListCommand then ShowNodeQuery steps, got %+v", steps)
+	}
+	for _, step := range steps {
+		if step.Metadata.UUID == "" || step.Metadata.Executed.IsZero() || step.Metadata.Returned.IsZero() {
+			t.Errorf("Expected %s step to carry its execution metadata, got %+v", step.Type, step.Metadata)
+		}
+	}
+}
+
+func TestShowNodeQueryValidatesBeforeCallingService(t *testing.T) {
+	service := &LaggingNodeService{MockNodeService: *nodemanager.NewMockNodeService()}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.NodeService](registry, service)
+	nodeManagerBus := nodemanager.NewNodeManagerBus(commandment.NewOperationBus(registry, &TestLogger{}))
+
+	query, err := nodeManagerBus.NewShowNodeQuery(nodemanager.ShowNodeQueryParams{Ref: 0})
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	if _, err := query.Execute(context.Background()); !errors.Is(err, commandment.ErrInvalidOperation) {
+		t.Fatalf("Expected ErrInvalidOperation, got %v", err)
+	}
+	if service.reads != 0 {
+		t.Errorf("Expected invalid query not to reach the service, got %d reads", service.reads)
+	}
+}
+
+// ListService counting the lists it creates
+type CountingListService struct {
+	nodemanager.MockListService
+	creates int
+}
+
+func (s *CountingListService) CreateList(ctx context.Context, params nodemanager.CreateListCommandParams) (nodemanager.NodeCommandResult, error) {
+	s.creates++
+	return s.MockListService.CreateList(ctx, params)
+}
+
+func TestCreateListCommandIdempotencyKeyInMetadata(t *testing.T) {
+	service := &CountingListService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[nodemanager.ListService](registry, service)
+	operationBus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithIdempotencyStore(commandment.NewMemoryIdempotencyStore()))
+	nodeManagerBus := nodemanager.NewNodeManagerBus(operationBus)
+
+	var results []nodemanager.NodeCommandResult
+	for range 2 {
+		cmd, err := nodeManagerBus.NewCreateListCommand(nodemanager.CreateListCommandParams{Title: "Groceries"})
+		if err != nil {
+			t.Fatalf("Failed to create command: %v", err)
+		}
+		cmd.Meta.IdempotencyKey = "client-request-1"
+		result, err := cmd.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("Command execution failed: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	if service.creates != 1 {
+		t.Errorf("Expected the service to be invoked once, got %d", service.creates)
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("Expected the retry to return the recorded result, got %+v and %+v", results[0], results[1])
+	}
+}
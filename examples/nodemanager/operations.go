@@ -2,6 +2,8 @@ package nodemanager
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/davidlee/commandment/pkg/commandment"
 )
@@ -26,7 +28,7 @@ func (q *ShowNodeQuery) Metadata() commandment.OperationMetadata {
 
 func (q *ShowNodeQuery) Descriptor() commandment.OperationDescriptor {
 	return commandment.OperationDescriptor{
-		Type:     "ShowNodeQuery",
+		Type:     commandment.QualifiedTypeName(q),
 		Params:   q.Params,
 		Metadata: q.Meta,
 	}
@@ -35,6 +37,17 @@ func (q *ShowNodeQuery) Descriptor() commandment.OperationDescriptor {
 func (q *ShowNodeQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
 func (q *ShowNodeQuery) GetLogger() commandment.Logger               { return q.Logger }
 
+// ReadOnly marks ShowNodeQuery as a query.
+func (q *ShowNodeQuery) ReadOnly() {}
+
+// Validate rejects node references that can't exist before the service is called.
+func (q *ShowNodeQuery) Validate() error {
+	if q.Params.Ref <= 0 {
+		return fmt.Errorf("node reference must be positive, got %d", q.Params.Ref)
+	}
+	return nil
+}
+
 // DisplayNodeTreeCommand implements a command for displaying node trees (updates node refs).
 type DisplayNodeTreeCommand struct {
 	Params  DisplayNodeTreeCommandParams
@@ -55,7 +68,7 @@ func (c *DisplayNodeTreeCommand) Metadata() commandment.OperationMetadata {
 
 func (c *DisplayNodeTreeCommand) Descriptor() commandment.OperationDescriptor {
 	return commandment.OperationDescriptor{
-		Type:     "DisplayNodeTreeCommand",
+		Type:     commandment.QualifiedTypeName(c),
 		Params:   c.Params,
 		Metadata: c.Meta,
 	}
@@ -70,12 +83,49 @@ type CreateListCommand struct {
 	Service ListService
 	Meta    commandment.OperationMetadata
 	Logger  commandment.Logger
+	mu      sync.Mutex // guards created, as the command may execute concurrently
+	created []int64    // IDs of lists created by Execute, for Undo
 }
 
 func (c *CreateListCommand) Execute(ctx context.Context) (NodeCommandResult, error) {
-	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (NodeCommandResult, error) {
+	result, err := commandment.ExecuteValidated(ctx, c, func(ctx context.Context) (NodeCommandResult, error) {
 		return c.Service.CreateList(ctx, c.Params)
 	})
+	if err == nil && result.Valid() && !commandment.IsDryRun(ctx) {
+		c.mu.Lock()
+		c.created = append(c.created, result.Value.ID)
+		c.mu.Unlock()
+	}
+	return result, err
+}
+
+// DryRun validates the params and returns the list that Execute would create,
+// without an ID since nothing is persisted.
+func (c *CreateListCommand) DryRun(ctx context.Context) (NodeCommandResult, error) {
+	if c.Params.Title == "" {
+		return NodeCommandResult{
+			Errors: []ValidationError{{Field: "Title", Message: "Title is required"}},
+		}, nil
+	}
+	return NodeCommandResult{
+		Value: Node{Title: c.Params.Title, Description: c.Params.Description},
+	}, nil
+}
+
+// Undo deletes the list most recently created by Execute. It does nothing if
+// Execute created no list, for example because validation failed.
+func (c *CreateListCommand) Undo(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.created) == 0 {
+		return nil
+	}
+	id := c.created[len(c.created)-1]
+	if err := c.Service.DeleteList(ctx, id); err != nil {
+		return err
+	}
+	c.created = c.created[:len(c.created)-1]
+	return nil
 }
 
 func (c *CreateListCommand) Metadata() commandment.OperationMetadata {
@@ -84,7 +134,7 @@ func (c *CreateListCommand) Metadata() commandment.OperationMetadata {
 
 func (c *CreateListCommand) Descriptor() commandment.OperationDescriptor {
 	return commandment.OperationDescriptor{
-		Type:     "CreateListCommand",
+		Type:     commandment.QualifiedTypeName(c),
 		Params:   c.Params,
 		Metadata: c.Meta,
 	}
@@ -92,3 +142,6 @@ func (c *CreateListCommand) Descriptor() commandment.OperationDescriptor {
 
 func (c *CreateListCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
 func (c *CreateListCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+// RequiredPermissions restricts list creation to callers allowed to write lists.
+func (c *CreateListCommand) RequiredPermissions() []string { return []string{"list:write"} }
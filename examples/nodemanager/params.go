@@ -1,9 +1,11 @@
 package nodemanager
 
+import "github.com/davidlee/commandment/pkg/commandment"
+
 // DisplayNodeTreeCommandParams contains parameters for displaying node trees.
 type DisplayNodeTreeCommandParams struct {
-	RootReference string
-	MaxDepth      int
+	RootReference string `commandment:"logfield"`
+	MaxDepth      int    `commandment:"default=3"`
 }
 
 // NodeTree represents a tree structure of nodes with statistics.
@@ -19,11 +21,9 @@ type CreateListCommandParams struct {
 	ParentID    *int64
 }
 
-// NodeCommandResult represents the result of node operations.
-type NodeCommandResult struct {
-	Node   Node
-	Errors []ValidationError
-}
+// NodeCommandResult represents the result of node operations, carrying the
+// node alongside any validation errors.
+type NodeCommandResult = commandment.Validated[Node]
 
 // ShowNodeQueryParams contains parameters for querying individual nodes.
 type ShowNodeQueryParams struct {
@@ -44,7 +44,4 @@ type TreeStats struct {
 }
 
 // ValidationError represents validation errors.
-type ValidationError struct {
-	Field   string
-	Message string
-}
+type ValidationError = commandment.ValidationError
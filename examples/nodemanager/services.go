@@ -10,9 +10,16 @@ type TreeService interface {
 // ListService provides operations for creating and managing lists.
 type ListService interface {
 	CreateList(ctx context.Context, params CreateListCommandParams) (NodeCommandResult, error)
+	DeleteList(ctx context.Context, id int64) error
 }
 
 // NodeService provides operations for retrieving individual nodes.
 type NodeService interface {
 	ShowNode(ctx context.Context, params ShowNodeQueryParams) (Node, error)
 }
+
+// BatchNodeService retrieves many nodes in one call. Results are returned in the
+// order of refs.
+type BatchNodeService interface {
+	BatchShowNode(ctx context.Context, refs []int64) ([]Node, error)
+}
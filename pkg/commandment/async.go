@@ -0,0 +1,23 @@
+package commandment
+
+import "context"
+
+// Result is the outcome of an operation executed with ExecuteAsync.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// ExecuteAsync executes op in a new goroutine with ctx, returning a channel that
+// delivers exactly one Result and is then closed. The operation sees ctx's
+// cancellation as it would when executed directly. The channel is buffered, so
+// the goroutine finishes even if the result is never received.
+func ExecuteAsync[TResult any](ctx context.Context, op Operation[TResult]) <-chan Result[TResult] {
+	results := make(chan Result[TResult], 1)
+	go func() {
+		defer close(results)
+		value, err := op.Execute(ctx)
+		results <- Result[TResult]{Value: value, Err: err}
+	}()
+	return results
+}
@@ -0,0 +1,50 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestExecuteAsyncDeliversResult(t *testing.T) {
+	bus := newSlowBus(10*time.Millisecond, 0)
+	op, err := commandment.CreateOperation[*TestOperation](bus, "later")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	results := commandment.ExecuteAsync[string](context.Background(), op)
+	result := <-results
+	if result.Err != nil || result.Value != "done: later" {
+		t.Errorf("Expected result %q, got %+v", "done: later", result)
+	}
+	if _, ok := <-results; ok {
+		t.Error("Expected channel to be closed after the result")
+	}
+}
+
+func TestExecuteAsyncHonorsCancellation(t *testing.T) {
+	bus := newSlowBus(time.Second, 0)
+	op, err := commandment.CreateOperation[*TestOperation](bus, "never")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	results := commandment.ExecuteAsync[string](ctx, op)
+	cancel()
+	select {
+	case result := <-results:
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", result.Err)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Expected cancellation to end the execution")
+	}
+	if op.Meta.Returned.IsZero() {
+		t.Error("Expected Returned to be recorded for a cancelled operation")
+	}
+}
@@ -0,0 +1,132 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrAuditRequired is returned in strict audit mode when a command is executed on a
+// bus without an audit sink.
+var ErrAuditRequired = errors.New("command requires an audit sink")
+
+// ErrRedactedRecord is returned by ReplayFailed for audit records whose params were
+// masked by redaction, which can't be replayed faithfully. The command is not
+// executed.
+var ErrRedactedRecord = errors.New("audit record params are redacted")
+
+// AuditRecord describes one executed command.
+type AuditRecord struct {
+	Descriptor OperationDescriptor `json:"descriptor"`
+	Identity   string              `json:"identity,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	Completed  time.Time           `json:"completed"`
+	// Redacted reports whether the descriptor params had values masked by the bus
+	// redaction policy or redact tags, so ReplayFailed refuses to replay the record.
+	Redacted bool `json:"redacted,omitempty"`
+	// DryRun reports whether the command was executed as a dry run, so
+	// ReplayFailed skips the record rather than executing it for real.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// AuditSink receives a record of every command executed by the bus.
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord) error
+}
+
+// WithAuditSink records every executed command, successful or not, to sink,
+// including those rejected before they start, such as by validation. Queries are
+// not recorded unless the bus is created WithAuditQueries.
+func WithAuditSink(sink AuditSink) Option {
+	return func(b *OperationBus) {
+		b.auditSink = sink
+	}
+}
+
+// WithAuditQueries records executed queries to the audit sink as well as commands.
+func WithAuditQueries() Option {
+	return func(b *OperationBus) {
+		b.auditQueries = true
+	}
+}
+
+// WithStrictAudit refuses to execute commands with ErrAuditRequired unless the bus
+// has an audit sink, preventing unaudited mutations. Queries are exempt.
+func WithStrictAudit() Option {
+	return func(b *OperationBus) {
+		b.strictAudit = true
+	}
+}
+
+// checkAudit reports ErrAuditRequired for a command on a strict bus without an
+// audit sink. It is a no-op on a nil bus.
+func (b *OperationBus) checkAudit(op any) error {
+	if b == nil || !b.strictAudit || b.auditSink != nil || isQuery(op) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrAuditRequired, operationTypeName(op))
+}
+
+// audit records an executed command, or a query on a bus auditing queries, to the
+// audit sink, logging sink failures. A conditional query failing with ErrNotModified
+// is recorded as a success.
+func (b *OperationBus) audit(ctx context.Context, op any, execErr error) {
+	if b == nil || b.auditSink == nil || (isQuery(op) && !b.auditQueries) {
+		return
+	}
+	record := AuditRecord{
+		Descriptor: OperationDescriptor{Type: operationTypeName(op)},
+		Completed:  time.Now(),
+	}
+	if d, ok := op.(Describer); ok {
+		record.Descriptor = d.Descriptor()
+		params := record.Descriptor.Params
+		record.Descriptor.Params = b.redactParams(params)
+		record.Redacted = !reflect.DeepEqual(params, record.Descriptor.Params)
+	}
+	record.Identity, _ = IdentityFromContext(ctx)
+	record.DryRun = IsDryRun(ctx)
+	if execErr != nil && !errors.Is(execErr, ErrNotModified) {
+		record.Error = execErr.Error()
+	}
+	if err := b.auditSink.Record(ctx, record); err != nil {
+		b.logger.Error("Audit record failed",
+			"operation_type", record.Descriptor.Type,
+			"operation_id", record.Descriptor.Metadata.UUID,
+			"error", err,
+		)
+	}
+}
+
+// ReplayResult is the outcome of re-executing one audited command.
+type ReplayResult struct {
+	Descriptor OperationDescriptor
+	Result     any
+	Err        error
+}
+
+// ReplayFailed reconstructs and re-executes, in order, the audited commands whose
+// record has an error, skipping those that succeeded or were dry runs. Commands are reconstructed
+// with CreateFromDescriptor, so their types need registered descriptor factories,
+// and keep their recorded metadata, as with Replay. Records whose params were
+// redacted are refused with ErrRedactedRecord rather than replayed with masked
+// values. It returns one result per replayed command, and the joined errors of
+// those that failed again or were refused.
+func (b *OperationBus) ReplayFailed(ctx context.Context, entries []AuditRecord) ([]ReplayResult, error) {
+	var failed []AuditRecord
+	var descriptors []OperationDescriptor
+	for _, entry := range entries {
+		if entry.Error != "" && !entry.DryRun {
+			failed = append(failed, entry)
+			descriptors = append(descriptors, entry.Descriptor)
+		}
+	}
+	return b.replay(ctx, descriptors, func(i int) error {
+		if failed[i].Redacted {
+			return ErrRedactedRecord
+		}
+		return nil
+	})
+}
@@ -0,0 +1,309 @@
+package commandment_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Audit sink keeping records in memory
+type RecordingAuditSink struct {
+	mu      sync.Mutex
+	records []commandment.AuditRecord
+}
+
+func (s *RecordingAuditSink) Record(ctx context.Context, record commandment.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func TestStrictAuditRejectsCommandWithoutSink(t *testing.T) {
+	bus, service := newRecordBus(commandment.WithStrictAudit())
+
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := cmd.Execute(context.Background()); !errors.Is(err, commandment.ErrAuditRequired) {
+		t.Errorf("Expected ErrAuditRequired, got %v", err)
+	}
+	if service.records["k"] != "v1" {
+		t.Errorf("Expected unaudited command not to mutate state, got %q", service.records["k"])
+	}
+}
+
+func TestStrictAuditExemptsQueries(t *testing.T) {
+	bus, _ := newRecordBus(commandment.WithStrictAudit())
+
+	if got := readRecord(context.Background(), t, bus, "k"); got != "v1" {
+		t.Errorf("Expected query to execute without an audit sink, got %q", got)
+	}
+}
+
+func TestStrictAuditRecordsCommandsWithSink(t *testing.T) {
+	sink := &RecordingAuditSink{}
+	bus, _ := newRecordBus(commandment.WithStrictAudit(), commandment.WithAuditSink(sink))
+	ctx := commandment.WithIdentity(context.Background(), "alice")
+
+	writeRecord(ctx, t, bus, "k", "v2")
+	readRecord(ctx, t, bus, "k")
+
+	if len(sink.records) != 1 {
+		t.Fatalf("Expected only the command to be audited, got %d records", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.Descriptor.Type != "WriteRecordCommand" || record.Identity != "alice" || record.Error != "" {
+		t.Errorf("Unexpected audit record: %+v", record)
+	}
+}
+
+func TestAuditRecordsRejectedCommands(t *testing.T) {
+	sink := &RecordingAuditSink{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &CountingService{})
+	bus := commandment.NewOperationBus(registry, &RecordingLogger{}, commandment.WithAuditSink(sink))
+
+	op, err := commandment.CreateOperation[*ValidatingOperation](bus, "")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); !errors.Is(err, commandment.ErrInvalidOperation) {
+		t.Fatalf("Expected ErrInvalidOperation, got %v", err)
+	}
+
+	if len(sink.records) != 1 {
+		t.Fatalf("Expected the rejected command to be audited, got %d records", len(sink.records))
+	}
+	if record := sink.records[0]; !strings.Contains(record.Error, "params must not be empty") {
+		t.Errorf("Expected audit record to carry the rejection, got %+v", record)
+	}
+}
+
+func TestReplayFailedSkipsDryRuns(t *testing.T) {
+	sink := &RecordingAuditSink{}
+	bus, service := newRecordBus(commandment.WithAuditSink(sink))
+
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := cmd.Execute(commandment.WithDryRun(context.Background())); err == nil {
+		t.Fatal("Expected the dry run of a command without DryRun to fail")
+	}
+	if len(sink.records) != 1 || !sink.records[0].DryRun {
+		t.Fatalf("Expected one audit record marked as a dry run, got %+v", sink.records)
+	}
+
+	results, err := bus.ReplayFailed(context.Background(), sink.records)
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected the failed dry run not to be replayed, got %v, %v", results, err)
+	}
+	if service.records["k"] != "v1" {
+		t.Errorf("Expected the record untouched, got %q", service.records["k"])
+	}
+}
+
+// Service failing the first attempt for selected inputs
+type FlakyService struct {
+	mu       sync.Mutex
+	failOnce map[string]bool
+	calls    []string
+}
+
+func (s *FlakyService) DoSomething(ctx context.Context, input string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, input)
+	if s.failOnce[input] {
+		delete(s.failOnce, input)
+		return "", errors.New("transient failure")
+	}
+	return "done: " + input, nil
+}
+
+func TestReplayFailedRerunsOnlyFailures(t *testing.T) {
+	service := &FlakyService{failOnce: map[string]bool{"b": true, "d": true}}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	sink := &RecordingAuditSink{}
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithAuditSink(sink))
+	bus.RegisterDescriptorFactory("TestOperation", func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var input string
+		if err := json.Unmarshal(params, &input); err != nil {
+			return nil, err
+		}
+		return commandment.CreateOperation[*TestOperation](bus, input)
+	})
+
+	for _, input := range []string{"a", "b", "c", "d"} {
+		op, err := commandment.CreateOperation[*TestOperation](bus, input)
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		_, _ = op.Execute(context.Background())
+	}
+
+	// Replay from the serialized audit log
+	data, err := json.Marshal(sink.records)
+	if err != nil {
+		t.Fatalf("Failed to serialize audit log: %v", err)
+	}
+	var entries []commandment.AuditRecord
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Failed to decode audit log: %v", err)
+	}
+	service.calls = nil
+
+	results, err := bus.ReplayFailed(context.Background(), entries)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if len(service.calls) != 2 || service.calls[0] != "b" || service.calls[1] != "d" {
+		t.Errorf("Expected only failed operations b and d to re-run, got %v", service.calls)
+	}
+	if len(results) != 2 || results[1].Result != "done: d" {
+		t.Errorf("Unexpected replay results: %+v", results)
+	}
+}
+
+func TestReplayFailedReportsUnknownTypes(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+	entries := []commandment.AuditRecord{
+		{Descriptor: commandment.OperationDescriptor{Type: "Unregistered"}, Error: "boom"},
+	}
+
+	results, err := bus.ReplayFailed(context.Background(), entries)
+	if err == nil || len(results) != 1 || results[0].Err == nil {
+		t.Errorf("Expected replay of an unregistered type to fail, got %+v, %v", results, err)
+	}
+}
+
+func TestReplayFailedRefusesRedactedRecords(t *testing.T) {
+	sink := &RecordingAuditSink{}
+	policy := commandment.RedactionPolicy{Fields: []string{"Value"}}
+	bus, service := newRecordBus(commandment.WithAuditSink(sink), commandment.WithRedactionPolicy(policy))
+	commandment.RegisterOperationType[*WriteRecordCommand](bus)
+
+	writeRecord(context.Background(), t, bus, "k", "secret")
+	if len(sink.records) != 1 || !sink.records[0].Redacted {
+		t.Fatalf("Expected the masked record to be marked redacted, got %+v", sink.records)
+	}
+	record := sink.records[0]
+	record.Error = "write failed"
+	service.records["k"] = "v1"
+
+	results, err := bus.ReplayFailed(context.Background(), []commandment.AuditRecord{record})
+	if !errors.Is(err, commandment.ErrRedactedRecord) || len(results) != 1 || !errors.Is(results[0].Err, commandment.ErrRedactedRecord) {
+		t.Errorf("Expected the redacted record to be refused, got %+v, %v", results, err)
+	}
+	if service.records["k"] != "v1" {
+		t.Errorf("Expected the masked value not to be written, got %q", service.records["k"])
+	}
+}
+
+func TestAuditRecordsWithoutMaskedParamsAreNotRedacted(t *testing.T) {
+	sink := &RecordingAuditSink{}
+	policy := commandment.RedactionPolicy{Fields: []string{"Password"}}
+	bus, _ := newRecordBus(commandment.WithAuditSink(sink), commandment.WithRedactionPolicy(policy))
+
+	writeRecord(context.Background(), t, bus, "k", "v2")
+	if len(sink.records) != 1 || sink.records[0].Redacted {
+		t.Errorf("Expected an unmasked record, got %+v", sink.records)
+	}
+}
+
+func TestAuditQueriesRecordsQueriesToo(t *testing.T) {
+	sink := &RecordingAuditSink{}
+	bus, _ := newRecordBus(commandment.WithAuditSink(sink), commandment.WithAuditQueries())
+
+	writeRecord(context.Background(), t, bus, "k", "v2")
+	readRecord(context.Background(), t, bus, "k")
+
+	if len(sink.records) != 2 || sink.records[1].Descriptor.Type != "ReadRecordQuery" {
+		t.Errorf("Expected the command and the query to be audited, got %+v", sink.records)
+	}
+}
+
+func TestAuditRecordsNotModifiedQueriesAsSuccess(t *testing.T) {
+	sink := &RecordingAuditSink{}
+	bus, _ := newRecordBus(commandment.WithAuditSink(sink), commandment.WithAuditQueries())
+	etag, err := commandment.ResultETag(readRecord(context.Background(), t, bus, "k"))
+	if err != nil {
+		t.Fatalf("Failed to compute etag: %v", err)
+	}
+
+	query, err := commandment.CreateOperation[*ReadRecordQuery](bus, "k")
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	if _, err := query.Execute(commandment.WithIfNoneMatch(context.Background(), etag)); !errors.Is(err, commandment.ErrNotModified) {
+		t.Fatalf("Expected ErrNotModified, got %v", err)
+	}
+
+	if len(sink.records) != 2 || sink.records[1].Error != "" {
+		t.Errorf("Expected the not-modified query audited without an error, got %+v", sink.records)
+	}
+}
+
+func TestFileAuditSinkWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := commandment.NewFileAuditSink(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit sink: %v", err)
+	}
+	policy := commandment.RedactionPolicy{Fields: []string{"Value"}}
+	bus, _ := newRecordBus(commandment.WithAuditSink(sink), commandment.WithRedactionPolicy(policy))
+	ctx := commandment.WithIdentity(context.Background(), "alice")
+
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "secret"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := cmd.Execute(ctx); err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	writeRecord(ctx, t, bus, "k", "v3")
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Failed to close audit sink: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("Expected one line per command, got %d", lines)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("Expected redacted params in the audit log, got %s", data)
+	}
+
+	records, err := commandment.ReadAuditRecords(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode audit log: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	record := records[0]
+	if record.Descriptor.Type != "WriteRecordCommand" || record.Identity != "alice" || record.Error != "" {
+		t.Errorf("Unexpected audit record: %+v", record)
+	}
+	if record.Descriptor.Metadata.UUID != cmd.Metadata().UUID {
+		t.Errorf("Expected operation ID %s, got %s", cmd.Metadata().UUID, record.Descriptor.Metadata.UUID)
+	}
+	if record.Descriptor.Metadata.Created.IsZero() || record.Completed.IsZero() {
+		t.Errorf("Expected timestamps in the audit record, got %+v", record)
+	}
+}
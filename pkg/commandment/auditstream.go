@@ -0,0 +1,96 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// AuditStreamer is an AuditSink that pushes each record, as it is produced, to
+// live subscribers such as compliance monitors, and then to an optional next sink
+// for persistence.
+type AuditStreamer struct {
+	next AuditSink
+
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]func(ctx context.Context, record AuditRecord) error
+}
+
+// NewAuditStreamer creates an AuditStreamer forwarding records to next, which may be nil.
+func NewAuditStreamer(next AuditSink) *AuditStreamer {
+	return &AuditStreamer{
+		next:        next,
+		subscribers: make(map[int]func(context.Context, AuditRecord) error),
+	}
+}
+
+// Record delivers record to every subscriber and then to the next sink, returning
+// the joined delivery failures.
+func (s *AuditStreamer) Record(ctx context.Context, record AuditRecord) error {
+	var errs []error
+	s.mu.RLock()
+	for _, deliver := range s.subscribers {
+		if err := deliver(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.mu.RUnlock()
+	if s.next != nil {
+		if err := s.next.Record(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Subscribe calls fn with every record from now on, synchronously as each command
+// completes. It returns a func that ends the subscription; fn must not call it.
+func (s *AuditStreamer) Subscribe(fn func(AuditRecord)) (unsubscribe func()) {
+	id := s.add(func(ctx context.Context, record AuditRecord) error {
+		fn(record)
+		return nil
+	})
+	return func() { s.remove(id) }
+}
+
+// Stream returns a channel receiving every record from now on, with room for buffer
+// undelivered records. When the buffer is full, the executing command waits for the
+// subscriber or for its context to be done. The returned func ends the subscription
+// and closes the channel.
+func (s *AuditStreamer) Stream(buffer int) (<-chan AuditRecord, func()) {
+	records := make(chan AuditRecord, buffer)
+	done := make(chan struct{})
+	id := s.add(func(ctx context.Context, record AuditRecord) error {
+		select {
+		case records <- record:
+			return nil
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	var once sync.Once
+	return records, func() {
+		once.Do(func() {
+			close(done)
+			s.remove(id)
+			close(records)
+		})
+	}
+}
+
+func (s *AuditStreamer) add(deliver func(context.Context, AuditRecord) error) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.subscribers[s.nextID] = deliver
+	return s.nextID
+}
+
+func (s *AuditStreamer) remove(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, id)
+}
@@ -0,0 +1,88 @@
+package commandment_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestAuditStreamerDeliversEachRecordLive(t *testing.T) {
+	persisted := &RecordingAuditSink{}
+	streamer := commandment.NewAuditStreamer(persisted)
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithAuditSink(streamer))
+
+	records, stop := streamer.Stream(0)
+	defer stop()
+
+	const writers = 8
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cmd, err := commandment.CreateOperation[*TestOperation](bus, fmt.Sprint(i))
+			if err != nil {
+				t.Errorf("Failed to create command: %v", err)
+				return
+			}
+			if _, err := cmd.Execute(context.Background()); err != nil {
+				t.Errorf("Command execution failed: %v", err)
+			}
+		}()
+	}
+
+	seen := make(map[string]bool)
+	for range writers {
+		select {
+		case record := <-records:
+			seen[record.Descriptor.Metadata.UUID] = true
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a record per command, got %d", len(seen))
+		}
+	}
+	wg.Wait()
+
+	if len(seen) != writers {
+		t.Errorf("Expected %d distinct records, got %d", writers, len(seen))
+	}
+	persisted.mu.Lock()
+	defer persisted.mu.Unlock()
+	if len(persisted.records) != writers {
+		t.Errorf("Expected records forwarded to the next sink, got %d", len(persisted.records))
+	}
+}
+
+func TestAuditStreamerUnsubscribeStopsDelivery(t *testing.T) {
+	streamer := commandment.NewAuditStreamer(nil)
+	bus, _ := newRecordBus(commandment.WithAuditSink(streamer))
+
+	var mu sync.Mutex
+	var received int
+	unsubscribe := streamer.Subscribe(func(record commandment.AuditRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		received++
+	})
+
+	writeRecord(context.Background(), t, bus, "k", "v2")
+	unsubscribe()
+	writeRecord(context.Background(), t, bus, "k", "v3")
+
+	records, stop := streamer.Stream(1)
+	stop()
+	if _, open := <-records; open {
+		t.Error("Expected stopped stream to be closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received != 1 {
+		t.Errorf("Expected only the record before unsubscribing, got %d", received)
+	}
+}
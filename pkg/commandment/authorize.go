@@ -0,0 +1,60 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Authorizer decides whether the caller may execute an operation. A non-nil error
+// refuses the execution before any middleware or business logic runs.
+type Authorizer interface {
+	Authorize(ctx context.Context, desc OperationDescriptor) error
+}
+
+// AuthorizerFunc adapts a function to an Authorizer.
+type AuthorizerFunc func(ctx context.Context, desc OperationDescriptor) error
+
+// Authorize implements Authorizer.
+func (f AuthorizerFunc) Authorize(ctx context.Context, desc OperationDescriptor) error {
+	return f(ctx, desc)
+}
+
+// WithAuthorizer makes the bus consult authorizer before executing each operation
+// it created. Refusals are returned wrapping ErrForbidden.
+func WithAuthorizer(authorizer Authorizer) Option {
+	return func(b *OperationBus) {
+		b.authorizer = authorizer
+	}
+}
+
+// OperationKindFromContext returns KindQuery or KindCommand for the operation
+// executing with ctx, so that policies can treat reads and writes differently.
+// Returns false outside of execution.
+func OperationKindFromContext(ctx context.Context) (string, bool) {
+	exec := executionFromContext(ctx)
+	if exec == nil {
+		return "", false
+	}
+	if isQuery(exec.op) {
+		return KindQuery, true
+	}
+	return KindCommand, true
+}
+
+// authorize consults the bus authorizer for op. It is a no-op on a nil bus or one
+// without an authorizer.
+func (b *OperationBus) authorize(ctx context.Context, op any) error {
+	if b == nil || b.authorizer == nil {
+		return nil
+	}
+	descriptor := OperationDescriptor{Type: operationTypeName(op)}
+	if d, ok := op.(Describer); ok {
+		descriptor = d.Descriptor()
+	}
+	err := b.authorizer.Authorize(ctx, descriptor)
+	if err == nil || errors.Is(err, ErrForbidden) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrForbidden, err)
+}
@@ -0,0 +1,36 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// readOnlyPrincipal allows queries and denies commands
+var readOnlyPrincipal = commandment.AuthorizerFunc(func(ctx context.Context, desc commandment.OperationDescriptor) error {
+	if kind, _ := commandment.OperationKindFromContext(ctx); kind != commandment.KindQuery {
+		return errors.New("read-only principal may not execute " + desc.Type)
+	}
+	return nil
+})
+
+func TestAuthorizerAllowsQueriesDeniesCommands(t *testing.T) {
+	bus, service := newRecordBus(commandment.WithAuthorizer(readOnlyPrincipal))
+
+	if got := readRecord(context.Background(), t, bus, "k"); got != "v1" {
+		t.Errorf("Expected query to be allowed, got %q", got)
+	}
+
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := cmd.Execute(context.Background()); !errors.Is(err, commandment.ErrForbidden) {
+		t.Fatalf("Expected ErrForbidden, got %v", err)
+	}
+	if service.records["k"] != "v1" {
+		t.Errorf("Expected denied command not to reach the service, got %q", service.records["k"])
+	}
+}
@@ -0,0 +1,97 @@
+package commandment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchFunc fetches values for keys in one service call. It returns one value per
+// key, in the same order.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, error)
+
+// Batcher collects individual loads arriving within a short window and serves them
+// with a single BatchFunc call, so N operations each calling the same service with
+// different keys cost one round-trip. It is typically used to adapt a service's
+// batch method to its single-key interface. A Batcher is safe for concurrent use.
+type Batcher[K comparable, V any] struct {
+	window time.Duration
+	fetch  BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+}
+
+// batch is a set of keys collected in one window and their eventual results.
+type batch[K comparable, V any] struct {
+	keys   []K
+	index  map[K]int
+	done   chan struct{}
+	values []V
+	err    error
+}
+
+// NewBatcher creates a Batcher that waits window after the first load of a batch
+// before calling fetch with every key collected meanwhile.
+func NewBatcher[K comparable, V any](window time.Duration, fetch BatchFunc[K, V]) *Batcher[K, V] {
+	return &Batcher[K, V]{window: window, fetch: fetch}
+}
+
+// Load returns the value for key, fetched together with any other keys loaded in
+// the same window. Duplicate keys within a batch are fetched once. The batch is
+// fetched with the context of its first load, detached from that load's
+// cancellation; ctx only bounds how long this call waits.
+func (b *Batcher[K, V]) Load(ctx context.Context, key K) (V, error) {
+	current, i := b.enqueue(ctx, key)
+
+	select {
+	case <-current.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+	if current.err != nil {
+		var zero V
+		return zero, current.err
+	}
+	return current.values[i], nil
+}
+
+// enqueue adds key to the pending batch, starting a new one if needed, and returns
+// the batch and the key's position in it.
+func (b *Batcher[K, V]) enqueue(ctx context.Context, key K) (*batch[K, V], int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending == nil {
+		b.pending = &batch[K, V]{index: make(map[K]int), done: make(chan struct{})}
+		go b.dispatch(context.WithoutCancel(ctx), b.pending)
+	}
+	current := b.pending
+	i, ok := current.index[key]
+	if !ok {
+		i = len(current.keys)
+		current.index[key] = i
+		current.keys = append(current.keys, key)
+	}
+	return current, i
+}
+
+// dispatch waits out the window, closes the batch to new keys and fetches it.
+func (b *Batcher[K, V]) dispatch(ctx context.Context, current *batch[K, V]) {
+	time.Sleep(b.window)
+
+	b.mu.Lock()
+	if b.pending == current {
+		b.pending = nil
+	}
+	b.mu.Unlock()
+
+	values, err := b.fetch(ctx, current.keys)
+	if err == nil && len(values) != len(current.keys) {
+		err = fmt.Errorf("batch fetch returned %d values for %d keys", len(values), len(current.keys))
+	}
+	current.values, current.err = values, err
+	close(current.done)
+}
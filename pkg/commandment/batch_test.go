@@ -0,0 +1,94 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// loadConcurrently loads keys from batcher in parallel, returning values and errors by position.
+func loadConcurrently(batcher *commandment.Batcher[string, string], keys []string) ([]string, []error) {
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = batcher.Load(context.Background(), key)
+		}()
+	}
+	wg.Wait()
+	return values, errs
+}
+
+func TestBatcherDeduplicatesKeys(t *testing.T) {
+	var mu sync.Mutex
+	var calls [][]string
+	batcher := commandment.NewBatcher(20*time.Millisecond, func(ctx context.Context, keys []string) ([]string, error) {
+		mu.Lock()
+		calls = append(calls, keys)
+		mu.Unlock()
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			values[i] = strings.ToUpper(key)
+		}
+		return values, nil
+	})
+
+	values, errs := loadConcurrently(batcher, []string{"a", "b", "a"})
+
+	for i, want := range []string{"A", "B", "A"} {
+		if errs[i] != nil || values[i] != want {
+			t.Errorf("Expected load %d to return %q, got %q, %v", i, want, values[i], errs[i])
+		}
+	}
+	if len(calls) != 1 || len(calls[0]) != 2 {
+		t.Errorf("Expected one fetch of 2 distinct keys, got %v", calls)
+	}
+}
+
+func TestBatcherFansOutErrors(t *testing.T) {
+	errUnavailable := errors.New("service unavailable")
+	batcher := commandment.NewBatcher(10*time.Millisecond, func(ctx context.Context, keys []string) ([]string, error) {
+		return nil, errUnavailable
+	})
+
+	_, errs := loadConcurrently(batcher, []string{"a", "b"})
+	for i, err := range errs {
+		if !errors.Is(err, errUnavailable) {
+			t.Errorf("Expected load %d to fail with the batch error, got %v", i, err)
+		}
+	}
+}
+
+func TestBatcherRejectsMismatchedResults(t *testing.T) {
+	batcher := commandment.NewBatcher(10*time.Millisecond, func(ctx context.Context, keys []string) ([]string, error) {
+		return []string{"only one"}, nil
+	})
+
+	_, errs := loadConcurrently(batcher, []string{"a", "b"})
+	if errs[0] == nil || errs[1] == nil {
+		t.Errorf("Expected loads to fail when fetch returns too few values, got %v", errs)
+	}
+}
+
+func TestBatcherLoadHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	batcher := commandment.NewBatcher(time.Millisecond, func(ctx context.Context, keys []string) ([]string, error) {
+		<-release
+		return keys, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := batcher.Load(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
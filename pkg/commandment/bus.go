@@ -1,36 +1,169 @@
 package commandment
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"slices"
+	"sync"
 	"time"
 )
 
 // OperationBus is the central orchestrator that manages service registry,
 // creates operations with dependency injection, and handles operation lifecycle.
 type OperationBus struct {
-	registry    *ServiceRegistry
-	logger      Logger
-	defaultDeps any // Optional default Dependencies for all operations
+	registry         *ServiceRegistry
+	queryRegistry    *ServiceRegistry
+	logger           Logger
+	defaultDeps      any // Optional default Dependencies for all operations
+	idempotencyStore IdempotencyStore
+	identityLimiter  *identityLimiter
+	serializer       *serializer
+	serviceTimeout   time.Duration
+	defaultTimeout   time.Duration
+	idGenerator      func() string
+	queryCache       QueryCache
+	queryCacheTTL    time.Duration
+	negativeCacheTTL time.Duration
+	queryRefreshes   *sync.Map
+	queryCacheTypes  *sync.Map
+	auditSink        AuditSink
+	auditQueries     bool
+	strictAudit      bool
+	strictDeps       bool
+	depFields        bool
+	pooling          bool
+	authorizer       Authorizer
+	redaction        *RedactionPolicy
+	tracer           Tracer
+	sampler          Sampler
+	enrichers        []ContextEnricher
+	observers        []ResultObserver
+	postHooks        []func(context.Context, OperationDescriptor, any)
+	snapshotKeys     []snapshotKey
+	middlewareMu     sync.RWMutex
+	middleware       []Middleware
+	eventSinksMu     sync.RWMutex
+	eventSinks       []EventSink
+	interceptorsMu   sync.RWMutex
+	interceptors     []ServiceInterceptor
+	descriptors      *descriptorRegistry
+	converters       *converterRegistry
+	resultTypes      *typeRegistry
+	paramTypes       *typeRegistry
+	typeTimeouts     *typeTimeouts
+	catalog          *catalog
+	executions       *executionTracker
+	undo             *undoStack
+	metrics          *metricsRegistry
+	clock            Clock // nil for the wall clock
+	breakers         *circuitBreakers
+	sizeCodec        Codec[any]
 }
 
+// Option configures optional OperationBus behavior at construction time.
+type Option func(*OperationBus)
+
 // NewOperationBus creates a new OperationBus with the provided service registry and logger.
-func NewOperationBus(registry *ServiceRegistry, logger Logger) *OperationBus {
-	return &OperationBus{
-		registry:    registry,
-		logger:      logger,
-		defaultDeps: nil,
-	}
+func NewOperationBus(registry *ServiceRegistry, logger Logger, opts ...Option) *OperationBus {
+	return newOperationBus(registry, logger, nil, opts)
 }
 
 // NewOperationBusWithDefaultDependencies creates a new OperationBus with default Dependencies
 // that will be available to all operations created by this bus.
-func NewOperationBusWithDefaultDependencies(registry *ServiceRegistry, logger Logger, defaultDeps any) *OperationBus {
-	return &OperationBus{
-		registry:    registry,
-		logger:      logger,
-		defaultDeps: defaultDeps,
+func NewOperationBusWithDefaultDependencies(registry *ServiceRegistry, logger Logger, defaultDeps any, opts ...Option) *OperationBus {
+	return newOperationBus(registry, logger, defaultDeps, opts)
+}
+
+func newOperationBus(registry *ServiceRegistry, logger Logger, defaultDeps any, opts []Option) *OperationBus {
+	bus := &OperationBus{
+		registry:     registry,
+		logger:       logger,
+		defaultDeps:  defaultDeps,
+		descriptors:  newDescriptorRegistry(),
+		converters:   newConverterRegistry(),
+		resultTypes:  newTypeRegistry(),
+		paramTypes:   newTypeRegistry(),
+		typeTimeouts: newTypeTimeouts(),
+		catalog:      newCatalog(),
+		executions:   &executionTracker{},
+		undo:         newUndoStack(DefaultUndoCapacity),
+		metrics:      newMetricsRegistry(),
+	}
+	for _, opt := range opts {
+		opt(bus)
+	}
+	return bus
+}
+
+// With returns a child bus sharing the parent's service registry, logger, execution
+// diagnostics and metrics, with opts applied on top of the parent's configuration.
+// The child starts with the parent's middleware, event sinks, service interceptors,
+// descriptor factories, result converters, result types, type timeouts and catalog;
+// adding to them on the child doesn't affect the parent. It has an undo stack of
+// its own, of the parent's capacity, so UndoLast on one bus can't undo a command
+// recorded by another.
+func (b *OperationBus) With(opts ...Option) *OperationBus {
+	b.middlewareMu.RLock()
+	middleware := append([]Middleware(nil), b.middleware...)
+	b.middlewareMu.RUnlock()
+	b.eventSinksMu.RLock()
+	eventSinks := slices.Clip(b.eventSinks)
+	b.eventSinksMu.RUnlock()
+	b.interceptorsMu.RLock()
+	interceptors := slices.Clip(b.interceptors)
+	b.interceptorsMu.RUnlock()
+
+	child := &OperationBus{
+		registry:         b.registry,
+		queryRegistry:    b.queryRegistry,
+		logger:           b.logger,
+		defaultDeps:      b.defaultDeps,
+		idempotencyStore: b.idempotencyStore,
+		identityLimiter:  b.identityLimiter,
+		serializer:       b.serializer,
+		serviceTimeout:   b.serviceTimeout,
+		defaultTimeout:   b.defaultTimeout,
+		idGenerator:      b.idGenerator,
+		queryCache:       b.queryCache,
+		queryCacheTTL:    b.queryCacheTTL,
+		negativeCacheTTL: b.negativeCacheTTL,
+		queryRefreshes:   b.queryRefreshes,
+		queryCacheTypes:  b.queryCacheTypes,
+		auditSink:        b.auditSink,
+		auditQueries:     b.auditQueries,
+		strictAudit:      b.strictAudit,
+		strictDeps:       b.strictDeps,
+		depFields:        b.depFields,
+		pooling:          b.pooling,
+		authorizer:       b.authorizer,
+		redaction:        b.redaction,
+		tracer:           b.tracer,
+		sampler:          b.sampler,
+		enrichers:        slices.Clip(b.enrichers),
+		observers:        slices.Clip(b.observers),
+		postHooks:        slices.Clip(b.postHooks),
+		snapshotKeys:     slices.Clip(b.snapshotKeys),
+		middleware:       middleware,
+		eventSinks:       eventSinks,
+		interceptors:     interceptors,
+		descriptors:      b.descriptors.clone(),
+		converters:       b.converters.clone(),
+		resultTypes:      b.resultTypes.clone(),
+		paramTypes:       b.paramTypes.clone(),
+		typeTimeouts:     b.typeTimeouts.clone(),
+		catalog:          b.catalog.clone(),
+		executions:       b.executions,
+		undo:             newUndoStack(b.undo.capacity),
+		metrics:          b.metrics,
+		breakers:         b.breakers,
+		sizeCodec:        b.sizeCodec,
+		clock:            b.clock,
 	}
+	for _, opt := range opts {
+		opt(child)
+	}
+	return child
 }
 
 // CreateOperation creates a new operation instance with injected dependencies.
@@ -53,120 +186,207 @@ func CreateOperationWithDependencies[TOp Operation[TResult], TResult any](
 	return createOperationInternal[TOp, TResult](bus, params, deps)
 }
 
+// ExecuteWith executes op with request-scoped Dependencies, built by depsFactory from
+// ctx immediately before execution. They replace any Dependencies the operation was
+// created with, for this and later executions of the same operation instance; for
+// value-type operations, whose metadata is copied on every call, for this execution only.
+func ExecuteWith[TResult any](
+	ctx context.Context,
+	bus *OperationBus,
+	op Operation[TResult],
+	depsFactory func(ctx context.Context) any,
+) (TResult, error) {
+	deps := depsFactory(ctx)
+	err := bus.checkDependencies(op, deps)
+	if err == nil {
+		err = bus.scopeServices(op, deps)
+	}
+	if err != nil {
+		var zero TResult
+		return zero, err
+	}
+	state := &operationState{bus: bus, deps: deps}
+	if !setOperationState(op, state) {
+		ctx = context.WithValue(ctx, operationStateOverrideKey, operationStateOverride{uuid: op.Metadata().UUID, state: state})
+	}
+	return op.Execute(ctx)
+}
+
 // createOperationInternal is the shared implementation for operation creation
 func createOperationInternal[TOp Operation[TResult], TResult any](
 	bus *OperationBus,
 	params any,
 	deps any,
 ) (TOp, error) {
-	// Use reflection to determine required service type
-	serviceType := getRequiredServiceType[TOp]()
-	service := bus.registry.get(serviceType)
+	// Use reflection to determine the required service types, resolving them from the
+	// current registry snapshot so later re-registration doesn't affect this operation
+	opTypeName := operationTypeName(*new(TOp))
+	opType := reflect.TypeFor[TOp]()
+	layout := layoutOf(opType)
+	if layout.err != nil {
+		bus.logger.Error("Operation creation failed", "operation_type", opTypeName, "error", layout.err)
+		var zero TOp
+		return zero, layout.err
+	}
+	serviceFields := layout.services
+	registry := bus.serviceRegistryFor(opType)
+	services, err := registry.snapshot().resolveServices(serviceFields, opTypeName)
+	if err != nil {
+		bus.logger.Error("Operation creation failed", "operation_type", opTypeName, "error", err)
+		var zero TOp
+		return zero, err
+	}
 
-	// Create metadata for new operation
+	// Create metadata for new operation, associating it with this bus and storing
+	// dependencies for later context enrichment
 	metadata := OperationMetadata{
-		UUID:    generateUUID(),
-		Created: time.Now(),
+		UUID:    bus.newID(),
+		Created: bus.now(),
+		state:   &operationState{bus: bus, deps: deps},
 	}
 
 	// Log operation creation
-	opTypeName := reflect.TypeOf((*TOp)(nil)).Elem().Name()
 	logData := []any{
 		"operation_type", opTypeName,
 		"operation_id", metadata.UUID,
-		"service_type", serviceType.Name(),
 	}
+	logData = append(logData, serviceLogFields(serviceFields)...)
 	if deps != nil {
 		depsType := reflect.TypeOf(deps).String()
 		logData = append(logData, "dependencies_type", depsType)
 	}
 	bus.logger.Info("Operation created", logData...)
 
-	// Create operation with injected service, metadata, and logger
-	op, err := newOperationWithService[TOp](params, service, metadata, bus.logger)
+	// Create operation with injected services, metadata, and logger
+	op, err := newOperationWithService[TOp](layout, bus.pooling, params, services, metadata, bus.logger)
+	if err == nil {
+		err = bus.checkDependencies(op, deps)
+	}
+	if err == nil {
+		err = bus.scopeServices(op, deps)
+	}
 	if err != nil {
 		bus.logger.Error("Operation creation failed",
 			"operation_type", opTypeName,
 			"operation_id", metadata.UUID,
 			"error", err,
 		)
-		return op, err
+		var zero TOp
+		return zero, err
 	}
 
-	// Store dependencies in operation for later context enrichment
-	if deps != nil {
-		storeOperationDependencies(op, deps)
-	}
+	bus.emitCreated(op)
 
 	return op, nil
 }
 
 // DescriptorFactory recreates an executable operation from a serialized descriptor.
-// This method must be implemented by users for their specific operation types.
+// OperationBus implements it by dispatching to factories registered with
+// RegisterDescriptorFactory.
 type DescriptorFactory interface {
 	CreateFromDescriptor(descriptor OperationDescriptor) (any, error)
 }
 
-// getRequiredServiceType extracts the service type from an operation type using reflection.
-func getRequiredServiceType[TOp any]() reflect.Type {
-	opType := reflect.TypeOf((*TOp)(nil)).Elem()
-	if opType.Kind() == reflect.Ptr {
-		opType = opType.Elem()
+// newOperationWithService creates an operation instance using reflection, setting
+// its fields at the indices recorded in layout. If pooled, the struct may be reused
+// from the pool of released operations.
+func newOperationWithService[TOp any](layout *operationLayout, pooled bool, params any, services []serviceInjection, metadata OperationMetadata, logger Logger) (TOp, error) {
+	var zero TOp
+	structValue := layout.newStruct(pooled)
+
+	params, err := applyParamDefaults(params)
+	if err != nil {
+		return zero, err
+	}
+	if err := setParams(structValue.FieldByIndex(layout.params), params); err != nil {
+		return zero, err
+	}
+	injectServices(structValue, services)
+	structValue.FieldByIndex(layout.meta).Set(reflect.ValueOf(metadata))
+	if logger != nil {
+		structValue.FieldByIndex(layout.logger).Set(reflect.ValueOf(logger))
 	}
-	// Convention: look for Service field
-	serviceField, _ := opType.FieldByName("Service")
-	return serviceField.Type
-}
 
-// newOperationWithService creates an operation instance using reflection.
-func newOperationWithService[TOp any](params, service any, metadata OperationMetadata, logger Logger) (TOp, error) {
-	opType := reflect.TypeOf((*TOp)(nil)).Elem()
+	opValue := structValue
+	if layout.pointer {
+		opValue = structValue.Addr()
+	}
+	return opValue.Interface().(TOp), nil
+}
 
-	var opValue reflect.Value
-	if opType.Kind() == reflect.Ptr {
-		structType := opType.Elem()
-		opValue = reflect.New(structType)
-	} else {
-		opValue = reflect.New(opType).Elem()
+// setParams assigns params to an operation's Params field. Nil params leave the
+// field at its zero value, so pointer params may be omitted.
+func setParams(field reflect.Value, params any) error {
+	if params == nil {
+		return nil
+	}
+	value := reflect.ValueOf(params)
+	if !value.Type().AssignableTo(field.Type()) {
+		return fmt.Errorf("params of type %s not assignable to %s", value.Type(), field.Type())
 	}
+	field.Set(value)
+	return nil
+}
+
+// operationState holds bus-side state associated with an operation instance.
+type operationState struct {
+	bus  *OperationBus
+	deps any
+}
+
+// operationStateOverrideKey is the context key for state set by ExecuteWith on
+// operations whose metadata can't be updated in place
+const operationStateOverrideKey contextKey = "commandment:operation:state"
+
+// operationStateOverride is the state ExecuteWith set for the operation with uuid.
+type operationStateOverride struct {
+	uuid  string
+	state *operationState
+}
 
-	// Get the struct value to set fields on
-	structValue := opValue
-	if opType.Kind() == reflect.Ptr {
-		structValue = opValue.Elem()
+// setOperationState replaces the bus-side state held in op's metadata. It reports
+// false for value-type operations, whose metadata is copied on every method call.
+func setOperationState(op any, state *operationState) bool {
+	m, ok := op.(interface{ GetMetadata() *OperationMetadata })
+	if !ok || reflect.ValueOf(op).Kind() != reflect.Pointer {
+		return false
 	}
+	m.GetMetadata().state = state
+	return true
+}
 
-	structValue.FieldByName("Params").Set(reflect.ValueOf(params))
-	structValue.FieldByName("Service").Set(reflect.ValueOf(service))
-	structValue.FieldByName("Meta").Set(reflect.ValueOf(metadata))
-	structValue.FieldByName("Logger").Set(reflect.ValueOf(logger))
+// lookupOperationState retrieves bus-side state for an operation instance,
+// returning nil for operations that were not created by a bus.
+func lookupOperationState(op any) *operationState {
+	if m, ok := op.(interface{ Metadata() OperationMetadata }); ok {
+		return m.Metadata().state
+	}
+	return nil
+}
 
-	if opType.Kind() == reflect.Ptr {
-		result, ok := opValue.Interface().(TOp)
-		if !ok {
-			var zero TOp
-			return zero, fmt.Errorf("type assertion failed: got %T, expected %T", opValue.Interface(), zero)
-		}
-		return result, nil
-	} else {
-		result, ok := opValue.Addr().Interface().(TOp)
-		if !ok {
-			var zero TOp
-			return zero, fmt.Errorf("type assertion failed: got %T, expected %T", opValue.Addr().Interface(), zero)
+// executionState returns op's bus-side state for an execution with ctx, preferring
+// state set by ExecuteWith for this execution.
+func executionState(ctx context.Context, op any) *operationState {
+	if override, ok := ctx.Value(operationStateOverrideKey).(operationStateOverride); ok {
+		if m, ok := op.(interface{ Metadata() OperationMetadata }); ok && m.Metadata().UUID == override.uuid {
+			return override.state
 		}
-		return result, nil
 	}
+	return lookupOperationState(op)
 }
 
-// operationDependencies stores dependencies for operations using a weak map pattern
-var operationDependencies = make(map[any]any)
-
-// storeOperationDependencies associates dependencies with an operation instance
-func storeOperationDependencies(op, deps any) {
-	operationDependencies[op] = deps
+// operationBus returns the bus that created op, or nil.
+func operationBus(op any) *OperationBus {
+	if state := lookupOperationState(op); state != nil {
+		return state.bus
+	}
+	return nil
 }
 
 // GetOperationDependencies retrieves dependencies for an operation instance
 func GetOperationDependencies(op any) any {
-	return operationDependencies[op]
+	if state := lookupOperationState(op); state != nil {
+		return state.deps
+	}
+	return nil
 }
@@ -0,0 +1,54 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Bus is the interface of OperationBus used by code that dispatches operations
+// without depending on the concrete bus, so it can be substituted in tests.
+type Bus interface {
+	DescriptorFactory
+	ExecuteAny(ctx context.Context, op any) (any, error)
+	Use(mw ...Middleware)
+	// InvalidateCache evicts a cached query result; typeName may be the query's
+	// short or qualified descriptor type name.
+	InvalidateCache(typeName string, params any)
+}
+
+var _ Bus = (*OperationBus)(nil)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ExecuteAny executes an operation whose result type is not known statically, such
+// as one returned by CreateFromDescriptor. op must have an
+// Execute(context.Context) (T, error) method. A nil ctx is rejected rather than
+// passed on to the operation.
+func (b *OperationBus) ExecuteAny(ctx context.Context, op any) (any, error) {
+	if ctx == nil {
+		return nil, errors.New("cannot execute operation with nil context")
+	}
+	if op, ok := op.(Operation[any]); ok {
+		return op.Execute(ctx)
+	}
+	if op == nil {
+		return nil, errors.New("cannot execute nil operation")
+	}
+	execute := reflect.ValueOf(op).MethodByName("Execute")
+	if !execute.IsValid() || !isExecuteMethod(execute.Type()) {
+		return nil, fmt.Errorf("%T is not an operation: no Execute(context.Context) (T, error) method", op)
+	}
+	out := execute.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	err, _ := out[1].Interface().(error)
+	return out[0].Interface(), err
+}
+
+func isExecuteMethod(t reflect.Type) bool {
+	return t.NumIn() == 1 && t.In(0) == contextType &&
+		t.NumOut() == 2 && t.Out(1) == errorType
+}
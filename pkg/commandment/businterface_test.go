@@ -0,0 +1,152 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// replayDescriptors is code under test that only needs the Bus interface.
+func replayDescriptors(ctx context.Context, bus commandment.Bus, descriptors []commandment.OperationDescriptor) ([]any, error) {
+	results := make([]any, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		op, err := bus.CreateFromDescriptor(descriptor)
+		if err != nil {
+			return nil, err
+		}
+		result, err := bus.ExecuteAny(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Fake Bus recording calls without creating real operations
+type FakeBus struct {
+	created  []string
+	executed int
+}
+
+func (b *FakeBus) CreateFromDescriptor(descriptor commandment.OperationDescriptor) (any, error) {
+	b.created = append(b.created, descriptor.Type)
+	return descriptor.Type, nil
+}
+
+func (b *FakeBus) ExecuteAny(ctx context.Context, op any) (any, error) {
+	b.executed++
+	return "fake:" + op.(string), nil
+}
+
+func (b *FakeBus) Use(mw ...commandment.Middleware)            {}
+func (b *FakeBus) InvalidateCache(typeName string, params any) {}
+
+func TestFakeBusSubstitutesForOperationBus(t *testing.T) {
+	bus := &FakeBus{}
+	descriptors := []commandment.OperationDescriptor{{Type: "A"}, {Type: "B"}}
+
+	results, err := replayDescriptors(context.Background(), bus, descriptors)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if len(bus.created) != 2 || bus.executed != 2 {
+		t.Errorf("Expected 2 operations created and executed, got %v and %d", bus.created, bus.executed)
+	}
+	if results[1] != "fake:B" {
+		t.Errorf("Unexpected result: %v", results[1])
+	}
+}
+
+func TestOperationBusExecuteAny(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.RegisterDescriptorFactory("TestOperation", func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var input string
+		if err := json.Unmarshal(params, &input); err != nil {
+			return nil, err
+		}
+		return commandment.CreateOperation[*TestOperation](bus, input)
+	})
+
+	results, err := replayDescriptors(context.Background(), bus, []commandment.OperationDescriptor{
+		{Type: "TestOperation", Params: "replayed"},
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if results[0] != "result: replayed" {
+		t.Errorf("Unexpected result: %v", results[0])
+	}
+}
+
+func TestExecuteAnyRejectsNonOperations(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+
+	for _, op := range []any{nil, "not an operation", struct{}{}} {
+		if _, err := bus.ExecuteAny(context.Background(), op); err == nil {
+			t.Errorf("Expected error executing %T", op)
+		}
+	}
+}
+
+func TestExecuteAnyRejectsNilContext(t *testing.T) {
+	service := &CountingService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "input")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := bus.ExecuteAny(nil, op); err == nil {
+		t.Error("Expected error executing with a nil context")
+	}
+	if calls := service.calls.Load(); calls != 0 {
+		t.Errorf("Expected operation not to run, got %d service calls", calls)
+	}
+}
+
+func TestExecuteAnyReturnsOperationError(t *testing.T) {
+	service := &ControllableService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "fail")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := bus.ExecuteAny(context.Background(), op); err == nil {
+		t.Errorf("Expected service error, got %v", err)
+	}
+}
+
+func TestBusInvalidateCacheByShortName(t *testing.T) {
+	parent, service := newDirectoryBus(commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute))
+	var bus commandment.Bus = parent.With()
+
+	query, err := commandment.CreateOperation[*QualifiedLookupQuery](parent, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	if _, err := query.Execute(context.Background()); err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+	service.entries["alice"] = "room 2"
+	bus.InvalidateCache("QualifiedLookupQuery", "alice")
+
+	result, err := bus.ExecuteAny(context.Background(), query)
+	if err != nil || result != "room 2" {
+		t.Errorf("Expected query cached by the parent bus to be evicted, got %v, %v", result, err)
+	}
+	if service.lookups != 2 {
+		t.Errorf("Expected invalidated query to re-hit the service, got %d lookups", service.lookups)
+	}
+}
@@ -0,0 +1,111 @@
+package commandment
+
+import (
+	"context"
+	"sync"
+)
+
+// requestCacheKey is the context key for the request-scoped query cache
+const requestCacheKey contextKey = "commandment:cache:request"
+
+// WithRequestCache returns a context carrying a request-scoped cache of query
+// results. Queries executed with the context (or contexts derived from it) reuse
+// results for identical params, and executing any command with it clears the
+// cache, giving read-after-write consistency within the request.
+func WithRequestCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestCacheKey, &requestCache{
+		entries: make(map[string]any),
+	})
+}
+
+// requestCache holds query results for the lifetime of a request context.
+type requestCache struct {
+	mu      sync.Mutex
+	entries map[string]any
+}
+
+func (c *requestCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.entries[key]
+	return v, ok
+}
+
+func (c *requestCache) set(key string, v any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = v
+}
+
+func (c *requestCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// requestCacheFromContext returns the request cache carried by ctx, or nil.
+func requestCacheFromContext(ctx context.Context) *requestCache {
+	cache, _ := ctx.Value(requestCacheKey).(*requestCache)
+	return cache
+}
+
+// requestCacheScope ties one execution to the request cache in its context.
+type requestCacheScope struct {
+	cache   *requestCache
+	key     string // empty when the result is not cacheable
+	command bool
+}
+
+// beginRequestCache prepares request-cache handling for op. Commands clear the
+// cache up front, so queries they run internally don't see stale results.
+func beginRequestCache(ctx context.Context, op any) requestCacheScope {
+	cache := requestCacheFromContext(ctx)
+	if cache == nil {
+		return requestCacheScope{}
+	}
+	if !isQuery(op) {
+		cache.clear()
+		return requestCacheScope{cache: cache, command: true}
+	}
+	key, _ := operationCacheKey(op)
+	return requestCacheScope{cache: cache, key: key}
+}
+
+// lookup returns the cached result for a query, if present.
+func (s requestCacheScope) lookup() (any, bool) {
+	if s.cache == nil || s.key == "" {
+		return nil, false
+	}
+	return s.cache.get(s.key)
+}
+
+// finish caches a successful query result, or clears the cache after a command.
+func (s requestCacheScope) finish(result any, err error) {
+	switch {
+	case s.cache == nil:
+	case s.command:
+		s.cache.clear()
+	case s.key != "" && err == nil:
+		s.cache.set(s.key, result)
+	}
+}
+
+// operationCacheKey derives a cache key from an operation's type and params.
+// It reports false for operations without a descriptor or with unencodable params.
+func operationCacheKey(op any) (string, bool) {
+	d, ok := op.(Describer)
+	if !ok {
+		return "", false
+	}
+	descriptor := d.Descriptor()
+	return cacheKey(descriptor.Type, descriptor.Params)
+}
+
+// cacheKey derives a cache key from a descriptor type name and params.
+func cacheKey(typeName string, params any) (string, bool) {
+	data, err := canonicalParams(params)
+	if err != nil {
+		return "", false
+	}
+	return typeName + ":" + string(data), true
+}
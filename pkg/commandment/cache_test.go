@@ -0,0 +1,172 @@
+package commandment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Key-value service counting reads and writes
+type RecordService struct {
+	records map[string]string
+	reads   int
+}
+
+func (s *RecordService) Read(ctx context.Context, key string) (string, error) {
+	s.reads++
+	return s.records[key], nil
+}
+
+func (s *RecordService) Write(ctx context.Context, params WriteRecordParams) (string, error) {
+	s.records[params.Key] = params.Value
+	return params.Value, nil
+}
+
+// Params for WriteRecordCommand
+type WriteRecordParams struct {
+	Key   string
+	Value string
+}
+
+// Test query reading a record
+type ReadRecordQuery struct {
+	Params  string
+	Service *RecordService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (q *ReadRecordQuery) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, q, func(ctx context.Context) (string, error) {
+		return q.Service.Read(ctx, q.Params)
+	})
+}
+
+func (q *ReadRecordQuery) Metadata() commandment.OperationMetadata {
+	return q.Meta
+}
+
+func (q *ReadRecordQuery) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "ReadRecordQuery",
+		Params:   q.Params,
+		Metadata: q.Meta,
+	}
+}
+
+func (q *ReadRecordQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
+func (q *ReadRecordQuery) GetLogger() commandment.Logger               { return q.Logger }
+func (q *ReadRecordQuery) ReadOnly()                                   {}
+
+// Test command writing a record
+type WriteRecordCommand struct {
+	Params  WriteRecordParams
+	Service *RecordService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *WriteRecordCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return c.Service.Write(ctx, c.Params)
+	})
+}
+
+func (c *WriteRecordCommand) Metadata() commandment.OperationMetadata {
+	return c.Meta
+}
+
+func (c *WriteRecordCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "WriteRecordCommand",
+		Params:   c.Params,
+		Metadata: c.Meta,
+	}
+}
+
+func (c *WriteRecordCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *WriteRecordCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func newRecordBus(opts ...commandment.Option) (*commandment.OperationBus, *RecordService) {
+	service := &RecordService{records: map[string]string{"k": "v1"}}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	return commandment.NewOperationBus(registry, &TestLogger{}, opts...), service
+}
+
+func readRecord(ctx context.Context, t *testing.T, bus *commandment.OperationBus, key string) string {
+	t.Helper()
+	query, err := commandment.CreateOperation[*ReadRecordQuery](bus, key)
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	result, err := query.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Query execution failed: %v", err)
+	}
+	return result
+}
+
+func writeRecord(ctx context.Context, t *testing.T, bus *commandment.OperationBus, key, value string) {
+	t.Helper()
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: key, Value: value})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := cmd.Execute(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+}
+
+func TestRequestCacheInvalidatedByCommand(t *testing.T) {
+	bus, service := newRecordBus()
+	ctx := commandment.WithRequestCache(context.Background())
+
+	readRecord(ctx, t, bus, "k")
+	readRecord(ctx, t, bus, "k")
+	if service.reads != 1 {
+		t.Fatalf("Expected repeated query to be served from request cache, got %d reads", service.reads)
+	}
+
+	writeRecord(ctx, t, bus, "k", "v2")
+
+	if got := readRecord(ctx, t, bus, "k"); got != "v2" {
+		t.Errorf("Expected read-after-write to see %q, got %q", "v2", got)
+	}
+	if service.reads != 2 {
+		t.Errorf("Expected query after command to re-hit the service, got %d reads", service.reads)
+	}
+}
+
+func TestRequestCacheKeyedByParams(t *testing.T) {
+	bus, service := newRecordBus()
+	ctx := commandment.WithRequestCache(context.Background())
+
+	readRecord(ctx, t, bus, "k")
+	readRecord(ctx, t, bus, "other")
+	if service.reads != 2 {
+		t.Errorf("Expected distinct params to miss the cache, got %d reads", service.reads)
+	}
+}
+
+func TestRequestCacheScopedToRequest(t *testing.T) {
+	bus, service := newRecordBus()
+
+	readRecord(commandment.WithRequestCache(context.Background()), t, bus, "k")
+	readRecord(commandment.WithRequestCache(context.Background()), t, bus, "k")
+	readRecord(context.Background(), t, bus, "k")
+	if service.reads != 3 {
+		t.Errorf("Expected separate requests not to share results, got %d reads", service.reads)
+	}
+}
+
+func TestQueryWithoutReadOnlyMarkerIsTreatedAsCommand(t *testing.T) {
+	var _ commandment.Query[string] = &WriteRecordCommand{}
+	bus, _ := newRecordBus()
+	commandment.RegisterOperationType[*WriteRecordCommand](bus)
+
+	if kind := bus.Catalog()[0].Kind; kind != commandment.KindCommand {
+		t.Errorf("Expected an unmarked query to be a command, got %s", kind)
+	}
+}
@@ -0,0 +1,157 @@
+package commandment
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operation kinds reported in OperationInfo.
+const (
+	KindCommand = "command"
+	KindQuery   = "query"
+)
+
+// OperationInfo is machine-readable metadata about an operation type, suitable for
+// generating clients and documentation.
+type OperationInfo struct {
+	Type         string         `json:"type"`
+	Kind         string         `json:"kind"`
+	Services     []string       `json:"services"`
+	ParamsSchema map[string]any `json:"params_schema"`
+	ResultType   string         `json:"result_type"`
+}
+
+// catalog holds the operation types registered with RegisterOperationType.
+type catalog struct {
+	mu    sync.RWMutex
+	infos map[string]OperationInfo
+}
+
+func newCatalog() *catalog {
+	return &catalog{infos: make(map[string]OperationInfo)}
+}
+
+// clone returns an independent copy of the catalog.
+func (c *catalog) clone() *catalog {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return &catalog{infos: maps.Clone(c.infos)}
+}
+
+// RegisterOperationType adds TOp to the bus catalog under its QualifiedTypeName,
+// describing its kind, service, params and result types, and registers its result
+// and params types under the same name for
+// DecodeResult and DecodeDescriptor. Unless a descriptor factory is already
+// registered for the type, it registers one for CreateFromDescriptor that decodes
+// the params, creates the operation with CreateOperation on the bus recreating it,
+// and restores the descriptor's metadata with RestoreMetadata.
+func RegisterOperationType[TOp Operation[TResult], TResult any](bus *OperationBus) {
+	opType := reflect.TypeFor[TOp]()
+	info := OperationInfo{
+		Type:       QualifiedTypeName(*new(TOp)),
+		Kind:       KindCommand,
+		ResultType: reflect.TypeFor[TResult]().String(),
+	}
+	if opType.Implements(reflect.TypeFor[readOnlyOperation]()) {
+		info.Kind = KindQuery
+	}
+	for _, field := range operationServiceFields(opType) {
+		info.Services = append(info.Services, field.Type.String())
+	}
+	params, hasParams := opType.Elem().FieldByName("Params")
+	if hasParams {
+		info.ParamsSchema = typeSchema(params.Type)
+	}
+
+	bus.catalog.mu.Lock()
+	bus.catalog.infos[info.Type] = info
+	bus.catalog.mu.Unlock()
+	RegisterResultType[TResult](bus, info.Type)
+	if hasParams {
+		bus.registerParamsType(info.Type, params.Type)
+		bus.registerBoundDescriptorFactory(info.Type, func(bus *OperationBus, data json.RawMessage, meta OperationMetadata) (any, error) {
+			decoded := reflect.New(params.Type)
+			if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+				return nil, fmt.Errorf("decoding %s params: %w", info.Type, err)
+			}
+			op, err := CreateOperation[TOp](bus, decoded.Elem().Interface())
+			if err != nil {
+				return nil, err
+			}
+			return RestoreMetadata(op, meta), nil
+		})
+	}
+}
+
+// Catalog lists the operation types registered with RegisterOperationType, sorted
+// by type name.
+func (b *OperationBus) Catalog() []OperationInfo {
+	b.catalog.mu.RLock()
+	defer b.catalog.mu.RUnlock()
+	infos := slices.Collect(maps.Values(b.catalog.infos))
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Type < infos[j].Type })
+	return infos
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// typeSchema describes t as a JSON Schema, following encoding/json field naming.
+func typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]any{"type": "integer"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema describes the JSON-encoded fields of struct type t, including the
+// defaults declared by commandment tags.
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := typeSchema(field.Type)
+		if def, ok, _ := paramDefault(field); ok {
+			schema["default"] = def
+		}
+		properties[name] = schema
+	}
+	return map[string]any{"type": "object", "properties": properties}
+}
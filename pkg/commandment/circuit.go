@@ -0,0 +1,165 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without executing, for operations using a service
+// whose circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitState is the state of a service's circuit breaker.
+type CircuitState string
+
+// Circuit breaker states reported by CircuitStates.
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// WithCircuitBreaker trips a breaker per service type after threshold consecutive
+// failed service calls. While it is open, operations using the service fail fast
+// with ErrCircuitOpen. After cooldown it is half-open: a single trial execution is
+// let through, closing the breaker if it succeeds and reopening it if it fails.
+// Only the business logic counts: cache hits, rejected executions, cancellations
+// and ErrNotFound don't, and OperationErrors count as successful calls. Breakers
+// are shared with child buses.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(b *OperationBus) {
+		b.breakers = &circuitBreakers{
+			threshold: threshold,
+			cooldown:  cooldown,
+			circuits:  make(map[string]*circuit),
+		}
+	}
+}
+
+// CircuitStates returns the state of every service's circuit breaker, keyed by
+// service type, for health checks. It is empty without WithCircuitBreaker.
+func (b *OperationBus) CircuitStates() map[string]CircuitState {
+	if b == nil || b.breakers == nil {
+		return map[string]CircuitState{}
+	}
+	return b.breakers.states(time.Now())
+}
+
+// checkCircuit reports ErrCircuitOpen if any of op's services has an open circuit.
+// It is a no-op on a nil bus or one without circuit breakers.
+func (b *OperationBus) checkCircuit(op any) error {
+	if b == nil || b.breakers == nil {
+		return nil
+	}
+	for _, service := range circuitServices(op) {
+		if !b.breakers.allow(service, time.Now()) {
+			return fmt.Errorf("%w: service %s", ErrCircuitOpen, service)
+		}
+	}
+	return nil
+}
+
+// recordCircuit records the outcome of a call to op's services.
+func (b *OperationBus) recordCircuit(op any, err error) {
+	if b == nil || b.breakers == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrNotFound) {
+		return
+	}
+	if _, ok := AsOperationError(err); ok {
+		err = nil // the service answered
+	}
+	for _, service := range circuitServices(op) {
+		b.breakers.record(service, err != nil, time.Now())
+	}
+}
+
+// circuitServices returns the type names of op's injected services.
+func circuitServices(op any) []string {
+	fields := operationServiceFields(reflect.TypeOf(op))
+	services := make([]string, 0, len(fields))
+	for _, field := range fields {
+		services = append(services, field.Type.String())
+	}
+	return services
+}
+
+// circuitBreakers tracks a circuit per service type.
+type circuitBreakers struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the breaker state of one service.
+type circuit struct {
+	failures int       // consecutive failures while closed
+	openedAt time.Time // zero while closed
+	trialAt  time.Time // start of the half-open trial; zero if none is running
+}
+
+func (c *circuit) state(now time.Time, cooldown time.Duration) CircuitState {
+	switch {
+	case c.openedAt.IsZero():
+		return CircuitClosed
+	case now.Sub(c.openedAt) < cooldown:
+		return CircuitOpen
+	default:
+		return CircuitHalfOpen
+	}
+}
+
+// allow reports whether an execution using service may run at now, claiming the
+// trial of a half-open circuit. A trial that never reports back is abandoned after
+// another cooldown.
+func (cb *circuitBreakers) allow(service string, now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c, ok := cb.circuits[service]
+	if !ok {
+		return true
+	}
+	switch c.state(now, cb.cooldown) {
+	case CircuitOpen:
+		return false
+	case CircuitHalfOpen:
+		if !c.trialAt.IsZero() && now.Sub(c.trialAt) < cb.cooldown {
+			return false
+		}
+		c.trialAt = now
+	}
+	return true
+}
+
+func (cb *circuitBreakers) record(service string, failed bool, now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c, ok := cb.circuits[service]
+	if !ok {
+		c = &circuit{}
+		cb.circuits[service] = c
+	}
+	if !failed {
+		*c = circuit{}
+		return
+	}
+	c.failures++
+	if !c.openedAt.IsZero() || c.failures >= cb.threshold {
+		c.openedAt = now
+		c.trialAt = time.Time{}
+	}
+}
+
+func (cb *circuitBreakers) states(now time.Time) map[string]CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	states := make(map[string]CircuitState, len(cb.circuits))
+	for service, c := range cb.circuits {
+		states[service] = c.state(now, cb.cooldown)
+	}
+	return states
+}
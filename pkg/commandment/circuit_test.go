@@ -0,0 +1,107 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service failing while its failing flag is set, counting calls
+type UnreliableService struct {
+	failing atomic.Bool
+	calls   atomic.Int32
+}
+
+func (s *UnreliableService) DoSomething(ctx context.Context, input string) (string, error) {
+	s.calls.Add(1)
+	if s.failing.Load() {
+		return "", errors.New("backend unavailable")
+	}
+	return "ok: " + input, nil
+}
+
+func newUnreliableBus(cooldown time.Duration) (*commandment.OperationBus, *UnreliableService) {
+	service := &UnreliableService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	return commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithCircuitBreaker(3, cooldown)), service
+}
+
+func executeUnreliable(t *testing.T, bus *commandment.OperationBus) error {
+	t.Helper()
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	_, err = op.Execute(context.Background())
+	return err
+}
+
+const testServiceType = "commandment_test.TestService"
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	bus, service := newUnreliableBus(time.Minute)
+	service.failing.Store(true)
+
+	for i := range 3 {
+		if err := executeUnreliable(t, bus); err == nil || errors.Is(err, commandment.ErrCircuitOpen) {
+			t.Fatalf("Expected execution %d to reach the failing service, got %v", i, err)
+		}
+	}
+	if state := bus.CircuitStates()[testServiceType]; state != commandment.CircuitOpen {
+		t.Fatalf("Expected open circuit after 3 failures, got %q", state)
+	}
+
+	if err := executeUnreliable(t, bus); !errors.Is(err, commandment.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if calls := service.calls.Load(); calls != 3 {
+		t.Errorf("Expected open circuit to fail fast without calling the service, got %d calls", calls)
+	}
+}
+
+func TestCircuitBreakerRecoversAfterCooldown(t *testing.T) {
+	bus, service := newUnreliableBus(20 * time.Millisecond)
+	service.failing.Store(true)
+	for range 3 {
+		_ = executeUnreliable(t, bus)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if state := bus.CircuitStates()[testServiceType]; state != commandment.CircuitHalfOpen {
+		t.Fatalf("Expected half-open circuit after cooldown, got %q", state)
+	}
+	if err := executeUnreliable(t, bus); err == nil || errors.Is(err, commandment.ErrCircuitOpen) {
+		t.Fatalf("Expected half-open trial to reach the service, got %v", err)
+	}
+	if state := bus.CircuitStates()[testServiceType]; state != commandment.CircuitOpen {
+		t.Fatalf("Expected failed trial to reopen the circuit, got %q", state)
+	}
+
+	service.failing.Store(false)
+	time.Sleep(30 * time.Millisecond)
+	if err := executeUnreliable(t, bus); err != nil {
+		t.Fatalf("Expected successful trial, got %v", err)
+	}
+	if state := bus.CircuitStates()[testServiceType]; state != commandment.CircuitClosed {
+		t.Errorf("Expected successful trial to close the circuit, got %q", state)
+	}
+	if err := executeUnreliable(t, bus); err != nil {
+		t.Errorf("Expected closed circuit to let executions through, got %v", err)
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	bus, service := newUnreliableBus(time.Minute)
+	for _, failing := range []bool{true, true, false, true, true} {
+		service.failing.Store(failing)
+		_ = executeUnreliable(t, bus)
+	}
+	if state := bus.CircuitStates()[testServiceType]; state != commandment.CircuitClosed {
+		t.Errorf("Expected non-consecutive failures to keep the circuit closed, got %q", state)
+	}
+}
@@ -0,0 +1,127 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// clockKey is the context key for the execution clock
+const clockKey contextKey = "commandment:clock"
+
+// Clock tells the time of operation execution.
+type Clock interface {
+	Now() time.Time
+}
+
+// WithClock returns a context whose operations, and the services they call via Now,
+// read the time from clock instead of the wall clock.
+func WithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey, clock)
+}
+
+// Now returns the time according to the clock carried by ctx, or the wall clock.
+// Services should use it in place of time.Now so their time-dependent logic can be
+// replayed deterministically.
+func Now(ctx context.Context) time.Time {
+	if clock := clockFromContext(ctx); clock != nil {
+		return clock.Now()
+	}
+	return time.Now()
+}
+
+// WithBusClock makes the bus read time from clock, stamping the Created time of the
+// operations it creates and the times of executions whose context carries no clock
+// of its own from WithClock.
+func WithBusClock(clock Clock) Option {
+	return func(b *OperationBus) {
+		b.clock = clock
+	}
+}
+
+// now returns the time according to the bus clock, or the wall clock.
+func (b *OperationBus) now() time.Time {
+	if b != nil && b.clock != nil {
+		return b.clock.Now()
+	}
+	return time.Now()
+}
+
+// clockFromContext returns the clock carried by ctx, or nil.
+func clockFromContext(ctx context.Context) Clock {
+	clock, _ := ctx.Value(clockKey).(Clock)
+	return clock
+}
+
+// ManualClock is a Clock that only moves when set or advanced.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a ManualClock reading now.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to now.
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Replay reconstructs and re-executes, in order, the operations of a recorded log,
+// setting clock to each operation's original Created time before it runs, so that
+// time-dependent logic reading Now sees the times it originally ran at. Operations
+// are reconstructed with CreateFromDescriptor and keep their recorded metadata,
+// UUID and Created time included. It returns one result per operation, and the
+// joined errors of those that failed.
+func (b *OperationBus) Replay(ctx context.Context, log []OperationDescriptor, clock *ManualClock) ([]ReplayResult, error) {
+	return b.replay(WithClock(ctx, clock), log, func(i int) error {
+		clock.Set(log[i].Metadata.Created)
+		return nil
+	})
+}
+
+// replay reconstructs and re-executes descriptors in order, restoring each one's
+// recorded metadata on the reconstructed operation. before is called with the index
+// of each descriptor ahead of its execution; an error from it fails the descriptor
+// without executing it. It returns one result per descriptor, and the joined errors
+// of those that failed.
+func (b *OperationBus) replay(ctx context.Context, descriptors []OperationDescriptor, before func(i int) error) ([]ReplayResult, error) {
+	results := make([]ReplayResult, 0, len(descriptors))
+	var errs []error
+	for i, descriptor := range descriptors {
+		replay := ReplayResult{Descriptor: descriptor}
+		if err := before(i); err != nil {
+			replay.Err = err
+		} else if op, err := b.CreateFromDescriptor(descriptor); err != nil {
+			replay.Err = err
+		} else {
+			replay.Result, replay.Err = b.ExecuteAny(ctx, restoreMetadataOf(op, descriptor.Metadata))
+		}
+		if replay.Err != nil {
+			errs = append(errs, fmt.Errorf("replaying %s %s: %w",
+				descriptor.Type, descriptor.Metadata.UUID, replay.Err))
+		}
+		results = append(results, replay)
+	}
+	return results, errors.Join(errs...)
+}
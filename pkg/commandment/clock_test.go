@@ -0,0 +1,173 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service stamping its input with the execution time
+type TimestampService struct{}
+
+func (s *TimestampService) DoSomething(ctx context.Context, input string) (string, error) {
+	return input + "@" + commandment.Now(ctx).UTC().Format(time.RFC3339), nil
+}
+
+func TestReplaySetsClockToRecordedCreatedTimes(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &TimestampService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	sink := &CompletedMetadataSink{}
+	bus.AddEventSink(sink)
+	bus.RegisterDescriptorFactory("TestOperation", func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var input string
+		if err := json.Unmarshal(params, &input); err != nil {
+			return nil, err
+		}
+		return commandment.CreateOperation[*TestOperation](bus, input)
+	})
+
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	var log []commandment.OperationDescriptor
+	for i, input := range []string{"a", "b", "c"} {
+		log = append(log, commandment.OperationDescriptor{
+			Type:     "TestOperation",
+			Params:   input,
+			Metadata: commandment.OperationMetadata{UUID: input, Created: base.Add(time.Duration(i) * time.Hour)},
+		})
+	}
+
+	clock := commandment.NewManualClock(time.Time{})
+	results, err := bus.Replay(context.Background(), log, clock)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	want := []string{"a@2024-03-01T09:00:00Z", "b@2024-03-01T10:00:00Z", "c@2024-03-01T11:00:00Z"}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(results))
+	}
+	for i, result := range results {
+		if result.Result != want[i] {
+			t.Errorf("Expected replay %d to see recorded time %q, got %v", i, want[i], result.Result)
+		}
+	}
+	if !clock.Now().Equal(log[2].Metadata.Created) {
+		t.Errorf("Expected clock to end at the last recorded time, got %v", clock.Now())
+	}
+	if len(sink.metadata) != len(log) {
+		t.Fatalf("Expected %d completed executions, got %d", len(log), len(sink.metadata))
+	}
+	for i, meta := range sink.metadata {
+		if recorded := log[i].Metadata; meta.UUID != recorded.UUID || !meta.Created.Equal(recorded.Created) {
+			t.Errorf("Expected replay %d to keep UUID %q created %v, got %q created %v",
+				i, recorded.UUID, recorded.Created, meta.UUID, meta.Created)
+		}
+	}
+}
+
+func TestBusClockStampsCreatedAndExecutedTimes(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &TimestampService{})
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithBusClock(commandment.NewManualClock(at)))
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if meta := op.Metadata(); !meta.Created.Equal(at) || !meta.Executed.Equal(at) {
+		t.Errorf("Expected times from the bus clock, got created %v executed %v", meta.Created, meta.Executed)
+	}
+	if result != "x@2024-03-01T09:00:00Z" {
+		t.Errorf("Expected the service to read the bus clock, got %q", result)
+	}
+}
+
+func TestClockStampsExecutionMetadata(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(commandment.WithClock(context.Background(), commandment.NewManualClock(at))); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	meta := op.Metadata()
+	if !meta.Executed.Equal(at) || !meta.Returned.Equal(at) {
+		t.Errorf("Expected execution times from the injected clock, got executed %v returned %v", meta.Executed, meta.Returned)
+	}
+}
+
+func TestMetadataDurationRecordedOnReturn(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	clock := commandment.NewManualClock(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithMiddleware(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+			return func(ctx context.Context) (any, error) {
+				clock.Advance(1500 * time.Millisecond)
+				return next(ctx)
+			}
+		}))
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(commandment.WithClock(context.Background(), clock)); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	meta := op.Metadata()
+	if meta.Duration() != 1500*time.Millisecond || meta.DurationMs != 1500 {
+		t.Errorf("Expected 1.5s duration, got %v (%dms)", meta.Duration(), meta.DurationMs)
+	}
+	data, err := json.Marshal(op.Descriptor())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	var persisted commandment.OperationDescriptor
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		t.Fatalf("Failed to unmarshal descriptor: %v", err)
+	}
+	if persisted.Metadata.DurationMs != 1500 {
+		t.Errorf("Expected persisted descriptor to carry the duration, got %dms", persisted.Metadata.DurationMs)
+	}
+}
+
+func TestMetadataDurationZeroUntilReturned(t *testing.T) {
+	executed := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for name, meta := range map[string]commandment.OperationMetadata{
+		"not executed": {Created: executed},
+		"not returned": {Created: executed, Executed: executed},
+		"re-executing": {Executed: executed, Returned: executed.Add(-time.Second)},
+	} {
+		if d := meta.Duration(); d != 0 {
+			t.Errorf("%s: expected zero duration, got %v", name, d)
+		}
+	}
+
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	op, err := commandment.CreateOperation[*TestOperation](commandment.NewOperationBus(registry, &TestLogger{}), "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if meta := op.Metadata(); meta.Duration() != 0 || meta.DurationMs != 0 {
+		t.Errorf("Expected no duration before execution, got %v (%dms)", meta.Duration(), meta.DurationMs)
+	}
+}
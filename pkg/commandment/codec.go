@@ -0,0 +1,61 @@
+package commandment
+
+import (
+	"encoding/json"
+)
+
+// Codec encodes values of type T, such as descriptors to persist or results to
+// measure, and decodes them back.
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// DescriptorCodec is the Codec of OperationDescriptors. Decoded params need only be
+// something CreateFromDescriptor can encode as JSON for the registered factory,
+// such as json.RawMessage.
+type DescriptorCodec = Codec[OperationDescriptor]
+
+// JSONDescriptorCodec is the default DescriptorCodec, encoding descriptors as JSON as
+// OperationDescriptor.MarshalJSON does. It decodes params as json.RawMessage.
+var JSONDescriptorCodec DescriptorCodec = jsonDescriptorCodec{}
+
+type jsonDescriptorCodec struct{}
+
+func (jsonDescriptorCodec) Encode(descriptor OperationDescriptor) ([]byte, error) {
+	return json.Marshal(descriptor)
+}
+
+func (jsonDescriptorCodec) Decode(data []byte) (OperationDescriptor, error) {
+	var decoded struct {
+		OperationDescriptor
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return OperationDescriptor{}, err
+	}
+	descriptor := decoded.OperationDescriptor
+	descriptor.Params = decoded.Params
+	return descriptor, nil
+}
+
+// jsonCodec encodes values as JSON, decoding them as encoding/json does into any.
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Decode(data []byte) (any, error) {
+	var v any
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// CreateFromEncodedDescriptor decodes data with codec, as DecodeDescriptor does,
+// and recreates the operation as CreateFromDescriptor does.
+func (b *OperationBus) CreateFromEncodedDescriptor(data []byte, codec DescriptorCodec) (any, error) {
+	descriptor, err := b.DecodeDescriptor(data, codec)
+	if err != nil {
+		return nil, err
+	}
+	return b.CreateFromDescriptor(descriptor)
+}
@@ -0,0 +1,122 @@
+package commandment_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+	"github.com/davidlee/commandment/pkg/commandment/gobcodec"
+)
+
+func TestDescriptorCodecsRoundTrip(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 30, 15, 123456789, time.FixedZone("CET", 3600))
+	descriptor := commandment.OperationDescriptor{
+		Type:    "WriteRecordCommand",
+		Version: 2,
+		Params:  WriteRecordParams{Key: "k", Value: "v"},
+		Metadata: commandment.OperationMetadata{
+			UUID:     "op-1",
+			Created:  created,
+			Executed: created.Add(time.Nanosecond),
+			Extras:   map[string]string{"tenant": "acme"},
+		},
+	}
+
+	for name, codec := range map[string]commandment.DescriptorCodec{
+		"json": commandment.JSONDescriptorCodec,
+		"gob":  gobcodec.Codec{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Encode(descriptor)
+			if err != nil {
+				t.Fatalf("Failed to encode descriptor: %v", err)
+			}
+			decoded, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("Failed to decode descriptor: %v", err)
+			}
+
+			if decoded.Type != descriptor.Type || decoded.Version != descriptor.Version {
+				t.Errorf("Expected type %s v%d, got %s v%d", descriptor.Type, descriptor.Version, decoded.Type, decoded.Version)
+			}
+			if !decoded.Metadata.Created.Equal(created) || !decoded.Metadata.Executed.Equal(created.Add(time.Nanosecond)) {
+				t.Errorf("Expected timestamps to keep nanosecond precision, got %v and %v",
+					decoded.Metadata.Created, decoded.Metadata.Executed)
+			}
+			if _, offset := decoded.Metadata.Created.Zone(); offset != 3600 {
+				t.Errorf("Expected zone offset to survive, got %d", offset)
+			}
+			if decoded.Metadata.UUID != "op-1" || decoded.Metadata.Extras["tenant"] != "acme" {
+				t.Errorf("Expected metadata to survive, got %+v", decoded.Metadata)
+			}
+			var params WriteRecordParams
+			if err := json.Unmarshal(decoded.Params.(json.RawMessage), &params); err != nil || params != descriptor.Params {
+				t.Errorf("Expected params %+v, got %+v (%v)", descriptor.Params, params, err)
+			}
+		})
+	}
+}
+
+func TestCreateFromEncodedDescriptor(t *testing.T) {
+	bus, _ := newRecordBus()
+	bus.RegisterDescriptorFactory("WriteRecordCommand", func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var p WriteRecordParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return commandment.CreateOperation[*WriteRecordCommand](bus, p)
+	})
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+
+	for _, codec := range []commandment.DescriptorCodec{nil, gobcodec.Codec{}} {
+		encoder := codec
+		if encoder == nil {
+			encoder = commandment.JSONDescriptorCodec
+		}
+		data, err := encoder.Encode(cmd.Descriptor())
+		if err != nil {
+			t.Fatalf("Failed to encode descriptor: %v", err)
+		}
+		recreated, err := bus.CreateFromEncodedDescriptor(data, codec)
+		if err != nil {
+			t.Fatalf("Failed to recreate command with %T: %v", codec, err)
+		}
+		if got, ok := recreated.(*WriteRecordCommand); !ok || got.Params != cmd.Params {
+			t.Errorf("Expected recreated command with params %+v, got %#v", cmd.Params, recreated)
+		}
+	}
+}
+
+func TestCreateFromEncodedDescriptorRejectsCorruptData(t *testing.T) {
+	bus, _ := newRecordBus()
+	if _, err := bus.CreateFromEncodedDescriptor([]byte("not a descriptor"), gobcodec.Codec{}); err == nil {
+		t.Error("Expected corrupt data to fail decoding")
+	}
+}
+
+func TestDecodeDescriptorRestoresRegisteredParamsType(t *testing.T) {
+	bus, _ := newRecordBus()
+	commandment.RegisterParamsType[WriteRecordParams](bus, "WriteRecordCommand")
+	descriptor := commandment.OperationDescriptor{
+		Type:   commandment.QualifiedTypeName(&WriteRecordCommand{}),
+		Params: WriteRecordParams{Key: "k", Value: "v"},
+	}
+
+	for _, codec := range []commandment.DescriptorCodec{commandment.JSONDescriptorCodec, gobcodec.Codec{}} {
+		data, err := codec.Encode(descriptor)
+		if err != nil {
+			t.Fatalf("Failed to encode descriptor: %v", err)
+		}
+		decoded, err := bus.DecodeDescriptor(data, codec)
+		if err != nil {
+			t.Fatalf("Failed to decode descriptor with %T: %v", codec, err)
+		}
+		if decoded.Params != descriptor.Params {
+			t.Errorf("Expected params %#v with %T, got %#v", descriptor.Params, codec, decoded.Params)
+		}
+	}
+}
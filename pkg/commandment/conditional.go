@@ -0,0 +1,55 @@
+package commandment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ifNoneMatchKey is the context key for the caller's known result ETag
+const ifNoneMatchKey contextKey = "commandment:conditional:if-none-match"
+
+// ErrNotModified is returned by a conditional query whose result matches the ETag
+// the caller already holds, so the result need not be serialized or sent again.
+var ErrNotModified = errors.New("result not modified")
+
+// WithIfNoneMatch makes the next query executed with ctx conditional: if its
+// result's ETag equals etag, it returns ErrNotModified and a zero result instead.
+// The condition applies only to that query, not to operations it executes.
+func WithIfNoneMatch(ctx context.Context, etag string) context.Context {
+	return context.WithValue(ctx, ifNoneMatchKey, etag)
+}
+
+// ResultETag returns the ETag of a query result: a hash of its canonical JSON
+// encoding, so equal results have equal ETags across processes.
+func ResultETag(result any) (string, error) {
+	data, err := canonicalJSON(result)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ifNoneMatch returns the ETag a conditional execution must not match, if any.
+func ifNoneMatch(ctx context.Context) string {
+	etag, _ := ctx.Value(ifNoneMatchKey).(string)
+	return etag
+}
+
+// checkNotModified reports ErrNotModified if op is a query whose result matches etag.
+func checkNotModified(op any, etag string, result any) error {
+	if etag == "" || !isQuery(op) {
+		return nil
+	}
+	current, err := ResultETag(result)
+	if err != nil {
+		return fmt.Errorf("computing result etag: %w", err)
+	}
+	if current == etag {
+		return ErrNotModified
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestConditionalQueryMatchingETagNotModified(t *testing.T) {
+	bus, _ := newRecordBus()
+	etag, err := commandment.ResultETag(readRecord(context.Background(), t, bus, "k"))
+	if err != nil {
+		t.Fatalf("Failed to compute etag: %v", err)
+	}
+
+	query, err := commandment.CreateOperation[*ReadRecordQuery](bus, "k")
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	result, err := query.Execute(commandment.WithIfNoneMatch(context.Background(), etag))
+	if !errors.Is(err, commandment.ErrNotModified) {
+		t.Fatalf("Expected ErrNotModified, got %v", err)
+	}
+	if result != "" {
+		t.Errorf("Expected zero result when not modified, got %q", result)
+	}
+}
+
+func TestConditionalQueryStaleETagReturnsResult(t *testing.T) {
+	bus, _ := newRecordBus()
+	etag, err := commandment.ResultETag(readRecord(context.Background(), t, bus, "k"))
+	if err != nil {
+		t.Fatalf("Failed to compute etag: %v", err)
+	}
+	writeRecord(context.Background(), t, bus, "k", "v2")
+
+	if got := readRecord(commandment.WithIfNoneMatch(context.Background(), etag), t, bus, "k"); got != "v2" {
+		t.Errorf("Expected full result for stale etag, got %q", got)
+	}
+}
+
+func TestConditionalETagIgnoredByCommands(t *testing.T) {
+	bus, _ := newRecordBus()
+	etag, err := commandment.ResultETag("v1")
+	if err != nil {
+		t.Fatalf("Failed to compute etag: %v", err)
+	}
+	writeRecord(commandment.WithIfNoneMatch(context.Background(), etag), t, bus, "k", "v1")
+}
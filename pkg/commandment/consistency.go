@@ -0,0 +1,50 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// eventualConsistencyBackoff is the initial wait between attempts of
+// ExecuteWithEventualConsistency; it doubles after each attempt.
+const eventualConsistencyBackoff = 10 * time.Millisecond
+
+// ExecuteWithEventualConsistency executes op, retrying with exponential backoff
+// while it fails with ErrNotFound, for up to maxWait. It suits reads of data that
+// was just written to an eventually-consistent store. Before each retry the op's
+// query cache entry is invalidated, so a cached not-found result is not replayed.
+// When maxWait elapses it returns the last ErrNotFound.
+func ExecuteWithEventualConsistency[T any](ctx context.Context, op Operation[T], maxWait time.Duration) (T, error) {
+	deadline := time.Now().Add(maxWait)
+	backoff := eventualConsistencyBackoff
+	for {
+		result, err := op.Execute(ctx)
+		if !errors.Is(err, ErrNotFound) {
+			return result, err
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return result, err
+		}
+		wait := min(backoff, remaining)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return result, errors.Join(err, ctx.Err())
+		}
+		backoff *= 2
+		invalidateOperationCache(op)
+	}
+}
+
+// invalidateOperationCache removes op's entry from its bus query cache, if any.
+func invalidateOperationCache(op any) {
+	bus := operationBus(op)
+	d, ok := op.(Describer)
+	if bus == nil || !ok {
+		return
+	}
+	descriptor := d.Descriptor()
+	bus.InvalidateCache(descriptor.Type, descriptor.Params)
+}
@@ -0,0 +1,117 @@
+package commandment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// ErrUnknownResultVersion is returned by ExecuteFormatted and ExecuteJSON for an API
+// version the bus has no converters for and that was not registered with
+// RegisterResultVersion.
+var ErrUnknownResultVersion = errors.New("unknown result version")
+
+// converterKey identifies a converter by the internal result type and API version.
+type converterKey struct {
+	from    reflect.Type
+	version string
+}
+
+// converterRegistry maps internal result types to versioned external representations.
+type converterRegistry struct {
+	mu         sync.RWMutex
+	converters map[converterKey]func(any) (any, error)
+	versions   map[string]bool
+}
+
+func newConverterRegistry() *converterRegistry {
+	return &converterRegistry{
+		converters: make(map[converterKey]func(any) (any, error)),
+		versions:   make(map[string]bool),
+	}
+}
+
+// clone returns an independent copy of the registry.
+func (r *converterRegistry) clone() *converterRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &converterRegistry{converters: maps.Clone(r.converters), versions: maps.Clone(r.versions)}
+}
+
+// RegisterResultVersion declares version as an API version of bus, so
+// ExecuteFormatted and ExecuteJSON accept it even without converters registered for
+// it. Results without a converter for the version are returned unchanged.
+func RegisterResultVersion(bus *OperationBus, version string) {
+	bus.converters.mu.Lock()
+	defer bus.converters.mu.Unlock()
+	bus.converters.versions[version] = true
+}
+
+// RegisterResultConverter registers convert as the representation of results of type
+// From in the given API version. ExecuteFormatted and ExecuteJSON apply it to
+// operations created by bus, so the internal result type can evolve independently of
+// the DTOs exposed to API clients.
+func RegisterResultConverter[From, To any](bus *OperationBus, version string, convert func(From) (To, error)) {
+	key := converterKey{from: reflect.TypeOf((*From)(nil)).Elem(), version: version}
+	bus.converters.mu.Lock()
+	defer bus.converters.mu.Unlock()
+	bus.converters.versions[version] = true
+	bus.converters.converters[key] = func(result any) (any, error) {
+		from, ok := result.(From)
+		if !ok {
+			return nil, fmt.Errorf("result converter for %s: got %T", key.from, result)
+		}
+		return convert(from)
+	}
+}
+
+// convertResult converts result to its representation in version, reporting
+// ErrUnknownResultVersion for a version unknown to the bus. Results without a
+// converter for a known version, and results of operations not created by a bus,
+// are returned unchanged.
+func (b *OperationBus) convertResult(result any, version string) (any, error) {
+	if b == nil {
+		return result, nil
+	}
+	key := converterKey{from: reflect.TypeOf(result), version: version}
+	b.converters.mu.RLock()
+	known := b.converters.versions[version]
+	convert, ok := b.converters.converters[key]
+	b.converters.mu.RUnlock()
+	if !known {
+		return nil, ErrUnknownResultVersion
+	}
+	if !ok {
+		return result, nil
+	}
+	return convert(result)
+}
+
+// ExecuteFormatted executes op and converts its result to the representation
+// registered for the given API version with RegisterResultConverter. A version
+// unknown to op's bus fails with ErrUnknownResultVersion.
+func ExecuteFormatted[T any](ctx context.Context, op Operation[T], version string) (any, error) {
+	result, err := op.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := operationBus(op).convertResult(result, version)
+	if err != nil {
+		return nil, fmt.Errorf("converting %s result to version %q: %w", operationTypeName(op), version, err)
+	}
+	return formatted, nil
+}
+
+// ExecuteJSON executes op and returns its result, converted for the given API version,
+// encoded as JSON.
+func ExecuteJSON[T any](ctx context.Context, op Operation[T], version string) ([]byte, error) {
+	formatted, err := ExecuteFormatted(ctx, op, version)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(formatted)
+}
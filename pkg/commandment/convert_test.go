@@ -0,0 +1,81 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func newConvertingBus(t *testing.T) *commandment.OperationBus {
+	t.Helper()
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &CountingService{})
+	return commandment.NewOperationBus(registry, &TestLogger{})
+}
+
+func TestExecuteFormattedAppliesVersionConverter(t *testing.T) {
+	bus := newConvertingBus(t)
+	commandment.RegisterResultConverter(bus, "v2", func(result string) (map[string]string, error) {
+		return map[string]string{"message": result}, nil
+	})
+	commandment.RegisterResultVersion(bus, "v1")
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "hello")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	body, err := commandment.ExecuteJSON(context.Background(), op, "v2")
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if string(body) != `{"message":"counted: hello"}` {
+		t.Errorf("Unexpected v2 body: %s", body)
+	}
+
+	formatted, err := commandment.ExecuteFormatted(context.Background(), op, "v1")
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if formatted != "counted: hello" {
+		t.Errorf("Expected version without a converter to return the raw result, got %v", formatted)
+	}
+}
+
+func TestExecuteFormattedRejectsUnknownVersion(t *testing.T) {
+	bus := newConvertingBus(t)
+	commandment.RegisterResultConverter(bus, "v2", func(result string) (string, error) {
+		return result, nil
+	})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "hello")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	if _, err := commandment.ExecuteFormatted(context.Background(), op, "v9"); !errors.Is(err, commandment.ErrUnknownResultVersion) {
+		t.Errorf("Expected ErrUnknownResultVersion, got %v", err)
+	}
+	if _, err := commandment.ExecuteJSON(context.Background(), op, "v9"); !errors.Is(err, commandment.ErrUnknownResultVersion) {
+		t.Errorf("Expected ErrUnknownResultVersion from ExecuteJSON, got %v", err)
+	}
+}
+
+func TestExecuteFormattedReportsConverterError(t *testing.T) {
+	bus := newConvertingBus(t)
+	errUnsupported := errors.New("unsupported in v3")
+	commandment.RegisterResultConverter(bus, "v3", func(result string) (string, error) {
+		return "", errUnsupported
+	})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "hello")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	if _, err := commandment.ExecuteFormatted(context.Background(), op, "v3"); !errors.Is(err, errUnsupported) {
+		t.Errorf("Expected converter error, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package commandment
+
+import "context"
+
+// correlationIDKey is the context key for the correlation ID of the current request
+const correlationIDKey contextKey = "commandment:correlation_id"
+
+// WithCorrelationID records a correlation ID, such as a request ID received on an
+// incoming request, for operations executed with ctx. Operations record it in their
+// metadata, and operations they execute in turn inherit it.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, correlationID)
+}
+
+// CorrelationIDFromContext retrieves the correlation ID from context. During
+// execution it is the correlation ID recorded in the operation's metadata.
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	correlationID, ok := ctx.Value(correlationIDKey).(string)
+	return correlationID, ok && correlationID != ""
+}
+
+// parentUUID returns the ID of the operation executing with ctx, whose business
+// logic is executing the operation identified by uuid, or "" if there is none.
+func parentUUID(ctx context.Context, uuid string) string {
+	if parent := executionFromContext(ctx); parent != nil && parent.metadata.UUID != uuid {
+		return parent.metadata.UUID
+	}
+	return ""
+}
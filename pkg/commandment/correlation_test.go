@@ -0,0 +1,101 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// EventSink keeping the metadata of completed executions
+type CompletedMetadataSink struct {
+	mu       sync.Mutex
+	metadata []commandment.OperationMetadata
+}
+
+func (s *CompletedMetadataSink) OnCreated(descriptor commandment.OperationDescriptor) {}
+func (s *CompletedMetadataSink) OnStarted(metadata commandment.OperationMetadata)     {}
+
+func (s *CompletedMetadataSink) OnCompleted(metadata commandment.OperationMetadata, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadata = append(s.metadata, metadata)
+}
+
+func newCorrelatedBus() (*commandment.OperationBus, *CompletedMetadataSink) {
+	registry := commandment.NewServiceRegistry()
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	commandment.RegisterService(registry, &ParentService{bus: bus})
+	commandment.RegisterService(registry, &ChildService{calls: make(map[string]int)})
+	sink := &CompletedMetadataSink{}
+	bus.AddEventSink(sink)
+	return bus, sink
+}
+
+func TestCorrelationIDPropagatesToNestedOperations(t *testing.T) {
+	bus, sink := newCorrelatedBus()
+	ctx := commandment.WithCorrelationID(context.Background(), "request-42")
+
+	parent, err := commandment.CreateOperation[*ParentOperation](bus, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := parent.Execute(ctx); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if len(sink.metadata) != 3 {
+		t.Fatalf("Expected the parent and two children to complete, got %d", len(sink.metadata))
+	}
+	for _, child := range sink.metadata[:2] {
+		if child.CorrelationID != "request-42" || child.ParentUUID != parent.Metadata().UUID {
+			t.Errorf("Expected child correlated with request-42 under %s, got %+v", parent.Metadata().UUID, child)
+		}
+	}
+	if meta := parent.Metadata(); meta.CorrelationID != "request-42" || meta.ParentUUID != "" {
+		t.Errorf("Expected top-level parent correlated without a parent, got %+v", meta)
+	}
+}
+
+func TestNestedOperationsLinkToParentWithoutCorrelationID(t *testing.T) {
+	bus, sink := newCorrelatedBus()
+
+	parent, err := commandment.CreateOperation[*ParentOperation](bus, []string{"a"})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := parent.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if child := sink.metadata[0]; child.ParentUUID != parent.Metadata().UUID || child.CorrelationID != "" {
+		t.Errorf("Expected child linked to its parent only, got %+v", child)
+	}
+}
+
+func TestCorrelationIDsSerializeInDescriptor(t *testing.T) {
+	bus, _ := newRecordBus()
+	ctx := commandment.WithCorrelationID(context.Background(), "request-42")
+
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if _, err := cmd.Execute(ctx); err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	data, err := json.Marshal(cmd.Descriptor())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	if !strings.Contains(string(data), `"correlation_id":"request-42"`) {
+		t.Errorf("Expected correlation_id in the serialized descriptor, got %s", data)
+	}
+	var descriptor commandment.OperationDescriptor
+	if err := json.Unmarshal(data, &descriptor); err != nil || descriptor.Metadata.CorrelationID != "request-42" {
+		t.Errorf("Expected correlation ID to round-trip, got %q, %v", descriptor.Metadata.CorrelationID, err)
+	}
+}
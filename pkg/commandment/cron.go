@@ -0,0 +1,227 @@
+package commandment
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cron executes the operation produced by factory on the schedule described by spec,
+// returning a func that stops the schedule. Each run creates a fresh operation and
+// executes it through the normal pipeline; stopping cancels the context of a run
+// in progress and waits for the scheduler to exit.
+//
+// spec is a standard five-field cron expression (minute hour day-of-month month
+// day-of-week) supporting "*", lists, ranges and steps, with Sunday as 0 or 7 in
+// the day-of-week field, one of the @hourly, @daily,
+// @weekly, @monthly or @yearly shorthands, or "@every <duration>".
+func (b *OperationBus) Cron(spec string, factory func() Operation[any]) (func(), error) {
+	schedule, err := parseCronSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go b.runSchedule(ctx, spec, schedule, factory, done)
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+	return stop, nil
+}
+
+// runSchedule fires factory-produced operations until ctx is cancelled.
+func (b *OperationBus) runSchedule(ctx context.Context, spec string, schedule cronSchedule, factory func() Operation[any], done chan<- struct{}) {
+	defer close(done)
+	for {
+		now := time.Now()
+		next := schedule.next(now)
+		if next.IsZero() {
+			b.logger.Error("Schedule has no future runs", "schedule", spec)
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		op := factory()
+		if op == nil {
+			continue
+		}
+		if _, err := op.Execute(ctx); err != nil {
+			b.logger.Error("Scheduled operation failed",
+				"schedule", spec,
+				"operation_id", op.Metadata().UUID,
+				"error", err,
+			)
+		}
+	}
+}
+
+// cronSchedule computes the next activation strictly after a given time.
+type cronSchedule interface {
+	next(after time.Time) time.Time
+}
+
+// intervalSchedule fires at a fixed interval, as for "@every 5m".
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+func (s intervalSchedule) next(after time.Time) time.Time {
+	return after.Add(s.interval)
+}
+
+// fieldSchedule fires on the minutes matching each of its cron fields.
+type fieldSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+// maxCronSearch bounds how far ahead next searches for a matching time.
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+func (s fieldSchedule) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(maxCronSearch)
+	for t.Before(limit) {
+		switch {
+		case !hasBit(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !hasBit(s.hour, t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !hasBit(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's rule that when both day fields are restricted,
+// a day matching either of them is a match.
+func (s fieldSchedule) dayMatches(t time.Time) bool {
+	domMatch := hasBit(s.dom, t.Day())
+	dowMatch := hasBit(s.dow, int(t.Weekday()))
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+func hasBit(set uint64, n int) bool {
+	return set&(1<<uint(n)) != 0
+}
+
+var cronShorthands = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// parseCronSpec parses a cron expression into a schedule.
+func parseCronSpec(spec string) (cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q: %w", spec, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid cron spec %q: interval must be positive", spec)
+		}
+		return intervalSchedule{interval: interval}, nil
+	}
+	if expanded, ok := cronShorthands[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron spec %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	// Day-of-week accepts 7 as well as 0 for Sunday.
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q: %w", spec, err)
+		}
+		sets[i] = set
+	}
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+
+	return fieldSchedule{
+		minute:        sets[0],
+		hour:          sets[1],
+		dom:           sets[2],
+		month:         sets[3],
+		dow:           sets[4],
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps
+// into a bit set of the matching values within [lo, hi].
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+		}
+
+		start, end := lo, hi
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = strconv.Atoi(first); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", part)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(last); err != nil {
+					return 0, fmt.Errorf("invalid range in %q", part)
+				}
+			} else if hasStep {
+				end = hi
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("value out of range [%d-%d] in %q", lo, hi, part)
+		}
+
+		for n := start; n <= end; n += step {
+			set |= 1 << uint(n)
+		}
+	}
+	return set, nil
+}
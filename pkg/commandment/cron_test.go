@@ -0,0 +1,101 @@
+package commandment_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service counting scheduled executions
+type CountingService struct {
+	calls atomic.Int64
+}
+
+func (s *CountingService) DoSomething(ctx context.Context, input string) (string, error) {
+	s.calls.Add(1)
+	return "counted: " + input, nil
+}
+
+func TestCronFiresOnSchedule(t *testing.T) {
+	service := &CountingService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	stop, err := bus.Cron("@every 10ms", func() commandment.Operation[any] {
+		op, err := commandment.CreateOperation[*TestOperation](bus, "tick")
+		if err != nil {
+			t.Errorf("Failed to create operation: %v", err)
+			return nil
+		}
+		return commandment.AsAnyOperation[string](op)
+	})
+	if err != nil {
+		t.Fatalf("Failed to schedule operation: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for service.calls.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+
+	fired := service.calls.Load()
+	if fired < 3 {
+		t.Fatalf("Expected at least 3 scheduled executions, got %d", fired)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if after := service.calls.Load(); after != fired {
+		t.Errorf("Expected no executions after stop, got %d more", after-fired)
+	}
+}
+
+func TestCronNextRun(t *testing.T) {
+	// Saturday 2024-06-01 10:07
+	base := time.Date(2024, 6, 1, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2024, 7, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1,15 * 0", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * * 7", time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 5-7", time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			next, err := commandment.NextCronRun(tt.spec, base)
+			if err != nil {
+				t.Fatalf("Failed to parse spec: %v", err)
+			}
+			if !next.Equal(tt.expected) {
+				t.Errorf("Expected next run %v, got %v", tt.expected, next)
+			}
+		})
+	}
+}
+
+func TestCronInvalidSpec(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "0 0 * * 8", "@every -1s", "@every soon"} {
+		t.Run(spec, func(t *testing.T) {
+			stop, err := bus.Cron(spec, func() commandment.Operation[any] { return nil })
+			if err == nil {
+				stop()
+				t.Errorf("Expected error for spec %q", spec)
+			}
+		})
+	}
+}
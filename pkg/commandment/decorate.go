@@ -0,0 +1,59 @@
+package commandment
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Decorate composes the common cross-cutting wrappers around op, created by bus, in
+// one call. With cache, a query's first successful result is returned by later
+// executions of the decorated operation, unless bus has a query cache, which then
+// caches it instead; commands are never cached. With retry, failures of a query are
+// retried under DefaultRetryPolicy, as are those of a command executed with an
+// idempotency key, from its metadata or WithIdempotencyKey, so a retry can't apply
+// its effect twice; other commands execute once. Wrap a command with WithRetry to
+// retry it regardless. A positive timeout bounds each attempt, as WithTimeout does.
+func Decorate[TResult any](bus *OperationBus, op Operation[TResult], cache, retry bool, timeout time.Duration) Operation[TResult] {
+	query := isQuery(op)
+	if timeout > 0 {
+		op = WithTimeout(op, timeout)
+	}
+	if retry {
+		op = retryOperation[TResult]{op: op, policy: DefaultRetryPolicy, idempotentOnly: !query}
+	}
+	if cache && query && (bus == nil || bus.queryCache == nil) {
+		op = &memoOperation[TResult]{op: op}
+	}
+	return op
+}
+
+// memoOperation returns the first successful result of the wrapped query thereafter.
+type memoOperation[TResult any] struct {
+	op Operation[TResult]
+
+	mu     sync.Mutex
+	done   bool
+	result TResult
+}
+
+func (m *memoOperation[TResult]) Execute(ctx context.Context) (TResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done {
+		return m.result, nil
+	}
+	result, err := m.op.Execute(ctx)
+	if err == nil {
+		m.result, m.done = result, true
+	}
+	return result, err
+}
+
+func (m *memoOperation[TResult]) Metadata() OperationMetadata {
+	return m.op.Metadata()
+}
+
+func (m *memoOperation[TResult]) Descriptor() OperationDescriptor {
+	return m.op.Descriptor()
+}
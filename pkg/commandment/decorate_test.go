@@ -0,0 +1,187 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Lookup service failing its first calls and answering after a delay
+type UnreliableLookupService struct {
+	mu       sync.Mutex
+	failures int
+	delay    time.Duration
+	calls    int
+}
+
+func (s *UnreliableLookupService) Lookup(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	s.calls++
+	fail := s.calls <= s.failures
+	s.mu.Unlock()
+	if fail {
+		return "", errors.New("transient failure")
+	}
+	select {
+	case <-time.After(s.delay):
+		return "value of " + key, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (s *UnreliableLookupService) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// Test query for decoration
+type UnreliableLookupQuery struct {
+	Params  string
+	Service *UnreliableLookupService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (q *UnreliableLookupQuery) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, q, func(ctx context.Context) (string, error) {
+		return q.Service.Lookup(ctx, q.Params)
+	})
+}
+
+func (q *UnreliableLookupQuery) Metadata() commandment.OperationMetadata {
+	return q.Meta
+}
+
+func (q *UnreliableLookupQuery) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "UnreliableLookupQuery",
+		Params:   q.Params,
+		Metadata: q.Meta,
+	}
+}
+
+func (q *UnreliableLookupQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
+func (q *UnreliableLookupQuery) GetLogger() commandment.Logger               { return q.Logger }
+func (q *UnreliableLookupQuery) ReadOnly()                                   {}
+
+func newUnreliableLookup(t *testing.T, service *UnreliableLookupService) (*commandment.OperationBus, *UnreliableLookupQuery) {
+	t.Helper()
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	query, err := commandment.CreateOperation[*UnreliableLookupQuery](bus, "k")
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	return bus, query
+}
+
+func TestDecoratedQueryRetriedAndCached(t *testing.T) {
+	service := &UnreliableLookupService{failures: 2}
+	bus, query := newUnreliableLookup(t, service)
+	decorated := commandment.Decorate(bus, query, true, true, time.Second)
+
+	for range 3 {
+		result, err := decorated.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("Expected retries to absorb transient failures, got %v", err)
+		}
+		if result != "value of k" {
+			t.Errorf("Unexpected result %q", result)
+		}
+	}
+	if calls := service.Calls(); calls != 3 {
+		t.Errorf("Expected two failed attempts and one success, then cached results; got %d calls", calls)
+	}
+}
+
+func TestDecoratedQueryTimesOutEachAttempt(t *testing.T) {
+	service := &UnreliableLookupService{delay: time.Second}
+	bus, query := newUnreliableLookup(t, service)
+	decorated := commandment.Decorate(bus, query, false, false, 20*time.Millisecond)
+
+	start := time.Now()
+	if _, err := decorated.Execute(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the attempt to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected timeout well before the service answered, took %v", elapsed)
+	}
+}
+
+func TestDecoratedQueryWithoutRetryFailsFast(t *testing.T) {
+	service := &UnreliableLookupService{failures: 1}
+	bus, query := newUnreliableLookup(t, service)
+	decorated := commandment.Decorate(bus, query, true, false, 0)
+
+	if _, err := decorated.Execute(context.Background()); err == nil {
+		t.Fatal("Expected the failure not to be retried")
+	}
+	if _, err := decorated.Execute(context.Background()); err != nil {
+		t.Errorf("Expected a failed result not to be cached, got %v", err)
+	}
+	if calls := service.Calls(); calls != 2 {
+		t.Errorf("Expected one call per execution, got %d", calls)
+	}
+}
+
+// Test command for decoration, sharing the lookup service
+type UnreliableUpdateCommand struct {
+	Params  string
+	Service *UnreliableLookupService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *UnreliableUpdateCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return c.Service.Lookup(ctx, c.Params)
+	})
+}
+
+func (c *UnreliableUpdateCommand) Metadata() commandment.OperationMetadata {
+	return c.Meta
+}
+
+func (c *UnreliableUpdateCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "UnreliableUpdateCommand",
+		Params:   c.Params,
+		Metadata: c.Meta,
+	}
+}
+
+func (c *UnreliableUpdateCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *UnreliableUpdateCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func TestDecoratedCommandRetriedOnlyWithIdempotencyKey(t *testing.T) {
+	service := &UnreliableLookupService{failures: 1}
+	bus, _ := newUnreliableLookup(t, service)
+	cmd, err := commandment.CreateOperation[*UnreliableUpdateCommand](bus, "k")
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	decorated := commandment.Decorate(bus, cmd, false, true, 0)
+
+	if _, err := decorated.Execute(context.Background()); err == nil {
+		t.Fatal("Expected the command without an idempotency key not to be retried")
+	}
+	if calls := service.Calls(); calls != 1 {
+		t.Errorf("Expected a single attempt, got %d calls", calls)
+	}
+
+	service.failures = 2
+	ctx := commandment.WithIdempotencyKey(context.Background(), "update-k")
+	if _, err := decorated.Execute(ctx); err != nil {
+		t.Errorf("Expected the keyed command to be retried, got %v", err)
+	}
+	if calls := service.Calls(); calls != 3 {
+		t.Errorf("Expected a failed attempt and a retry, got %d calls", calls)
+	}
+}
@@ -0,0 +1,185 @@
+package commandment
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrDependencyTypeMismatch is returned in strict dependencies mode when an
+// operation is given Dependencies of a type other than the one it expects.
+var ErrDependencyTypeMismatch = errors.New("dependencies type mismatch")
+
+// DependencyExpecter is implemented by operations declaring the type of
+// Dependencies they work with.
+type DependencyExpecter interface {
+	ExpectedDependencies() reflect.Type
+}
+
+// WithStrictDependencies makes creating or executing an operation implementing
+// DependencyExpecter fail fast with ErrDependencyTypeMismatch when its Dependencies
+// are not assignable to the expected type, instead of leaving the operation to
+// discover the mismatch. Operations without Dependencies are not affected.
+func WithStrictDependencies() Option {
+	return func(b *OperationBus) {
+		b.strictDeps = true
+	}
+}
+
+// checkDependencies reports ErrDependencyTypeMismatch if the bus is strict and deps
+// don't have the type op expects. It is a no-op on a nil bus.
+func (b *OperationBus) checkDependencies(op any, deps any) error {
+	if b == nil || !b.strictDeps || deps == nil {
+		return nil
+	}
+	expecter, ok := op.(DependencyExpecter)
+	if !ok {
+		return nil
+	}
+	expected := expecter.ExpectedDependencies()
+	if expected == nil || reflect.TypeOf(deps).AssignableTo(expected) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s expects %s, got %T",
+		ErrDependencyTypeMismatch, operationTypeName(op), expected, deps)
+}
+
+// ScopedService is implemented by services that build their own copy scoped to an
+// operation's Dependencies, such as one holding a request-scoped database
+// transaction. When an operation is created with Dependencies, or executed with
+// ExecuteWith, each of its services implementing ScopedService is replaced, for that
+// operation only, by the service WithDependencies returns. WithDependencies must not
+// modify its receiver, the registered service shared by every operation, and must
+// return a value assignable to the operation's service field.
+type ScopedService interface {
+	WithDependencies(deps any) any
+}
+
+// WithDependencyFields makes the bus hand Dependencies to services directly: when
+// an operation's service is a pointer to a struct with exported fields that its
+// Dependencies are assignable to, the operation gets its own shallow copy of the
+// service with those fields set. The registered service is never modified, so a
+// request-scoped value such as a database transaction reaches only the operation
+// it was given to. The copy is made when the operation is created, and again by
+// ExecuteWith with the new Dependencies; it lives as long as the operation.
+//
+// Being shallow, the copy shares maps, slices and pointers with the registered
+// service. Services holding a lock, such as a sync.Mutex or an atomic value, can't
+// be copied and fail operation creation with ErrDependencyTypeMismatch; they
+// implement ScopedService instead, which takes precedence over field population.
+func WithDependencyFields() Option {
+	return func(b *OperationBus) {
+		b.depFields = true
+	}
+}
+
+// scopeServices replaces op's services implementing ScopedService with the services
+// they scope to deps and, on a bus created WithDependencyFields, services with
+// fields for deps with copies holding them. It returns ErrDependencyTypeMismatch if
+// a service can't be scoped. Value-type operations, whose fields can't be replaced,
+// are skipped.
+func (b *OperationBus) scopeServices(op any, deps any) error {
+	if deps == nil {
+		return nil
+	}
+	opValue := reflect.ValueOf(op)
+	if opValue.Kind() != reflect.Pointer || opValue.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	depsValue := reflect.ValueOf(deps)
+	for _, field := range operationServiceFields(opValue.Type()) {
+		service := opValue.Elem().FieldByIndex(field.Index)
+		if service.IsZero() {
+			continue
+		}
+		if scoper, ok := service.Interface().(ScopedService); ok {
+			scoped := reflect.ValueOf(scoper.WithDependencies(deps))
+			if !scoped.IsValid() {
+				return fmt.Errorf("%w: %s scoped service for field %s is nil",
+					ErrDependencyTypeMismatch, operationTypeName(op), field.Name)
+			}
+			if !scoped.Type().AssignableTo(field.Type) {
+				return fmt.Errorf("%w: %s scoped service for field %s is %s, not assignable to %s",
+					ErrDependencyTypeMismatch, operationTypeName(op), field.Name, scoped.Type(), field.Type)
+			}
+			service.Set(scoped)
+			continue
+		}
+		if b == nil || !b.depFields {
+			continue
+		}
+		scoped, ok, err := withDependencyFields(service, depsValue)
+		if err != nil {
+			return fmt.Errorf("%w: %s service for field %s: %w",
+				ErrDependencyTypeMismatch, operationTypeName(op), field.Name, err)
+		}
+		if ok {
+			service.Set(scoped)
+		}
+	}
+	return nil
+}
+
+// withDependencyFields returns a copy of the struct service points to with deps set
+// on every exported field it is assignable to. It reports false if there are none,
+// and an error if the struct holds a lock and so can't be copied.
+func withDependencyFields(service reflect.Value, deps reflect.Value) (reflect.Value, bool, error) {
+	if service.Kind() == reflect.Interface {
+		service = service.Elem()
+	}
+	if service.Kind() != reflect.Pointer || service.IsNil() || service.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false, nil
+	}
+	serviceType := service.Elem().Type()
+	var fields [][]int
+	for _, field := range reflect.VisibleFields(serviceType) {
+		if field.IsExported() && !field.Anonymous && isDependencyField(field.Type, deps.Type()) {
+			fields = append(fields, field.Index)
+		}
+	}
+	if len(fields) == 0 {
+		return reflect.Value{}, false, nil
+	}
+	if holdsLock(serviceType) {
+		return reflect.Value{}, false, fmt.Errorf("%s holds a lock and can't be copied; implement ScopedService", serviceType)
+	}
+
+	scoped := reflect.New(serviceType)
+	scoped.Elem().Set(service.Elem())
+	for _, index := range fields {
+		scoped.Elem().FieldByIndex(index).Set(deps)
+	}
+	return scoped, true, nil
+}
+
+// isDependencyField reports whether a field of fieldType receives Dependencies of
+// depsType. Empty interfaces are excluded, as they would match any Dependencies.
+func isDependencyField(fieldType, depsType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Interface && fieldType.NumMethod() == 0 {
+		return false
+	}
+	return depsType.AssignableTo(fieldType)
+}
+
+var lockerType = reflect.TypeOf((*sync.Locker)(nil)).Elem()
+
+// holdsLock reports whether values of t contain, directly or through struct and
+// array elements, a value that must not be copied: one whose pointer is a
+// sync.Locker, as are sync.Mutex and the noCopy guard embedded in sync/atomic types.
+func holdsLock(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(lockerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		return holdsLock(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if holdsLock(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
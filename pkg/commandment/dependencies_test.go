@@ -2,6 +2,8 @@ package commandment_test
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/davidlee/commandment/pkg/commandment"
@@ -37,12 +39,11 @@ type DependencyAwareService struct {
 }
 
 func (s *DependencyAwareService) ProcessWithDependencies(ctx context.Context, input string) (string, error) {
-	deps := commandment.DependenciesFromContext(ctx)
-	if deps == nil {
+	if commandment.DependenciesFromContext(ctx) == nil {
 		return "no-deps:" + input, nil
 	}
 	
-	testDeps, ok := deps.(*TestDependencies)
+	testDeps, ok := commandment.DependenciesAs[*TestDependencies](ctx)
 	if !ok {
 		return "wrong-type:" + input, nil
 	}
@@ -79,6 +80,10 @@ func (op *DependencyAwareOperation) Descriptor() commandment.OperationDescriptor
 func (op *DependencyAwareOperation) GetMetadata() *commandment.OperationMetadata { return &op.Meta }
 func (op *DependencyAwareOperation) GetLogger() commandment.Logger               { return op.Logger }
 
+func (op *DependencyAwareOperation) ExpectedDependencies() reflect.Type {
+	return reflect.TypeFor[*TestDependencies]()
+}
+
 // Test operation that accesses Dependencies directly via GetDependencies
 type DirectDependencyOperation struct {
 	Params  string
@@ -358,4 +363,136 @@ func TestMultipleDependencyTypes(t *testing.T) {
 	if _, ok := deps2.(*SpecialDependencies); !ok {
 		t.Errorf("Operation 2 should have SpecialDependencies, got %T", deps2)
 	}
-}
\ No newline at end of file
+}
+
+func TestDependenciesAs(t *testing.T) {
+	deps := &TestDependencies{Value: "typed"}
+	ctx := commandment.WithDependencies(context.Background(), deps)
+
+	typed, ok := commandment.DependenciesAs[*TestDependencies](ctx)
+	if !ok {
+		t.Fatal("Expected typed Dependencies from context")
+	}
+	if typed != deps {
+		t.Error("Expected the same Dependencies instance")
+	}
+
+	if _, ok := commandment.DependenciesAs[*SpecialDependencies](ctx); ok {
+		t.Error("Expected mismatched Dependencies type to report false")
+	}
+
+	if _, ok := commandment.DependenciesAs[*TestDependencies](context.Background()); ok {
+		t.Error("Expected missing Dependencies to report false")
+	}
+}
+
+func TestDependenciesAsDuringExecution(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[DependencyAwareService](registry, DependencyAwareService{name: "test"})
+
+	bus := commandment.NewOperationBusWithDefaultDependencies(registry, &TestLogger{}, &SpecialDependencies{SpecialValue: "special"})
+
+	op, err := commandment.CreateOperation[*DependencyAwareOperation](bus, "typed-input")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	expected := "wrong-type:typed-input"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+type requestIDKey struct{}
+
+func TestExecuteWithBuildsDependenciesFromContext(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[DependencyAwareService](registry, DependencyAwareService{name: "test"})
+
+	bus := commandment.NewOperationBusWithDefaultDependencies(registry, &TestLogger{}, &TestDependencies{Value: "default"})
+
+	op, err := commandment.CreateOperation[*DependencyAwareOperation](bus, "scoped-input")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+	result, err := commandment.ExecuteWith(ctx, bus, op, func(ctx context.Context) any {
+		requestID, _ := ctx.Value(requestIDKey{}).(string)
+		return &TestDependencies{Value: requestID}
+	})
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	expected := "req-42:scoped-input"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	deps, ok := commandment.GetDependencies(op).(*TestDependencies)
+	if !ok || deps.Value != "req-42" {
+		t.Errorf("Expected constructed Dependencies to be injected into the operation, got %v", commandment.GetDependencies(op))
+	}
+}
+
+func TestExecuteWithOperationNotCreatedByBus(t *testing.T) {
+	op := &DependencyAwareOperation{Params: "manual", Logger: &TestLogger{}}
+
+	result, err := commandment.ExecuteWith(context.Background(), nil, op, func(ctx context.Context) any {
+		return &TestDependencies{Value: "factory"}
+	})
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	expected := "factory:manual"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestStrictDependenciesRejectsMismatchedType(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[DependencyAwareService](registry, DependencyAwareService{name: "test"})
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithStrictDependencies())
+
+	_, err := commandment.CreateOperationWithDependencies[*DependencyAwareOperation](bus, "input", &SpecialDependencies{SpecialValue: "special"})
+	if !errors.Is(err, commandment.ErrDependencyTypeMismatch) {
+		t.Errorf("Expected ErrDependencyTypeMismatch on creation, got %v", err)
+	}
+
+	op, err := commandment.CreateOperationWithDependencies[*DependencyAwareOperation](bus, "input", &TestDependencies{Value: "ok"})
+	if err != nil {
+		t.Fatalf("Expected matching Dependencies to be accepted: %v", err)
+	}
+	_, err = commandment.ExecuteWith(context.Background(), bus, op, func(ctx context.Context) any {
+		return &SpecialDependencies{SpecialValue: "scoped"}
+	})
+	if !errors.Is(err, commandment.ErrDependencyTypeMismatch) {
+		t.Errorf("Expected ErrDependencyTypeMismatch on execution, got %v", err)
+	}
+}
+
+func TestLenientDependenciesPassMismatchedTypeThrough(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[DependencyAwareService](registry, DependencyAwareService{name: "test"})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperationWithDependencies[*DependencyAwareOperation](bus, "input", &SpecialDependencies{SpecialValue: "special"})
+	if err != nil {
+		t.Fatalf("Expected lenient bus to accept mismatched Dependencies: %v", err)
+	}
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if result != "wrong-type:input" {
+		t.Errorf("Expected the operation to see the mismatched Dependencies, got %q", result)
+	}
+}
@@ -0,0 +1,313 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// journalTx is a per-operation database transaction
+type journalTx struct {
+	entries []string
+}
+
+// JournalService records entries within the transaction it is given
+type JournalService struct {
+	mu    sync.Mutex
+	Tx    *journalTx
+	posts int
+}
+
+// WithDependencies returns a journal posting within the operation's transaction.
+func (s *JournalService) WithDependencies(deps any) any {
+	tx, _ := deps.(*journalTx)
+	return &JournalService{Tx: tx}
+}
+
+func (s *JournalService) Post(entry string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.posts++
+	if s.Tx == nil {
+		return "no transaction"
+	}
+	s.Tx.entries = append(s.Tx.entries, entry)
+	return "posted"
+}
+
+// PostEntryCommand posts an entry to the journal
+type PostEntryCommand struct {
+	Params  string
+	Service *JournalService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *PostEntryCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return c.Service.Post(c.Params), nil
+	})
+}
+
+func (c *PostEntryCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "PostEntryCommand", Params: c.Params, Metadata: c.Meta}
+}
+
+func (c *PostEntryCommand) Metadata() commandment.OperationMetadata     { return c.Meta }
+func (c *PostEntryCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *PostEntryCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func newJournalBus(opts ...commandment.Option) (*commandment.OperationBus, *JournalService) {
+	registry := commandment.NewServiceRegistry()
+	shared := &JournalService{}
+	commandment.RegisterService(registry, shared)
+	return commandment.NewOperationBus(registry, &TestLogger{}, opts...), shared
+}
+
+// MisscopedService scopes itself to a value of the wrong type
+type MisscopedService struct{}
+
+func (s *MisscopedService) WithDependencies(deps any) any { return "not a service" }
+
+// MisscopedCommand uses a MisscopedService
+type MisscopedCommand struct {
+	Params  string
+	Service *MisscopedService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *MisscopedCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return "executed", nil
+	})
+}
+
+func (c *MisscopedCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "MisscopedCommand", Params: c.Params, Metadata: c.Meta}
+}
+
+func (c *MisscopedCommand) Metadata() commandment.OperationMetadata     { return c.Meta }
+func (c *MisscopedCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *MisscopedCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func TestScopedServicesCarryPerOperationTransaction(t *testing.T) {
+	bus, shared := newJournalBus()
+	first, second := &journalTx{}, &journalTx{}
+
+	for _, step := range []struct {
+		tx    *journalTx
+		entry string
+	}{{first, "debit"}, {second, "credit"}} {
+		cmd, err := commandment.CreateOperationWithDependencies[*PostEntryCommand](bus, step.entry, step.tx)
+		if err != nil {
+			t.Fatalf("Failed to create command: %v", err)
+		}
+		if result, err := cmd.Execute(context.Background()); err != nil || result != "posted" {
+			t.Fatalf("Expected entry posted, got %q, %v", result, err)
+		}
+	}
+
+	if len(first.entries) != 1 || first.entries[0] != "debit" {
+		t.Errorf("Expected first transaction to hold only the debit, got %v", first.entries)
+	}
+	if len(second.entries) != 1 || second.entries[0] != "credit" {
+		t.Errorf("Expected second transaction to hold only the credit, got %v", second.entries)
+	}
+	if shared.Tx != nil {
+		t.Errorf("Expected registered service to be left without a transaction, got %v", shared.Tx)
+	}
+}
+
+func TestScopedServicesFromExecuteWith(t *testing.T) {
+	bus, _ := newJournalBus()
+	tx := &journalTx{}
+
+	cmd, err := commandment.CreateOperation[*PostEntryCommand](bus, "refund")
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	_, err = commandment.ExecuteWith(context.Background(), bus, cmd, func(ctx context.Context) any { return tx })
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if len(tx.entries) != 1 || tx.entries[0] != "refund" {
+		t.Errorf("Expected request-scoped transaction to hold the refund, got %v", tx.entries)
+	}
+}
+
+func TestScopedServicesLeaveRegisteredServiceWithoutDependencies(t *testing.T) {
+	bus, shared := newJournalBus()
+
+	cmd, err := commandment.CreateOperation[*PostEntryCommand](bus, "debit")
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if result, _ := cmd.Execute(context.Background()); result != "no transaction" {
+		t.Errorf("Expected the registered service used without Dependencies, got %q", result)
+	}
+	if cmd.Service != shared || shared.posts != 1 {
+		t.Errorf("Expected the registered service itself to post once, got %p (%d posts)", cmd.Service, shared.posts)
+	}
+}
+
+func TestScopedServiceOfWrongTypeFailsCreation(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &MisscopedService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	_, err := commandment.CreateOperationWithDependencies[*MisscopedCommand](bus, "x", "deps")
+	if !errors.Is(err, commandment.ErrDependencyTypeMismatch) {
+		t.Errorf("Expected ErrDependencyTypeMismatch, got %v", err)
+	}
+}
+
+// NilScopedService scopes itself to nothing
+type NilScopedService struct{}
+
+func (s *NilScopedService) WithDependencies(deps any) any { return nil }
+
+// NilScopedCommand uses a NilScopedService
+type NilScopedCommand struct {
+	Params  string
+	Service *NilScopedService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *NilScopedCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return "executed", nil
+	})
+}
+
+func (c *NilScopedCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "NilScopedCommand", Params: c.Params, Metadata: c.Meta}
+}
+
+func (c *NilScopedCommand) Metadata() commandment.OperationMetadata     { return c.Meta }
+func (c *NilScopedCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *NilScopedCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func TestScopedServiceReturningNilFailsCreation(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &NilScopedService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	_, err := commandment.CreateOperationWithDependencies[*NilScopedCommand](bus, "x", "deps")
+	if !errors.Is(err, commandment.ErrDependencyTypeMismatch) {
+		t.Errorf("Expected ErrDependencyTypeMismatch, got %v", err)
+	}
+}
+
+// Ledger posts entries
+type Ledger interface {
+	Post(entry string) string
+}
+
+// AccountLedger posts within the transaction set on its Tx field
+type AccountLedger struct {
+	Name string
+	Tx   *journalTx
+}
+
+func (s *AccountLedger) Post(entry string) string {
+	if s.Tx == nil {
+		return "no transaction"
+	}
+	s.Tx.entries = append(s.Tx.entries, s.Name+":"+entry)
+	return "posted"
+}
+
+// LockedAccountLedger guards its Tx field with a mutex, so it can't be copied
+type LockedAccountLedger struct {
+	mu sync.Mutex
+	Tx *journalTx
+}
+
+func (s *LockedAccountLedger) Post(entry string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return "posted"
+}
+
+// PostLedgerCommand posts an entry to a ledger
+type PostLedgerCommand struct {
+	Params  string
+	Service Ledger
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *PostLedgerCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return c.Service.Post(c.Params), nil
+	})
+}
+
+func (c *PostLedgerCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "PostLedgerCommand", Params: c.Params, Metadata: c.Meta}
+}
+
+func (c *PostLedgerCommand) Metadata() commandment.OperationMetadata     { return c.Meta }
+func (c *PostLedgerCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *PostLedgerCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func newAccountLedgerBus(service Ledger, opts ...commandment.Option) *commandment.OperationBus {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	return commandment.NewOperationBus(registry, &TestLogger{}, opts...)
+}
+
+func TestDependencyFieldsCarryPerOperationTransaction(t *testing.T) {
+	shared := &AccountLedger{Name: "main"}
+	bus := newAccountLedgerBus(shared, commandment.WithDependencyFields())
+	first, second := &journalTx{}, &journalTx{}
+
+	for _, step := range []struct {
+		tx    *journalTx
+		entry string
+	}{{first, "debit"}, {second, "credit"}} {
+		cmd, err := commandment.CreateOperationWithDependencies[*PostLedgerCommand](bus, step.entry, step.tx)
+		if err != nil {
+			t.Fatalf("Failed to create command: %v", err)
+		}
+		if result, err := cmd.Execute(context.Background()); err != nil || result != "posted" {
+			t.Fatalf("Expected entry posted, got %q, %v", result, err)
+		}
+	}
+
+	if len(first.entries) != 1 || first.entries[0] != "main:debit" {
+		t.Errorf("Expected first transaction to hold only the debit, got %v", first.entries)
+	}
+	if len(second.entries) != 1 || second.entries[0] != "main:credit" {
+		t.Errorf("Expected second transaction to hold only the credit, got %v", second.entries)
+	}
+	if shared.Tx != nil {
+		t.Errorf("Expected registered service to be left without a transaction, got %v", shared.Tx)
+	}
+}
+
+func TestDependencyFieldsRequireOption(t *testing.T) {
+	bus := newAccountLedgerBus(&AccountLedger{})
+
+	cmd, err := commandment.CreateOperationWithDependencies[*PostLedgerCommand](bus, "debit", &journalTx{})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if result, _ := cmd.Execute(context.Background()); result != "no transaction" {
+		t.Errorf("Expected service fields untouched without WithDependencyFields, got %q", result)
+	}
+}
+
+func TestDependencyFieldsRejectServiceHoldingLock(t *testing.T) {
+	bus := newAccountLedgerBus(&LockedAccountLedger{}, commandment.WithDependencyFields())
+
+	_, err := commandment.CreateOperationWithDependencies[*PostLedgerCommand](bus, "debit", &journalTx{})
+	if !errors.Is(err, commandment.ErrDependencyTypeMismatch) {
+		t.Errorf("Expected ErrDependencyTypeMismatch for a service holding a lock, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package commandment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// DescriptorFactoryFunc recreates an executable operation from the serialized
+// params and metadata of an OperationDescriptor.
+type DescriptorFactoryFunc func(params json.RawMessage, meta OperationMetadata) (any, error)
+
+// ErrUnknownDescriptorType is returned for descriptors whose type has no factory
+// registered on the bus.
+var ErrUnknownDescriptorType = errors.New("unknown descriptor type")
+
+// ErrDescriptorTooNew is returned for descriptors with a version newer than the
+// bus's factory for their type supports.
+var ErrDescriptorTooNew = errors.New("descriptor version too new")
+
+// descriptorFactory is a registered factory and the newest descriptor version it reads.
+// Factories generated by RegisterOperationType are bound to the bus they create on
+// when called, so child buses create operations with their own configuration.
+type descriptorFactory struct {
+	create  DescriptorFactoryFunc
+	bound   func(bus *OperationBus, params json.RawMessage, meta OperationMetadata) (any, error)
+	version int
+}
+
+// descriptorRegistry maps descriptor type names to their factories.
+type descriptorRegistry struct {
+	mu        sync.RWMutex
+	factories map[string]descriptorFactory
+}
+
+func newDescriptorRegistry() *descriptorRegistry {
+	return &descriptorRegistry{
+		factories: make(map[string]descriptorFactory),
+	}
+}
+
+// clone returns an independent copy of the registry.
+func (r *descriptorRegistry) clone() *descriptorRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &descriptorRegistry{factories: maps.Clone(r.factories)}
+}
+
+// lookupTypeName returns the entry registered for typeName. Short and qualified
+// names of the same type match each other, so descriptors persisted under either
+// name still resolve: a qualified name falls back to the entry under its short
+// name, and a short name matches the single qualified entry sharing it. It reports
+// how many entries matched; more than one means the name is ambiguous, such as a
+// qualified name whose short name is also registered under another package's type.
+func lookupTypeName[V any](entries map[string]V, typeName string) (V, int) {
+	if entry, ok := entries[typeName]; ok {
+		return entry, 1
+	}
+	short := shortTypeName(typeName)
+	var match V
+	matches := 0
+	if short != typeName {
+		entry, ok := entries[short]
+		if !ok {
+			return match, 0
+		}
+		match, matches = entry, 1
+	}
+	for name, entry := range entries {
+		if name != short && shortTypeName(name) == short {
+			match = entry
+			matches++
+		}
+	}
+	return match, matches
+}
+
+// RegisterDescriptorFactory registers the factory used by CreateFromDescriptor to
+// reconstruct operations whose descriptor has the given type name.
+func (b *OperationBus) RegisterDescriptorFactory(typeName string, factory DescriptorFactoryFunc) {
+	b.RegisterVersionedDescriptorFactory(typeName, 0, factory)
+}
+
+// RegisterVersionedDescriptorFactory registers a factory, as RegisterDescriptorFactory
+// does, that reads descriptors of the given type up to and including version.
+func (b *OperationBus) RegisterVersionedDescriptorFactory(typeName string, version int, factory DescriptorFactoryFunc) {
+	b.descriptors.mu.Lock()
+	defer b.descriptors.mu.Unlock()
+	b.descriptors.factories[typeName] = descriptorFactory{create: factory, version: version}
+}
+
+// registerBoundDescriptorFactory registers factory, called with the bus recreating
+// the operation, for typeName unless a factory is already registered, so generated
+// factories don't replace hand-written ones.
+func (b *OperationBus) registerBoundDescriptorFactory(typeName string, factory func(*OperationBus, json.RawMessage, OperationMetadata) (any, error)) {
+	b.descriptors.mu.Lock()
+	defer b.descriptors.mu.Unlock()
+	if _, ok := b.descriptors.factories[typeName]; !ok {
+		b.descriptors.factories[typeName] = descriptorFactory{bound: factory}
+	}
+}
+
+// CompatibleWith reports whether bus can reconstruct the descriptor, returning
+// ErrUnknownDescriptorType if its type has no registered factory, or
+// ErrDescriptorTooNew if its version is newer than the factory supports.
+func (od OperationDescriptor) CompatibleWith(bus *OperationBus) error {
+	_, err := bus.descriptorFactory(od)
+	return err
+}
+
+// descriptorFactory returns the factory able to reconstruct descriptor.
+func (b *OperationBus) descriptorFactory(descriptor OperationDescriptor) (DescriptorFactoryFunc, error) {
+	b.descriptors.mu.RLock()
+	factory, matches := lookupTypeName(b.descriptors.factories, descriptor.Type)
+	b.descriptors.mu.RUnlock()
+	switch {
+	case matches == 0:
+		return nil, fmt.Errorf("%w: no descriptor factory registered for operation type %q",
+			ErrUnknownDescriptorType, descriptor.Type)
+	case matches > 1:
+		return nil, fmt.Errorf("%w: operation type %q matches descriptor factories of several types",
+			ErrUnknownDescriptorType, descriptor.Type)
+	}
+	if descriptor.Version > factory.version {
+		return nil, fmt.Errorf("%w: operation type %q descriptor is version %d, bus supports up to %d",
+			ErrDescriptorTooNew, descriptor.Type, descriptor.Version, factory.version)
+	}
+	if factory.bound != nil {
+		return func(params json.RawMessage, meta OperationMetadata) (any, error) {
+			return factory.bound(b, params, meta)
+		}, nil
+	}
+	return factory.create, nil
+}
+
+// CreateFromDescriptor recreates an executable operation from a serialized descriptor
+// using the factory registered for its type, after checking CompatibleWith.
+func (b *OperationBus) CreateFromDescriptor(descriptor OperationDescriptor) (any, error) {
+	factory, err := b.descriptorFactory(descriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := rawParams(descriptor.Params)
+	if err != nil {
+		return nil, fmt.Errorf("encoding params for operation type %q: %w", descriptor.Type, err)
+	}
+	return factory(params, descriptor.Metadata)
+}
+
+// RestoreMetadata sets the metadata of op, created by a bus, to meta recorded in a
+// descriptor, so a recreated operation keeps the UUID, timestamps, idempotency key
+// and correlation IDs of the one it was serialized from, and stays associated with
+// the bus and Dependencies that created it. It returns op, updated in place for
+// pointer operations; meta without a UUID is ignored. Descriptor factories call it
+// on the operations they create.
+func RestoreMetadata[TOp any](op TOp, meta OperationMetadata) TOp {
+	restoreMetadata(reflect.ValueOf(&op).Elem(), meta)
+	return op
+}
+
+// restoreMetadataOf is RestoreMetadata for an operation whose type is only known at
+// run time, such as one returned by CreateFromDescriptor.
+func restoreMetadataOf(op any, meta OperationMetadata) any {
+	if op == nil {
+		return op
+	}
+	value := reflect.New(reflect.TypeOf(op)).Elem()
+	value.Set(reflect.ValueOf(op))
+	restoreMetadata(value, meta)
+	return value.Interface()
+}
+
+// restoreMetadata sets meta on the operation held by the settable value, keeping
+// the operation's link to its bus.
+func restoreMetadata(value reflect.Value, meta OperationMetadata) {
+	layout := layoutOf(value.Type())
+	if meta.UUID == "" || layout.err != nil {
+		return
+	}
+	if layout.pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	field := value.FieldByIndex(layout.meta)
+	meta.state = field.Interface().(OperationMetadata).state
+	field.Set(reflect.ValueOf(meta))
+}
+
+// descriptorTypes returns the sorted type names with a registered descriptor factory.
+func (b *OperationBus) descriptorTypes() []string {
+	b.descriptors.mu.RLock()
+	defer b.descriptors.mu.RUnlock()
+	types := make([]string, 0, len(b.descriptors.factories))
+	for typeName := range b.descriptors.factories {
+		types = append(types, typeName)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// rawParams returns descriptor params as JSON, whether they are still a typed value
+// or have already been decoded into generic JSON values.
+func rawParams(params any) (json.RawMessage, error) {
+	if raw, ok := params.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(params)
+}
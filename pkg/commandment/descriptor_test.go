@@ -0,0 +1,255 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+	"github.com/davidlee/commandment/pkg/commandment/internal/samename"
+)
+
+func newVersionedDescriptorBus() *commandment.OperationBus {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.RegisterVersionedDescriptorFactory("TestOperation", 2, func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var input string
+		if err := json.Unmarshal(params, &input); err != nil {
+			return nil, err
+		}
+		return commandment.CreateOperation[*TestOperation](bus, input)
+	})
+	return bus
+}
+
+func TestDescriptorCompatibleWithKnownVersion(t *testing.T) {
+	bus := newVersionedDescriptorBus()
+
+	for _, version := range []int{0, 2} {
+		descriptor := commandment.OperationDescriptor{Type: "TestOperation", Version: version, Params: "x"}
+		if err := descriptor.CompatibleWith(bus); err != nil {
+			t.Errorf("Expected version %d to be compatible, got %v", version, err)
+		}
+	}
+}
+
+func TestDescriptorCompatibleWithUnknownType(t *testing.T) {
+	bus := newVersionedDescriptorBus()
+	descriptor := commandment.OperationDescriptor{Type: "RetiredOperation", Params: "x"}
+
+	if err := descriptor.CompatibleWith(bus); !errors.Is(err, commandment.ErrUnknownDescriptorType) {
+		t.Errorf("Expected ErrUnknownDescriptorType, got %v", err)
+	}
+}
+
+func TestDescriptorCompatibleWithFutureVersion(t *testing.T) {
+	bus := newVersionedDescriptorBus()
+	descriptor := commandment.OperationDescriptor{Type: "TestOperation", Version: 3, Params: "x"}
+
+	if err := descriptor.CompatibleWith(bus); !errors.Is(err, commandment.ErrDescriptorTooNew) {
+		t.Errorf("Expected ErrDescriptorTooNew, got %v", err)
+	}
+	if _, err := bus.CreateFromDescriptor(descriptor); !errors.Is(err, commandment.ErrDescriptorTooNew) {
+		t.Errorf("Expected reconstruction to be refused with ErrDescriptorTooNew, got %v", err)
+	}
+}
+
+func TestCustomDescriptorRoundTrip(t *testing.T) {
+	bus, service := newRecordBus()
+	bus.RegisterDescriptorFactory("WriteRecordCommand", func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var p WriteRecordParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return commandment.CreateOperation[*WriteRecordCommand](bus, p)
+	})
+
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	data, err := json.Marshal(cmd.Descriptor())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	var descriptor commandment.OperationDescriptor
+	if err := json.Unmarshal(data, &descriptor); err != nil {
+		t.Fatalf("Failed to unmarshal descriptor: %v", err)
+	}
+
+	recreated, err := bus.CreateFromDescriptor(descriptor)
+	if err != nil {
+		t.Fatalf("Failed to recreate command: %v", err)
+	}
+	recreatedCmd, ok := recreated.(*WriteRecordCommand)
+	if !ok {
+		t.Fatalf("Expected *WriteRecordCommand, got %T", recreated)
+	}
+	if recreatedCmd.Params != cmd.Params {
+		t.Errorf("Expected params %+v, got %+v", cmd.Params, recreatedCmd.Params)
+	}
+	if _, err := recreatedCmd.Execute(context.Background()); err != nil {
+		t.Fatalf("Recreated command execution failed: %v", err)
+	}
+	if service.records["k"] != "v2" {
+		t.Errorf("Expected recreated command to write the record, got %q", service.records["k"])
+	}
+}
+
+func TestQualifiedTypeNamesDistinguishPackages(t *testing.T) {
+	ours := commandment.QualifiedTypeName(&TestOperation{})
+	theirs := commandment.QualifiedTypeName(samename.TestOperation{})
+
+	if ours != "github.com/davidlee/commandment/pkg/commandment_test.TestOperation" {
+		t.Errorf("Unexpected qualified name %q", ours)
+	}
+	if ours == theirs {
+		t.Errorf("Expected identically named types in different packages to differ, both %q", ours)
+	}
+	if value := commandment.QualifiedTypeName(TestOperation{}); value != ours {
+		t.Errorf("Expected pointer and value to share a name, got %q and %q", ours, value)
+	}
+}
+
+func TestQualifiedDescriptorFactoriesDontCollide(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+	ours := commandment.QualifiedTypeName(&TestOperation{})
+	theirs := commandment.QualifiedTypeName(&samename.TestOperation{})
+	factory := func(result string) commandment.DescriptorFactoryFunc {
+		return func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+			return result, nil
+		}
+	}
+	bus.RegisterDescriptorFactory(ours, factory("ours"))
+	bus.RegisterDescriptorFactory(theirs, factory("theirs"))
+
+	for typeName, want := range map[string]string{ours: "ours", theirs: "theirs"} {
+		got, err := bus.CreateFromDescriptor(commandment.OperationDescriptor{Type: typeName})
+		if err != nil || got != want {
+			t.Errorf("Expected %s to create %q, got %v, %v", typeName, want, got, err)
+		}
+	}
+	if _, err := bus.CreateFromDescriptor(commandment.OperationDescriptor{Type: "TestOperation"}); !errors.Is(err, commandment.ErrUnknownDescriptorType) {
+		t.Errorf("Expected ambiguous short name to be unknown, got %v", err)
+	}
+}
+
+func TestQualifiedDescriptorLookupRejectsAmbiguousShortName(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+	created := func(result string) commandment.DescriptorFactoryFunc {
+		return func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+			return result, nil
+		}
+	}
+	bus.RegisterDescriptorFactory("TestOperation", created("short"))
+	bus.RegisterDescriptorFactory(commandment.QualifiedTypeName(&samename.TestOperation{}), created("theirs"))
+
+	ours := commandment.QualifiedTypeName(&TestOperation{})
+	if got, err := bus.CreateFromDescriptor(commandment.OperationDescriptor{Type: ours}); !errors.Is(err, commandment.ErrUnknownDescriptorType) {
+		t.Errorf("Expected %s to be ambiguous, got %v, %v", ours, got, err)
+	}
+}
+
+func TestRegisteredOperationTypesUseQualifiedNames(t *testing.T) {
+	bus, _ := newRecordBus()
+	commandment.RegisterOperationType[*WriteRecordCommand](bus)
+	want := commandment.QualifiedTypeName(&WriteRecordCommand{})
+
+	if got := bus.Catalog()[0].Type; got != want {
+		t.Errorf("Expected catalog type %q, got %q", want, got)
+	}
+	for _, typeName := range []string{want, "WriteRecordCommand"} {
+		if _, err := bus.DecodeResult(typeName, []byte(`"v"`)); err != nil {
+			t.Errorf("Expected result type for %s, got %v", typeName, err)
+		}
+	}
+}
+
+func TestDescriptorLookupMatchesShortAndQualifiedNames(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+	created := func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		return "created", nil
+	}
+	bus.RegisterDescriptorFactory(commandment.QualifiedTypeName(&TestOperation{}), created)
+	bus.RegisterDescriptorFactory("ReadRecordQuery", created)
+
+	for _, typeName := range []string{"TestOperation", commandment.QualifiedTypeName(&ReadRecordQuery{})} {
+		if _, err := bus.CreateFromDescriptor(commandment.OperationDescriptor{Type: typeName}); err != nil {
+			t.Errorf("Expected %s to resolve, got %v", typeName, err)
+		}
+	}
+}
+
+func TestRegisteredOperationTypeRoundTripsThroughDescriptorFactory(t *testing.T) {
+	bus, service := newRecordBus()
+	commandment.RegisterOperationType[*WriteRecordCommand](bus)
+	var factory commandment.DescriptorFactory = bus
+
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	data, err := json.Marshal(cmd.Descriptor())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	var descriptor commandment.OperationDescriptor
+	if err := json.Unmarshal(data, &descriptor); err != nil {
+		t.Fatalf("Failed to unmarshal descriptor: %v", err)
+	}
+
+	recreated, err := factory.CreateFromDescriptor(descriptor)
+	if err != nil {
+		t.Fatalf("Failed to recreate command: %v", err)
+	}
+	recreatedCmd, ok := recreated.(*WriteRecordCommand)
+	if !ok || recreatedCmd.Params != cmd.Params {
+		t.Fatalf("Expected *WriteRecordCommand with params %+v, got %#v", cmd.Params, recreated)
+	}
+	if meta := recreatedCmd.Metadata(); meta.UUID != cmd.Meta.UUID || !meta.Created.Equal(cmd.Meta.Created) {
+		t.Errorf("Expected recreated command to keep its metadata %+v, got %+v", cmd.Meta, meta)
+	}
+	result, err := bus.ExecuteAny(context.Background(), recreated)
+	if err != nil || result != "v2" || service.records["k"] != "v2" {
+		t.Errorf("Expected recreated command to write the record, got %v, %v", result, err)
+	}
+}
+
+func TestRegisterOperationTypeKeepsExistingDescriptorFactory(t *testing.T) {
+	bus, _ := newRecordBus()
+	bus.RegisterDescriptorFactory("WriteRecordCommand", func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		return "custom", nil
+	})
+	commandment.RegisterOperationType[*WriteRecordCommand](bus)
+
+	got, err := bus.CreateFromDescriptor(commandment.OperationDescriptor{Type: "WriteRecordCommand"})
+	if err != nil || got != "custom" {
+		t.Errorf("Expected the hand-written factory to be kept, got %v, %v", got, err)
+	}
+}
+
+func TestGeneratedDescriptorFactoryCreatesOnRecreatingBus(t *testing.T) {
+	parent, _ := newRecordBus()
+	commandment.RegisterOperationType[*WriteRecordCommand](parent)
+	var childRan bool
+	child := parent.With(commandment.WithMiddleware(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			childRan = true
+			return next(ctx)
+		}
+	}))
+
+	descriptor := commandment.OperationDescriptor{Type: "WriteRecordCommand", Params: WriteRecordParams{Key: "k", Value: "v2"}}
+	recreated, err := child.CreateFromDescriptor(descriptor)
+	if err != nil {
+		t.Fatalf("Failed to recreate command: %v", err)
+	}
+	if _, err := child.ExecuteAny(context.Background(), recreated); err != nil {
+		t.Fatalf("Recreated command failed: %v", err)
+	}
+	if !childRan {
+		t.Error("Expected the command recreated by the child bus to run the child's middleware")
+	}
+}
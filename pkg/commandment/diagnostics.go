@@ -0,0 +1,128 @@
+package commandment
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recentErrorWindow is the period over which Diagnostics counts failed executions.
+const recentErrorWindow = 5 * time.Minute
+
+// Diagnostics is a point-in-time snapshot of bus state, suitable for serving
+// from a debug endpoint such as /debug/commandment.
+type Diagnostics struct {
+	Services        []string `json:"services"`
+	DescriptorTypes []string `json:"descriptor_types"`
+	InFlight        int      `json:"in_flight"`
+	RecentErrors    int      `json:"recent_errors"`
+}
+
+// Diagnostics reports the registered services and descriptor types, the number of
+// operations currently executing, and the number of failed executions in the last
+// five minutes.
+func (b *OperationBus) Diagnostics() Diagnostics {
+	return Diagnostics{
+		Services:        b.registry.serviceTypeNames(),
+		DescriptorTypes: b.descriptorTypes(),
+		InFlight:        int(b.executions.inFlight.Load()),
+		RecentErrors:    b.executions.recentErrors(time.Now()),
+	}
+}
+
+// InFlightInfo describes an operation that is currently executing.
+type InFlightInfo struct {
+	UUID string        `json:"uuid"`
+	Type string        `json:"type"`
+	Age  time.Duration `json:"age"`
+}
+
+// InFlight lists the operations currently executing, oldest first, with how long
+// each has been running. It helps locate stuck operations.
+func (b *OperationBus) InFlight() []InFlightInfo {
+	now := time.Now()
+	b.executions.mu.Lock()
+	infos := make([]InFlightInfo, 0, len(b.executions.running))
+	for exec := range b.executions.running {
+		infos = append(infos, InFlightInfo{
+			UUID: exec.metadata.UUID,
+			Type: exec.typeName,
+			Age:  now.Sub(exec.started),
+		})
+	}
+	b.executions.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Age > infos[j].Age })
+	return infos
+}
+
+// executionStarted records the start of an execution; it is a no-op on a nil bus.
+func (b *OperationBus) executionStarted(exec *execution) {
+	if b != nil {
+		b.executions.started(exec)
+	}
+}
+
+// executionFinished records the outcome of an execution; it is a no-op on a nil bus.
+func (b *OperationBus) executionFinished(exec *execution, err error) {
+	if b != nil {
+		b.executions.finished(exec, err)
+		b.metrics.observeLatency(exec.typeName, time.Since(exec.started))
+	}
+}
+
+// executionTracker tracks in-flight executions and counts recent failures,
+// bucketing failures by minute over recentErrorWindow.
+type executionTracker struct {
+	inFlight atomic.Int64
+
+	mu      sync.Mutex
+	running map[*execution]struct{}
+	buckets [int(recentErrorWindow / time.Minute)]errorBucket
+}
+
+type errorBucket struct {
+	minute int64
+	count  int
+}
+
+func (t *executionTracker) started(exec *execution) {
+	t.inFlight.Add(1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.running == nil {
+		t.running = make(map[*execution]struct{})
+	}
+	t.running[exec] = struct{}{}
+}
+
+func (t *executionTracker) finished(exec *execution, err error) {
+	t.inFlight.Add(-1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.running, exec)
+	if err == nil {
+		return
+	}
+
+	minute := time.Now().Unix() / 60
+	bucket := &t.buckets[minute%int64(len(t.buckets))]
+	if bucket.minute != minute {
+		*bucket = errorBucket{minute: minute}
+	}
+	bucket.count++
+}
+
+func (t *executionTracker) recentErrors(now time.Time) int {
+	oldest := now.Unix()/60 - int64(len(t.buckets)) + 1
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	total := 0
+	for _, bucket := range t.buckets {
+		if bucket.minute >= oldest {
+			total += bucket.count
+		}
+	}
+	return total
+}
@@ -0,0 +1,147 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service that fails or blocks depending on its input
+type ControllableService struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *ControllableService) DoSomething(ctx context.Context, input string) (string, error) {
+	switch input {
+	case "fail":
+		return "", errors.New("service failed")
+	case "block":
+		s.started <- struct{}{}
+		<-s.release
+	}
+	return "result: " + input, nil
+}
+
+func TestDiagnosticsReflectConfiguredState(t *testing.T) {
+	service := &ControllableService{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	commandment.RegisterService(registry, DependencyAwareService{})
+
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	noopFactory := func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		return nil, nil
+	}
+	bus.RegisterDescriptorFactory("TestOperation", noopFactory)
+	bus.RegisterDescriptorFactory("DependencyAwareOperation", noopFactory)
+
+	// Two failed executions and one success
+	for _, input := range []string{"fail", "fail", "ok"} {
+		op, err := commandment.CreateOperation[*TestOperation](bus, input)
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		_, _ = op.Execute(context.Background())
+	}
+
+	// One execution left in flight
+	blocked, err := commandment.CreateOperation[*TestOperation](bus, "block")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = blocked.Execute(context.Background())
+	}()
+	<-service.started
+
+	diagnostics := bus.Diagnostics()
+
+	close(service.release)
+	<-done
+
+	expectedServices := []string{"commandment_test.DependencyAwareService", "commandment_test.TestService"}
+	if !reflect.DeepEqual(diagnostics.Services, expectedServices) {
+		t.Errorf("Expected services %v, got %v", expectedServices, diagnostics.Services)
+	}
+
+	expectedTypes := []string{"DependencyAwareOperation", "TestOperation"}
+	if !reflect.DeepEqual(diagnostics.DescriptorTypes, expectedTypes) {
+		t.Errorf("Expected descriptor types %v, got %v", expectedTypes, diagnostics.DescriptorTypes)
+	}
+
+	if diagnostics.InFlight != 1 {
+		t.Errorf("Expected 1 in-flight operation, got %d", diagnostics.InFlight)
+	}
+	if diagnostics.RecentErrors != 2 {
+		t.Errorf("Expected 2 recent errors, got %d", diagnostics.RecentErrors)
+	}
+
+	if after := bus.Diagnostics(); after.InFlight != 0 {
+		t.Errorf("Expected no in-flight operations after completion, got %d", after.InFlight)
+	}
+}
+
+func TestDiagnosticsEmptyBus(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+
+	diagnostics := bus.Diagnostics()
+	if len(diagnostics.Services) != 0 || len(diagnostics.DescriptorTypes) != 0 {
+		t.Errorf("Expected no services or descriptor types, got %+v", diagnostics)
+	}
+	if diagnostics.InFlight != 0 || diagnostics.RecentErrors != 0 {
+		t.Errorf("Expected zero counts, got %+v", diagnostics)
+	}
+}
+
+func TestInFlightListsSlowOperationWithAge(t *testing.T) {
+	service := &ControllableService{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "block")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = op.Execute(context.Background())
+	}()
+	<-service.started
+	time.Sleep(5 * time.Millisecond)
+
+	inFlight := bus.InFlight()
+
+	close(service.release)
+	<-done
+
+	if len(inFlight) != 1 {
+		t.Fatalf("Expected 1 in-flight operation, got %+v", inFlight)
+	}
+	info := inFlight[0]
+	if info.UUID != op.Metadata().UUID || info.Type != "TestOperation" {
+		t.Errorf("Unexpected in-flight entry: %+v", info)
+	}
+	if info.Age <= 0 {
+		t.Errorf("Expected a non-zero age, got %v", info.Age)
+	}
+
+	if after := bus.InFlight(); len(after) != 0 {
+		t.Errorf("Expected no in-flight operations after completion, got %+v", after)
+	}
+}
@@ -0,0 +1,49 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// dryRunKey is the context key marking dry-run executions
+const dryRunKey contextKey = "commandment:dry-run"
+
+// ErrDryRunUnsupported is returned, without executing, for commands executed in
+// dry-run mode that don't implement DryRunnable.
+var ErrDryRunUnsupported = errors.New("dry run unsupported")
+
+// DryRunnable is implemented by commands able to preview their result without side
+// effects. DryRun is called in place of the business logic, inside the usual
+// middleware chain, so it must not call ExecuteOperation itself.
+type DryRunnable[TResult any] interface {
+	DryRun(ctx context.Context) (TResult, error)
+}
+
+// WithDryRun marks executions with ctx as dry runs: commands run their DryRun method
+// instead of their business logic, and fail with ErrDryRunUnsupported if they have
+// none. Queries, being free of side effects, execute normally. Dry-run results are
+// neither replayed from nor recorded in the idempotency store, and their log lines
+// carry dry_run=true.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey, true)
+}
+
+// IsDryRun reports whether ctx was marked with WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey).(bool)
+	return dryRun
+}
+
+// dryRunLogic returns the logic to execute op with: businessLogic, or op's DryRun
+// for a command executed in dry-run mode.
+func dryRunLogic[T any](ctx context.Context, op any, businessLogic func(context.Context) (T, error)) (func(context.Context) (T, error), error) {
+	if !IsDryRun(ctx) || isQuery(op) {
+		return businessLogic, nil
+	}
+	dryRunnable, ok := op.(DryRunnable[T])
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDryRunUnsupported, operationTypeName(op))
+	}
+	return dryRunnable.DryRun, nil
+}
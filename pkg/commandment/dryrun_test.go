@@ -0,0 +1,95 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Test command writing a record that can preview the write
+type PreviewWriteCommand struct {
+	Params  WriteRecordParams
+	Service *RecordService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *PreviewWriteCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return c.Service.Write(ctx, c.Params)
+	})
+}
+
+func (c *PreviewWriteCommand) DryRun(ctx context.Context) (string, error) {
+	return "would write " + c.Params.Value, nil
+}
+
+func (c *PreviewWriteCommand) Metadata() commandment.OperationMetadata {
+	return c.Meta
+}
+
+func (c *PreviewWriteCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "PreviewWriteCommand", Params: c.Params, Metadata: c.Meta}
+}
+
+func (c *PreviewWriteCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *PreviewWriteCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func newDryRunBus() (*commandment.OperationBus, *RecordService, *RecordingLogger) {
+	service := &RecordService{records: map[string]string{"k": "v1"}}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	logger := &RecordingLogger{}
+	return commandment.NewOperationBus(registry, logger), service, logger
+}
+
+func TestDryRunRoutesToDryRun(t *testing.T) {
+	bus, service, logger := newDryRunBus()
+	cmd, err := commandment.CreateOperation[*PreviewWriteCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+
+	result, err := cmd.Execute(commandment.WithDryRun(context.Background()))
+	if err != nil || result != "would write v2" {
+		t.Fatalf("Expected dry-run preview, got %q, %v", result, err)
+	}
+	if service.records["k"] != "v1" {
+		t.Errorf("Expected dry run to leave the record untouched, got %q", service.records["k"])
+	}
+	entry, ok := logger.Find("Operation execution completed")
+	if !ok {
+		t.Fatal("Expected completion log entry")
+	}
+	if dryRun, _ := entry.Field("dry_run"); dryRun != true {
+		t.Errorf("Expected dry_run=true in the log, got %v", dryRun)
+	}
+}
+
+func TestDryRunUnsupportedCommandRefused(t *testing.T) {
+	bus, service, _ := newDryRunBus()
+	cmd, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+
+	if _, err := cmd.Execute(commandment.WithDryRun(context.Background())); !errors.Is(err, commandment.ErrDryRunUnsupported) {
+		t.Errorf("Expected ErrDryRunUnsupported, got %v", err)
+	}
+	if service.records["k"] != "v1" {
+		t.Errorf("Expected refused dry run not to write, got %q", service.records["k"])
+	}
+}
+
+func TestDryRunExecutesQueriesNormally(t *testing.T) {
+	bus, service, _ := newDryRunBus()
+
+	if result := readRecord(commandment.WithDryRun(context.Background()), t, bus, "k"); result != "v1" {
+		t.Errorf("Expected query result in dry run, got %q", result)
+	}
+	if service.reads != 1 {
+		t.Errorf("Expected query to reach its service, got %d reads", service.reads)
+	}
+}
@@ -0,0 +1,80 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// newEmptyParamsBus returns a bus recreating ParamsOperation[struct{}] from descriptors.
+func newEmptyParamsBus() *commandment.OperationBus {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.RegisterDescriptorFactory("ParamsOperation", func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var p struct{}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return commandment.CreateOperation[*ParamsOperation[struct{}]](bus, p)
+	})
+	return bus
+}
+
+func TestEmptyParamsOperationRoundTrip(t *testing.T) {
+	bus := newEmptyParamsBus()
+
+	for _, params := range []any{struct{}{}, nil} {
+		op, err := commandment.CreateOperation[*ParamsOperation[struct{}]](bus, params)
+		if err != nil {
+			t.Fatalf("Failed to create operation with params %v: %v", params, err)
+		}
+		if _, err := op.Execute(context.Background()); err != nil {
+			t.Fatalf("Operation execution failed: %v", err)
+		}
+
+		data, err := json.Marshal(op.Descriptor())
+		if err != nil {
+			t.Fatalf("Failed to marshal descriptor: %v", err)
+		}
+		var encoded struct {
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			t.Fatalf("Failed to decode descriptor: %v", err)
+		}
+		if string(encoded.Params) != "{}" {
+			t.Errorf("Expected empty params to serialize as {}, got %s", encoded.Params)
+		}
+
+		var descriptor commandment.OperationDescriptor
+		if err := json.Unmarshal(data, &descriptor); err != nil {
+			t.Fatalf("Failed to unmarshal descriptor: %v", err)
+		}
+		recreated, err := bus.CreateFromDescriptor(descriptor)
+		if err != nil {
+			t.Fatalf("Failed to recreate operation: %v", err)
+		}
+		if _, err := bus.ExecuteAny(context.Background(), recreated); err != nil {
+			t.Errorf("Recreated operation execution failed: %v", err)
+		}
+	}
+}
+
+func TestEmptyParamsDescriptorWithoutParamsRecreated(t *testing.T) {
+	bus := newEmptyParamsBus()
+
+	var descriptor commandment.OperationDescriptor
+	if err := json.Unmarshal([]byte(`{"type":"ParamsOperation"}`), &descriptor); err != nil {
+		t.Fatalf("Failed to unmarshal descriptor: %v", err)
+	}
+	recreated, err := bus.CreateFromDescriptor(descriptor)
+	if err != nil {
+		t.Fatalf("Expected a descriptor without params to recreate an empty-params operation: %v", err)
+	}
+	if _, err := bus.ExecuteAny(context.Background(), recreated); err != nil {
+		t.Errorf("Recreated operation execution failed: %v", err)
+	}
+}
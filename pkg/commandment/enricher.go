@@ -0,0 +1,27 @@
+package commandment
+
+import "context"
+
+// ContextEnricher adds values to an operation's execution context based on the
+// operation, for example a database handle chosen by descriptor type.
+type ContextEnricher func(ctx context.Context, descriptor OperationDescriptor) context.Context
+
+// WithContextEnricher registers enrichers that run, in order, before middleware and
+// business logic of every operation created by the bus. Each enricher receives the
+// context returned by the previous one.
+func WithContextEnricher(enrichers ...ContextEnricher) Option {
+	return func(b *OperationBus) {
+		b.enrichers = append(b.enrichers, enrichers...)
+	}
+}
+
+// applyEnrichers runs the bus context enrichers. It returns ctx unchanged on a nil bus.
+func (b *OperationBus) applyEnrichers(ctx context.Context, descriptor OperationDescriptor) context.Context {
+	if b == nil {
+		return ctx
+	}
+	for _, enrich := range b.enrichers {
+		ctx = enrich(ctx, descriptor)
+	}
+	return ctx
+}
@@ -0,0 +1,69 @@
+package commandment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+type dbHandleKey struct{}
+
+// Service reporting the DB handle found in its context
+type HandleReportingService struct{}
+
+func (s *HandleReportingService) DoSomething(ctx context.Context, input string) (string, error) {
+	handle, _ := ctx.Value(dbHandleKey{}).(string)
+	return input + "@" + handle, nil
+}
+
+func TestContextEnricherValueVisibleToService(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &HandleReportingService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithContextEnricher(func(ctx context.Context, descriptor commandment.OperationDescriptor) context.Context {
+			return context.WithValue(ctx, dbHandleKey{}, "primary:"+descriptor.Type)
+		}),
+	)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if result != "x@primary:TestOperation" {
+		t.Errorf("Expected enriched handle in service context, got %q", result)
+	}
+}
+
+func TestContextEnrichersComposeInOrder(t *testing.T) {
+	appendHandle := func(part string) commandment.ContextEnricher {
+		return func(ctx context.Context, descriptor commandment.OperationDescriptor) context.Context {
+			handle, _ := ctx.Value(dbHandleKey{}).(string)
+			return context.WithValue(ctx, dbHandleKey{}, handle+part)
+		}
+	}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &HandleReportingService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithContextEnricher(appendHandle("a"), appendHandle("b")),
+		commandment.WithContextEnricher(appendHandle("c")),
+	)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if result != "x@abc" {
+		t.Errorf("Expected enrichers to run in registration order, got %q", result)
+	}
+}
@@ -0,0 +1,56 @@
+package commandment
+
+// EventSink receives operation lifecycle events from an OperationBus. Callbacks run
+// synchronously on the operation's goroutine, so sinks should return quickly.
+type EventSink interface {
+	// OnCreated is called when the bus creates an operation.
+	OnCreated(descriptor OperationDescriptor)
+	// OnStarted is called when an admitted execution starts.
+	OnStarted(metadata OperationMetadata)
+	// OnCompleted is called when an execution returns, with its error. Executions
+	// rejected before starting report OnCompleted without OnStarted.
+	OnCompleted(metadata OperationMetadata, err error)
+}
+
+// AddEventSink registers sink to receive the lifecycle events of operations created
+// by the bus. Sinks are called in registration order.
+func (b *OperationBus) AddEventSink(sink EventSink) {
+	b.eventSinksMu.Lock()
+	defer b.eventSinksMu.Unlock()
+	b.eventSinks = append(b.eventSinks, sink)
+}
+
+// sinks returns the registered event sinks. It returns nil on a nil bus.
+func (b *OperationBus) sinks() []EventSink {
+	if b == nil {
+		return nil
+	}
+	b.eventSinksMu.RLock()
+	defer b.eventSinksMu.RUnlock()
+	return b.eventSinks
+}
+
+// emitCreated reports a created operation, building its descriptor only when a
+// sink is registered.
+func (b *OperationBus) emitCreated(op Describer) {
+	sinks := b.sinks()
+	if len(sinks) == 0 {
+		return
+	}
+	descriptor := op.Descriptor()
+	for _, sink := range sinks {
+		sink.OnCreated(descriptor)
+	}
+}
+
+func (b *OperationBus) emitStarted(metadata OperationMetadata) {
+	for _, sink := range b.sinks() {
+		sink.OnStarted(metadata)
+	}
+}
+
+func (b *OperationBus) emitCompleted(metadata OperationMetadata, err error) {
+	for _, sink := range b.sinks() {
+		sink.OnCompleted(metadata, err)
+	}
+}
@@ -0,0 +1,119 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// EventSink recording the sequence of lifecycle callbacks
+type RecordingEventSink struct {
+	mu     sync.Mutex
+	events []string
+	ids    []string
+}
+
+func (s *RecordingEventSink) record(event, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	s.ids = append(s.ids, id)
+}
+
+func (s *RecordingEventSink) OnCreated(descriptor commandment.OperationDescriptor) {
+	s.record("created:"+descriptor.Type, descriptor.Metadata.UUID)
+}
+
+func (s *RecordingEventSink) OnStarted(metadata commandment.OperationMetadata) {
+	s.record("started", metadata.UUID)
+}
+
+func (s *RecordingEventSink) OnCompleted(metadata commandment.OperationMetadata, err error) {
+	event := "completed"
+	if err != nil {
+		event = "failed"
+	}
+	s.record(event, metadata.UUID)
+}
+
+func TestEventSinkReceivesLifecycle(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	first, second := &RecordingEventSink{}, &RecordingEventSink{}
+	bus.AddEventSink(first)
+	bus.AddEventSink(second)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	want := []string{"created:TestOperation", "started", "completed"}
+	for _, sink := range []*RecordingEventSink{first, second} {
+		if !reflect.DeepEqual(sink.events, want) {
+			t.Errorf("Expected events %v, got %v", want, sink.events)
+		}
+		for _, id := range sink.ids {
+			if id != op.Meta.UUID {
+				t.Errorf("Expected every event for operation %s, got %s", op.Meta.UUID, id)
+			}
+		}
+	}
+}
+
+func TestEventSinkReceivesFailure(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.Use(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			return nil, errors.New("denied")
+		}
+	})
+	sink := &RecordingEventSink{}
+	bus.AddEventSink(sink)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err == nil {
+		t.Fatal("Expected execution to fail")
+	}
+
+	want := []string{"created:TestOperation", "started", "failed"}
+	if !reflect.DeepEqual(sink.events, want) {
+		t.Errorf("Expected events %v, got %v", want, sink.events)
+	}
+}
+
+func TestCompletedMetadataNamesOperationType(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	sink := &CompletedMetadataSink{}
+	bus.AddEventSink(sink)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if op.Meta.OperationType() != "" {
+		t.Errorf("Expected no operation type before execution, got %q", op.Meta.OperationType())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = op.Execute(ctx)
+
+	if len(sink.metadata) != 1 || sink.metadata[0].OperationType() != "TestOperation" {
+		t.Errorf("Expected the rejected execution's metadata to name TestOperation, got %+v", sink.metadata)
+	}
+}
@@ -0,0 +1,121 @@
+package commandment
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+)
+
+// Hash returns a canonical hash of the descriptor's type and params. Metadata is
+// excluded, so two descriptors for the same operation and params hash equally no
+// matter when they were created, and a descriptor hashes the same before and after
+// a JSON round-trip.
+func (od OperationDescriptor) Hash() (string, error) {
+	params, err := canonicalParams(od.Params)
+	if err != nil {
+		return "", err
+	}
+	data := make([]byte, 0, len(od.Type)+1+len(params))
+	data = append(data, od.Type...)
+	data = append(data, 0)
+	data = append(data, params...)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalJSON encodes v with object keys sorted, independent of whether v is a
+// typed struct or generic decoded JSON.
+func canonicalJSON(v any) ([]byte, error) {
+	data, err := rawParams(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// EventStore records descriptors of executed operations.
+type EventStore interface {
+	Append(descriptor OperationDescriptor) error
+	Events() []OperationDescriptor
+}
+
+// MemoryEventStoreOption configures a MemoryEventStore.
+type MemoryEventStoreOption func(*MemoryEventStore)
+
+// WithHashDedup makes Append ignore a descriptor already stored, so replaying the
+// same command isn't recorded twice. Descriptors are the same when their Hash
+// matches and they belong to the same command: they share an idempotency key or,
+// without one, an operation UUID. Separate commands with equal params, such as two
+// identical deposits, are both kept. Descriptors with neither key nor UUID are
+// deduplicated by Hash alone.
+func WithHashDedup() MemoryEventStoreOption {
+	return func(s *MemoryEventStore) {
+		s.seen = make(map[string]struct{})
+	}
+}
+
+// MemoryEventStore is an in-memory EventStore safe for concurrent use.
+type MemoryEventStore struct {
+	mu     sync.RWMutex
+	events []OperationDescriptor
+	seen   map[string]struct{} // non-nil when deduplicating
+}
+
+// NewMemoryEventStore creates an empty MemoryEventStore.
+func NewMemoryEventStore(opts ...MemoryEventStoreOption) *MemoryEventStore {
+	store := &MemoryEventStore{}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store
+}
+
+// Append implements EventStore.
+func (s *MemoryEventStore) Append(descriptor OperationDescriptor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen != nil {
+		key, err := dedupKey(descriptor)
+		if err != nil {
+			return err
+		}
+		if _, duplicate := s.seen[key]; duplicate {
+			return nil
+		}
+		s.seen[key] = struct{}{}
+	}
+
+	s.events = append(s.events, descriptor)
+	return nil
+}
+
+// dedupKey identifies descriptor's command for WithHashDedup: its Hash qualified by
+// its idempotency key, or its UUID when it has none.
+func dedupKey(descriptor OperationDescriptor) (string, error) {
+	hash, err := descriptor.Hash()
+	if err != nil {
+		return "", err
+	}
+	if key := descriptor.Metadata.IdempotencyKey; key != "" {
+		return hash + "\x00key:" + key, nil
+	}
+	return hash + "\x00id:" + descriptor.Metadata.UUID, nil
+}
+
+// Events implements EventStore, returning the stored descriptors in append order.
+func (s *MemoryEventStore) Events() []OperationDescriptor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	events := make([]OperationDescriptor, len(s.events))
+	copy(events, s.events)
+	return events
+}
@@ -0,0 +1,150 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func createRenameDescriptor(t *testing.T, bus *commandment.OperationBus, name string) commandment.OperationDescriptor {
+	t.Helper()
+	cmd, err := commandment.CreateOperation[*RenameCommand](bus, RenameParams{Name: name})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := cmd.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	return cmd.Descriptor()
+}
+
+func newRenameBus() *commandment.OperationBus {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &RenameService{})
+	return commandment.NewOperationBus(registry, &TestLogger{})
+}
+
+func TestEventStoreDedupsSameCommand(t *testing.T) {
+	bus := newRenameBus()
+	store := commandment.NewMemoryEventStore(commandment.WithHashDedup())
+
+	descriptor := createRenameDescriptor(t, bus, "same")
+	data, err := json.Marshal(descriptor)
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	var replayed commandment.OperationDescriptor
+	if err := json.Unmarshal(data, &replayed); err != nil {
+		t.Fatalf("Failed to unmarshal descriptor: %v", err)
+	}
+
+	for _, d := range []commandment.OperationDescriptor{descriptor, replayed} {
+		if err := store.Append(d); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	events := store.Events()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 stored entry, got %d", len(events))
+	}
+	if events[0].Metadata.UUID != descriptor.Metadata.UUID {
+		t.Error("Expected the first appended descriptor to be kept")
+	}
+}
+
+func TestEventStoreKeepsSeparateCommandsWithEqualParams(t *testing.T) {
+	bus := newRenameBus()
+	store := commandment.NewMemoryEventStore(commandment.WithHashDedup())
+
+	first := createRenameDescriptor(t, bus, "same")
+	second := createRenameDescriptor(t, bus, "same")
+	for _, descriptor := range []commandment.OperationDescriptor{first, second} {
+		if err := store.Append(descriptor); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	if events := store.Events(); len(events) != 2 {
+		t.Errorf("Expected both commands stored, got %d entries", len(events))
+	}
+}
+
+func TestEventStoreDedupsCommandsSharingIdempotencyKey(t *testing.T) {
+	bus := newRenameBus()
+	store := commandment.NewMemoryEventStore(commandment.WithHashDedup())
+
+	for range 2 {
+		descriptor := createRenameDescriptor(t, bus, "same")
+		descriptor.Metadata.IdempotencyKey = "rename-1"
+		if err := store.Append(descriptor); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	if events := store.Events(); len(events) != 1 {
+		t.Errorf("Expected 1 stored entry, got %d", len(events))
+	}
+}
+
+func TestEventStoreKeepsDistinctCommands(t *testing.T) {
+	bus := newRenameBus()
+	store := commandment.NewMemoryEventStore(commandment.WithHashDedup())
+
+	for _, name := range []string{"first", "second"} {
+		if err := store.Append(createRenameDescriptor(t, bus, name)); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	if events := store.Events(); len(events) != 2 {
+		t.Errorf("Expected 2 stored entries, got %d", len(events))
+	}
+}
+
+func TestEventStoreWithoutDedupKeepsDuplicates(t *testing.T) {
+	bus := newRenameBus()
+	store := commandment.NewMemoryEventStore()
+
+	for range 2 {
+		if err := store.Append(createRenameDescriptor(t, bus, "same")); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	if events := store.Events(); len(events) != 2 {
+		t.Errorf("Expected 2 stored entries, got %d", len(events))
+	}
+}
+
+func TestDescriptorHashStableAcrossRoundTrip(t *testing.T) {
+	descriptor := createRenameDescriptor(t, newRenameBus(), "round-trip")
+
+	data, err := json.Marshal(descriptor)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded commandment.OperationDescriptor
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	original, err := descriptor.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	roundTripped, err := decoded.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if original != roundTripped {
+		t.Errorf("Expected hash %s after round-trip, got %s", original, roundTripped)
+	}
+
+	decoded.Type = "OtherCommand"
+	if other, _ := decoded.Hash(); other == original {
+		t.Error("Expected hash to depend on the operation type")
+	}
+}
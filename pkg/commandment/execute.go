@@ -0,0 +1,374 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ExecuteOperation is a context-aware execution wrapper that enriches context with operation metadata
+// before calling the business logic. This allows downstream services to access operation metadata.
+// Middleware registered on the bus that created the operation wraps the business logic.
+func ExecuteOperation[T any](ctx context.Context, op OperationWithMetadata, businessLogic func(context.Context) (T, error)) (T, error) {
+	exec := beginExecution(ctx, op)
+	ctx = exec.enrich(ctx)
+	ctx, exec.span = exec.bus.startSpan(ctx, op, exec.typeName)
+
+	businessLogic, err := dryRunLogic(ctx, op, businessLogic)
+	if err != nil {
+		exec.reject(ctx, err)
+		var zero T
+		return zero, err
+	}
+	ctx, release, err := exec.acquire(ctx)
+	if err != nil {
+		exec.reject(ctx, err)
+		var zero T
+		return zero, err
+	}
+	defer release()
+
+	exec.logger.Info("Operation execution started", exec.logFields()...)
+
+	exec.bus.executionStarted(exec)
+	exec.bus.emitStarted(*exec.metadata)
+	out, err := exec.bus.chain(func(ctx context.Context) (any, error) {
+		return executeBusinessLogic(ctx, exec, businessLogic)
+	})(ctx)
+	exec.bus.executionFinished(exec, err)
+
+	result, err := resultAs[T](out, err)
+	if err == nil {
+		if err = checkNotModified(op, exec.ifNoneMatch, result); err != nil {
+			var zero T
+			result = zero
+		}
+	}
+	exec.span.End(err)
+	exec.finish(err)
+	if err == nil {
+		exec.bus.observeResultSize(exec.typeName, result)
+		exec.bus.runPostHooks(ctx, result)
+	}
+	exec.bus.audit(ctx, op, err)
+	return result, err
+}
+
+// acquire admits the execution and claims what it runs under: deadlines from the
+// SLA budget in ctx and the timeout for the op's type, and the op's serialization key.
+// The returned func releases them.
+func (e *execution) acquire(ctx context.Context) (context.Context, func(), error) {
+	if err := e.bus.admit(ctx, e.op); err != nil {
+		return ctx, nil, err
+	}
+	ctx, endBudget, err := startSLABudget(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	e.timeout = e.bus.executionTimeout(e.op)
+	ctx, cancel := withExecutionDeadline(ctx, e.timeout)
+	release, err := e.bus.acquireSerialization(ctx, e.op)
+	if err != nil {
+		cancel()
+		endBudget()
+		return ctx, nil, err
+	}
+	return ctx, func() {
+		release()
+		cancel()
+		endBudget()
+	}, nil
+}
+
+// admit checks whether the caller still wants op executed, op is valid and the bus
+// allows it to execute at all.
+func (b *OperationBus) admit(ctx context.Context, op any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validate(op); err != nil {
+		return err
+	}
+	if err := b.authorize(ctx, op); err != nil {
+		return err
+	}
+	if err := b.checkAudit(op); err != nil {
+		return err
+	}
+	if err := b.checkRateLimit(ctx); err != nil {
+		return err
+	}
+	return b.checkCircuit(op)
+}
+
+// execution carries the state of a single ExecuteOperation call.
+type execution struct {
+	op               OperationWithMetadata
+	bus              *OperationBus // nil for operations not created by a bus
+	deps             any
+	logger           Logger
+	typeName         string
+	metadata         *OperationMetadata
+	dryRun           bool
+	idempotencyKey   string
+	ifNoneMatch      string // ETag for conditional queries
+	logContext       []any  // log fields from the caller's locale and tagged params
+	started          time.Time
+	span             Span                   // span of the execution, ended with its outcome
+	timeout          time.Duration          // execution timeout applied by the bus, zero for none
+	clock            Clock                  // from the context or the bus, nil for the wall clock
+	shortCircuitedBy atomic.Pointer[string] // name of the NamedMiddleware that didn't call next
+}
+
+// executionKey is the context key for the current execution
+const executionKey contextKey = "commandment:execution"
+
+// executionFromContext returns the execution running with ctx, or nil.
+func executionFromContext(ctx context.Context) *execution {
+	exec, _ := ctx.Value(executionKey).(*execution)
+	return exec
+}
+
+// beginExecution records the execution start time and resolves per-execution settings
+// from the caller's context.
+func beginExecution(ctx context.Context, op OperationWithMetadata) *execution {
+	metadata := op.GetMetadata()
+	started := time.Now()
+	metadata.TraceParent = resolveTraceParent(ctx)
+	metadata.CorrelationID, _ = CorrelationIDFromContext(ctx)
+	metadata.ParentUUID = parentUUID(ctx, metadata.UUID)
+	metadata.operationType = operationTypeName(op)
+	var bus *OperationBus
+	var deps any
+	if state := executionState(ctx, op); state != nil {
+		bus, deps = state.bus, state.deps
+	}
+	clock := clockFromContext(ctx)
+	if clock == nil && bus != nil {
+		clock = bus.clock
+	}
+	if clock != nil {
+		metadata.Executed = clock.Now()
+	} else {
+		metadata.Executed = time.Now()
+	}
+	metadata.Extras = bus.snapshotContext(ctx)
+	logContext := localeLogFields(ctx)
+	if metadata.CorrelationID != "" {
+		logContext = append(logContext, "correlation_id", metadata.CorrelationID)
+	}
+	if IsDryRun(ctx) {
+		logContext = append(logContext, "dry_run", true)
+	}
+	if d, ok := op.(Describer); ok {
+		logContext = append(logContext, paramLogFields(bus.redactParams(d.Descriptor().Params))...)
+	}
+	return &execution{
+		op:             op,
+		bus:            bus,
+		deps:           deps,
+		logger:         op.GetLogger(),
+		typeName:       metadata.operationType,
+		metadata:       metadata,
+		dryRun:         IsDryRun(ctx),
+		idempotencyKey: resolveIdempotencyKey(ctx, op, metadata),
+		ifNoneMatch:    ifNoneMatch(ctx),
+		logContext:     logContext,
+		started:        started,
+		clock:          clock,
+	}
+}
+
+// enrich adds operation metadata, traceparent, correlation ID, clock, descriptor, dependencies and logger to the context,
+// scopes the idempotency namespace so child operations derive their keys from ours,
+// ensures a warnings collector, and applies the bus context enrichers.
+func (e *execution) enrich(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, executionKey, e)
+	ctx = WithOperationMetadata(ctx, e.metadata)
+	ctx = WithTraceParent(ctx, e.metadata.TraceParent)
+	if e.metadata.CorrelationID != "" {
+		ctx = WithCorrelationID(ctx, e.metadata.CorrelationID)
+	}
+	ctx = withLogger(ctx, fieldsLogger{logger: e.logger, fields: e.logFields()})
+	if e.clock != nil {
+		ctx = WithClock(ctx, e.clock)
+	}
+	descriptor := OperationDescriptor{Type: QualifiedTypeName(e.op), Metadata: *e.metadata}
+	if d, ok := e.op.(Describer); ok {
+		descriptor = d.Descriptor()
+		ctx = withOperationDescriptor(ctx, descriptor)
+	}
+	if e.deps != nil {
+		ctx = WithDependencies(ctx, e.deps)
+	}
+	ctx = withIdempotencyNamespace(ctx, e.idempotencyKey)
+	ctx = withExecutionWarnings(ctx)
+	if e.ifNoneMatch != "" {
+		ctx = WithIfNoneMatch(ctx, "")
+	}
+	return e.bus.applyEnrichers(ctx, descriptor)
+}
+
+// logFields returns the key/value pairs identifying the operation in every log line.
+func (e *execution) logFields(extra ...any) []any {
+	fields := []any{
+		"operation_type", e.typeName,
+		"operation_id", e.metadata.UUID,
+	}
+	fields = append(fields, e.logContext...)
+	return append(fields, extra...)
+}
+
+// now returns the time according to the execution's clock.
+func (e *execution) now() time.Time {
+	if e.clock != nil {
+		return e.clock.Now()
+	}
+	return time.Now()
+}
+
+// returned records the return time and execution duration in the metadata.
+func (e *execution) returned() {
+	e.metadata.Returned = e.now()
+	e.metadata.DurationMs = e.metadata.Duration().Milliseconds()
+}
+
+// reject records an execution refused before it started, auditing it like one that
+// failed.
+func (e *execution) reject(ctx context.Context, err error) {
+	e.returned()
+	e.span.End(err)
+	e.logger.Error("Operation execution rejected", e.logFields(
+		"duration_ms", e.metadata.DurationMs,
+		"error", err,
+	)...)
+	e.bus.emitCompleted(*e.metadata, err)
+	e.bus.audit(ctx, e.op, err)
+}
+
+// finish records the return time and logs the outcome, logging domain errors apart
+// from execution failures.
+func (e *execution) finish(err error) {
+	e.returned()
+	fields := []any{"duration_ms", e.metadata.DurationMs}
+	if name := e.shortCircuitedBy.Load(); name != nil {
+		fields = append(fields, "short_circuited_by", *name)
+	}
+	if errors.Is(err, ErrNotModified) {
+		fields = append(fields, "not_modified", true)
+		err = nil
+	}
+	if e.timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+		fields = append(fields, "timeout", e.timeout)
+	}
+	e.bus.emitCompleted(*e.metadata, err)
+	if opErr, ok := AsOperationError(err); ok {
+		e.logger.Warn("Operation returned domain error", e.logFields(append(fields,
+			"error_code", opErr.Code,
+			"retryable", opErr.Retryable,
+			"error", err,
+		)...)...)
+		return
+	}
+	if err != nil {
+		e.logger.Error("Operation execution failed", e.logFields(append(fields, "error", err)...)...)
+		return
+	}
+	e.logger.Info("Operation execution completed", e.logFields(fields...)...)
+}
+
+// executeBusinessLogic runs the business logic unless a cached or recorded result
+// can be returned instead. It is the innermost step of the middleware chain.
+func executeBusinessLogic[T any](ctx context.Context, exec *execution, businessLogic func(context.Context) (T, error)) (any, error) {
+	callService := func(ctx context.Context) (any, error) {
+		serviceCtx, cancel := exec.bus.withServiceDeadline(ctx)
+		defer cancel()
+		result, err := businessLogic(serviceCtx)
+		exec.bus.recordCircuit(exec.op, err)
+		if err != nil {
+			return result, err
+		}
+		if err := exec.bus.observeResult(ctx, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	requestCache := beginRequestCache(ctx, exec.op)
+	queryCache := exec.bus.beginQueryCache(exec.op)
+	if result, ok, err := cachedResult[T](ctx, exec, requestCache, queryCache, callService); ok {
+		return result, err
+	}
+
+	store := exec.bus.idempotencyStoreOrNil()
+	if exec.dryRun {
+		store = nil
+	}
+	if store != nil && exec.idempotencyKey != "" {
+		if result, ok := replayIdempotentResult[T](store, exec.idempotencyKey); ok {
+			exec.logger.Info("Operation result replayed from idempotency store",
+				exec.logFields("idempotency_key", exec.idempotencyKey)...)
+			return result, nil
+		}
+	}
+
+	result, err := callService(ctx)
+	requestCache.finish(result, err)
+	queryCache.finish(result, err)
+	if err == nil && store != nil && exec.idempotencyKey != "" {
+		recordIdempotentResult(store, exec.idempotencyKey, result, exec.logger)
+	}
+	return result, err
+}
+
+// cachedResult returns a query result from the request cache or, failing that, the
+// bus query cache. A stale query cache hit is returned and refreshed with refresh
+// in the background; a negative hit returns the cached not-found error.
+func cachedResult[T any](
+	ctx context.Context,
+	exec *execution,
+	requestCache requestCacheScope,
+	queryCache queryCacheScope,
+	refresh func(context.Context) (any, error),
+) (result T, ok bool, err error) {
+	if cached, ok := requestCache.lookup(); ok {
+		if result, ok := cached.(T); ok {
+			exec.logger.Info("Operation result served from request cache", exec.logFields()...)
+			return result, true, nil
+		}
+	}
+	entry, stale, ok := queryCache.lookup()
+	if !ok {
+		return result, false, nil
+	}
+	if entry.err != nil {
+		exec.logger.Info("Operation not-found result served from query cache", exec.logFields()...)
+		return result, true, entry.err
+	}
+	if result, ok := entry.value.(T); ok {
+		exec.logger.Info("Operation result served from query cache", exec.logFields("stale", stale)...)
+		if stale {
+			queryCache.revalidate(ctx, refresh)
+		}
+		return result, true, nil
+	}
+	return result, false, nil
+}
+
+// resultAs converts the output of the middleware chain back to the operation's result type.
+func resultAs[T any](out any, err error) (T, error) {
+	var zero T
+	if out == nil {
+		return zero, err
+	}
+	result, ok := out.(T)
+	if !ok {
+		if err != nil {
+			return zero, err
+		}
+		return zero, fmt.Errorf("middleware returned result of type %T, expected %T", out, zero)
+	}
+	return result, err
+}
@@ -0,0 +1,36 @@
+package commandment
+
+import (
+	"reflect"
+	"time"
+)
+
+// NextCronRun exposes cron schedule evaluation to external tests.
+func NextCronRun(spec string, after time.Time) (time.Time, error) {
+	schedule, err := parseCronSpec(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.next(after), nil
+}
+
+// CanonicalJSONUncached exposes the unmemoized params encoding for benchmarks.
+func CanonicalJSONUncached(v any) ([]byte, error) {
+	return canonicalJSON(v)
+}
+
+// QueuedForSerializationKey exposes the number of executions waiting for key.
+func QueuedForSerializationKey(b *OperationBus, key string) int {
+	return b.serializer.queued(key)
+}
+
+// OperationLayoutCached reports whether the creation layout of opType is cached.
+func OperationLayoutCached(opType reflect.Type) bool {
+	_, ok := operationLayouts.Load(opType)
+	return ok
+}
+
+// ForgetOperationLayouts clears the operation layout cache, for benchmarks.
+func ForgetOperationLayouts() {
+	operationLayouts.Clear()
+}
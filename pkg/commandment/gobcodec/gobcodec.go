@@ -0,0 +1,60 @@
+// Package gobcodec provides a commandment.DescriptorCodec encoding operation
+// descriptors with encoding/gob.
+package gobcodec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Codec encodes descriptors with encoding/gob. Params are carried as JSON inside
+// the gob stream, so params types need no gob.Register call, and are masked as
+// JSON-encoded descriptors are; metadata timestamps keep their full precision and
+// zone offset.
+type Codec struct{}
+
+var _ commandment.DescriptorCodec = Codec{}
+
+// envelope is the gob representation of a descriptor.
+type envelope struct {
+	Type     string
+	Version  int
+	Params   []byte
+	Metadata commandment.OperationMetadata
+}
+
+// Encode returns the gob encoding of descriptor.
+func (Codec) Encode(descriptor commandment.OperationDescriptor) ([]byte, error) {
+	params, err := descriptor.ParamsJSON()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = gob.NewEncoder(&buf).Encode(envelope{
+		Type:     descriptor.Type,
+		Version:  descriptor.Version,
+		Params:   params,
+		Metadata: descriptor.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode decodes a descriptor encoded by Encode. Its params are a json.RawMessage.
+func (Codec) Decode(data []byte) (commandment.OperationDescriptor, error) {
+	var e envelope
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return commandment.OperationDescriptor{}, err
+	}
+	return commandment.OperationDescriptor{
+		Type:     e.Type,
+		Version:  e.Version,
+		Params:   json.RawMessage(e.Params),
+		Metadata: e.Metadata,
+	}, nil
+}
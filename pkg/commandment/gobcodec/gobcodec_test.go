@@ -0,0 +1,76 @@
+package gobcodec_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+	"github.com/davidlee/commandment/pkg/commandment/gobcodec"
+)
+
+// Params with a field masked when serialized
+type SignInParams struct {
+	User     string
+	Password string `commandment:"redact"`
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.FixedZone("AEDT", 11*60*60))
+	descriptor := commandment.OperationDescriptor{
+		Type:     "CreateListCommand",
+		Version:  2,
+		Params:   map[string]any{"title": "Groceries"},
+		Metadata: commandment.OperationMetadata{UUID: "op-1", Created: created, IdempotencyKey: "key-1"},
+	}
+
+	data, err := gobcodec.Codec{}.Encode(descriptor)
+	if err != nil {
+		t.Fatalf("Failed to encode descriptor: %v", err)
+	}
+	decoded, err := gobcodec.Codec{}.Decode(data)
+	if err != nil {
+		t.Fatalf("Failed to decode descriptor: %v", err)
+	}
+
+	if decoded.Type != descriptor.Type || decoded.Version != descriptor.Version {
+		t.Errorf("Expected type and version %s/%d, got %s/%d", descriptor.Type, descriptor.Version, decoded.Type, decoded.Version)
+	}
+	if params, _ := decoded.Params.(json.RawMessage); string(params) != `{"title":"Groceries"}` {
+		t.Errorf("Expected params as raw JSON, got %#v", decoded.Params)
+	}
+	meta := decoded.Metadata
+	if meta.UUID != "op-1" || meta.IdempotencyKey != "key-1" || !meta.Created.Equal(created) {
+		t.Errorf("Expected metadata to round-trip, got %+v", meta)
+	}
+	if _, offset := meta.Created.Zone(); offset != 11*60*60 || meta.Created.Nanosecond() != 123456789 {
+		t.Errorf("Expected zone offset and precision kept, got %v", meta.Created)
+	}
+}
+
+func TestCodecMasksRedactedParams(t *testing.T) {
+	descriptor := commandment.OperationDescriptor{
+		Type:   "SignInCommand",
+		Params: SignInParams{User: "ann", Password: "hunter2"},
+	}
+
+	data, err := gobcodec.Codec{}.Encode(descriptor)
+	if err != nil {
+		t.Fatalf("Failed to encode descriptor: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Error("Expected the password not to be encoded")
+	}
+	decoded, err := gobcodec.Codec{}.Decode(data)
+	if err != nil {
+		t.Fatalf("Failed to decode descriptor: %v", err)
+	}
+	var params SignInParams
+	if err := json.Unmarshal(decoded.Params.(json.RawMessage), &params); err != nil {
+		t.Fatalf("Failed to decode params: %v", err)
+	}
+	if params.User != "ann" || params.Password != "***" {
+		t.Errorf("Expected only the password masked, got %+v", params)
+	}
+}
@@ -0,0 +1,175 @@
+package commandment
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+)
+
+// idempotencyKeyKey is the context key for an explicit idempotency key
+const idempotencyKeyKey contextKey = "commandment:idempotency:key"
+
+// idempotencyNamespaceKey is the context key for the namespace inherited by child operations
+const idempotencyNamespaceKey contextKey = "commandment:idempotency:namespace"
+
+// IdempotencyStore records operation results by idempotency key so that
+// re-executing an operation with the same key returns the recorded result
+// without invoking its service again.
+type IdempotencyStore interface {
+	Seen(key string) (cachedResultJSON []byte, ok bool)
+	Store(key string, resultJSON []byte)
+}
+
+// WithIdempotencyStore configures the store consulted for operations executed
+// with an idempotency key.
+func WithIdempotencyStore(store IdempotencyStore) Option {
+	return func(b *OperationBus) {
+		b.idempotencyStore = store
+	}
+}
+
+// WithIdempotencyKey sets the idempotency key for the next operation executed with ctx.
+// Child operations executed from within that operation derive their own keys from it,
+// so re-running a whole tree of operations is idempotent.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key of the operation currently
+// executing with ctx, if it has one.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	if ns, ok := ctx.Value(idempotencyNamespaceKey).(*idempotencyNamespace); ok {
+		return ns.key, true
+	}
+	return "", false
+}
+
+// idempotencyNamespace hands out deterministic keys to the children of an operation,
+// derived from the parent's key and each child's type and params, so concurrent
+// children get the same keys on a retry whatever order they execute in.
+type idempotencyNamespace struct {
+	key string
+
+	mu       sync.Mutex
+	children map[string]int // executions by child descriptor hash
+}
+
+// childKey returns the key of a child operation. A child repeating the type and
+// params of an earlier sibling is told apart by its occurrence count.
+func (ns *idempotencyNamespace) childKey(op any) string {
+	descriptor := OperationDescriptor{Type: QualifiedTypeName(op)}
+	if d, ok := op.(Describer); ok {
+		descriptor = d.Descriptor()
+	}
+	hash, err := descriptor.Hash()
+	if err != nil {
+		hash = descriptor.Type
+	}
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	ns.children[hash]++
+	if n := ns.children[hash]; n > 1 {
+		return ns.key + "/" + hash + "#" + strconv.Itoa(n)
+	}
+	return ns.key + "/" + hash
+}
+
+// hasIdempotencyKey reports whether an operation with metadata, executed with ctx,
+// runs under an explicit idempotency key.
+func hasIdempotencyKey(ctx context.Context, metadata OperationMetadata) bool {
+	key, _ := ctx.Value(idempotencyKeyKey).(string)
+	return key != "" || metadata.IdempotencyKey != ""
+}
+
+// resolveIdempotencyKey determines the key for an operation about to execute with ctx.
+// An explicit key in ctx takes precedence, then one in the operation's metadata;
+// otherwise a key is derived from op and the parent operation's namespace.
+func resolveIdempotencyKey(ctx context.Context, op any, metadata *OperationMetadata) string {
+	if key, ok := ctx.Value(idempotencyKeyKey).(string); ok && key != "" {
+		return key
+	}
+	if metadata.IdempotencyKey != "" {
+		return metadata.IdempotencyKey
+	}
+	if ns, ok := ctx.Value(idempotencyNamespaceKey).(*idempotencyNamespace); ok {
+		return ns.childKey(op)
+	}
+	return ""
+}
+
+// withIdempotencyNamespace scopes ctx to an operation with the given key, so its
+// children derive keys from it rather than reusing the parent's explicit key.
+func withIdempotencyNamespace(ctx context.Context, key string) context.Context {
+	if ctx.Value(idempotencyKeyKey) != nil {
+		ctx = context.WithValue(ctx, idempotencyKeyKey, "")
+	}
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyNamespaceKey, &idempotencyNamespace{key: key, children: make(map[string]int)})
+}
+
+// replayIdempotentResult returns the recorded result for key, if any.
+func replayIdempotentResult[T any](store IdempotencyStore, key string) (T, bool) {
+	var result T
+	data, ok := store.Seen(key)
+	if !ok {
+		return result, false
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		var zero T
+		return zero, false
+	}
+	return result, true
+}
+
+// recordIdempotentResult stores a successful result under key.
+func recordIdempotentResult(store IdempotencyStore, key string, result any, logger Logger) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		logger.Warn("Operation result not recorded for idempotency",
+			"idempotency_key", key,
+			"error", err,
+		)
+		return
+	}
+	store.Store(key, data)
+}
+
+// idempotencyStoreOrNil returns the configured idempotency store; it is safe to call
+// on a nil bus, as for operations constructed without one.
+func (b *OperationBus) idempotencyStoreOrNil() IdempotencyStore {
+	if b == nil {
+		return nil
+	}
+	return b.idempotencyStore
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore safe for concurrent use.
+type MemoryIdempotencyStore struct {
+	mu      sync.RWMutex
+	results map[string][]byte
+}
+
+// NewMemoryIdempotencyStore creates an empty MemoryIdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		results: make(map[string][]byte),
+	}
+}
+
+// Seen implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Seen(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.results[key]
+	return data, ok
+}
+
+// Store implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Store(key string, resultJSON []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[key] = resultJSON
+}
@@ -0,0 +1,288 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Child service counting how many times each input was processed
+type ChildService struct {
+	calls map[string]int
+}
+
+func (s *ChildService) Process(ctx context.Context, input string) (string, error) {
+	s.calls[input]++
+	return "child:" + input, nil
+}
+
+// Test operation executed as a child of ParentOperation
+type ChildOperation struct {
+	Params  string
+	Service *ChildService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (op *ChildOperation) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, op, func(ctx context.Context) (string, error) {
+		return op.Service.Process(ctx, op.Params)
+	})
+}
+
+func (op *ChildOperation) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op *ChildOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "ChildOperation",
+		Params:   op.Params,
+		Metadata: op.Meta,
+	}
+}
+
+func (op *ChildOperation) GetMetadata() *commandment.OperationMetadata { return &op.Meta }
+func (op *ChildOperation) GetLogger() commandment.Logger               { return op.Logger }
+
+// Parent service spawning child operations, optionally failing after they complete
+type ParentService struct {
+	bus      *commandment.OperationBus
+	calls    int
+	failNext bool
+}
+
+func (s *ParentService) Run(ctx context.Context, children []string) ([]string, error) {
+	s.calls++
+	results := make([]string, 0, len(children))
+	for _, input := range children {
+		child, err := commandment.CreateOperation[*ChildOperation](s.bus, input)
+		if err != nil {
+			return nil, err
+		}
+		result, err := child.Execute(ctx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if s.failNext {
+		s.failNext = false
+		return nil, errors.New("parent failed after children")
+	}
+	return results, nil
+}
+
+// Test operation spawning child operations through its service
+type ParentOperation struct {
+	Params  []string
+	Service *ParentService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (op *ParentOperation) Execute(ctx context.Context) ([]string, error) {
+	return commandment.ExecuteOperation(ctx, op, func(ctx context.Context) ([]string, error) {
+		return op.Service.Run(ctx, op.Params)
+	})
+}
+
+func (op *ParentOperation) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op *ParentOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "ParentOperation",
+		Params:   op.Params,
+		Metadata: op.Meta,
+	}
+}
+
+func (op *ParentOperation) GetMetadata() *commandment.OperationMetadata { return &op.Meta }
+func (op *ParentOperation) GetLogger() commandment.Logger               { return op.Logger }
+
+func newIdempotentBus(store commandment.IdempotencyStore) (*commandment.OperationBus, *ParentService, *ChildService) {
+	registry := commandment.NewServiceRegistry()
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithIdempotencyStore(store))
+
+	parent := &ParentService{bus: bus}
+	child := &ChildService{calls: make(map[string]int)}
+	commandment.RegisterService(registry, parent)
+	commandment.RegisterService(registry, child)
+	return bus, parent, child
+}
+
+func TestIdempotentParentReplaysChildren(t *testing.T) {
+	bus, parent, child := newIdempotentBus(commandment.NewMemoryIdempotencyStore())
+	parent.failNext = true
+
+	ctx := commandment.WithIdempotencyKey(context.Background(), "request-1")
+	children := []string{"a", "b"}
+
+	// First run: children execute, then the parent fails
+	op, err := commandment.CreateOperation[*ParentOperation](bus, children)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(ctx); err == nil {
+		t.Fatal("Expected first execution to fail")
+	}
+
+	// Second run: the parent re-runs, but its children are replayed
+	op, err = commandment.CreateOperation[*ParentOperation](bus, children)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := op.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	expected := "[child:a child:b]"
+	if got := fmt.Sprint(result); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if parent.calls != 2 {
+		t.Errorf("Expected parent service to run twice, ran %d times", parent.calls)
+	}
+	for _, input := range children {
+		if child.calls[input] != 1 {
+			t.Errorf("Expected child %q to execute once, executed %d times", input, child.calls[input])
+		}
+	}
+}
+
+func TestIdempotentParentReplaysWholeTree(t *testing.T) {
+	bus, parent, child := newIdempotentBus(commandment.NewMemoryIdempotencyStore())
+	ctx := commandment.WithIdempotencyKey(context.Background(), "request-2")
+
+	for range 2 {
+		op, err := commandment.CreateOperation[*ParentOperation](bus, []string{"a", "b"})
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		if _, err := op.Execute(ctx); err != nil {
+			t.Fatalf("Operation execution failed: %v", err)
+		}
+	}
+
+	if parent.calls != 1 {
+		t.Errorf("Expected parent service to run once, ran %d times", parent.calls)
+	}
+	if child.calls["a"] != 1 || child.calls["b"] != 1 {
+		t.Errorf("Expected each child to execute once, got %v", child.calls)
+	}
+}
+
+func TestIdempotencyKeysDifferPerRequest(t *testing.T) {
+	bus, parent, child := newIdempotentBus(commandment.NewMemoryIdempotencyStore())
+
+	for _, key := range []string{"request-3", "request-4"} {
+		ctx := commandment.WithIdempotencyKey(context.Background(), key)
+		op, err := commandment.CreateOperation[*ParentOperation](bus, []string{"a"})
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		if _, err := op.Execute(ctx); err != nil {
+			t.Fatalf("Operation execution failed: %v", err)
+		}
+	}
+
+	if parent.calls != 2 {
+		t.Errorf("Expected parent service to run twice, ran %d times", parent.calls)
+	}
+	if child.calls["a"] != 2 {
+		t.Errorf("Expected child to execute twice, executed %d times", child.calls["a"])
+	}
+}
+
+func TestIdempotencyChildKeysDerivedFromParent(t *testing.T) {
+	store := commandment.NewMemoryIdempotencyStore()
+	bus, _, _ := newIdempotentBus(store)
+
+	ctx := commandment.WithIdempotencyKey(context.Background(), "request-5")
+	op, err := commandment.CreateOperation[*ParentOperation](bus, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(ctx); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	keys := []string{"request-5"}
+	for _, input := range []string{"a", "b"} {
+		hash, err := commandment.OperationDescriptor{Type: "ChildOperation", Params: input}.Hash()
+		if err != nil {
+			t.Fatalf("Failed to hash descriptor: %v", err)
+		}
+		keys = append(keys, "request-5/"+hash)
+	}
+	for _, key := range keys {
+		if _, ok := store.Seen(key); !ok {
+			t.Errorf("Expected result recorded under key %q", key)
+		}
+	}
+}
+
+func TestIdempotencyChildKeysIndependentOfExecutionOrder(t *testing.T) {
+	bus, parent, child := newIdempotentBus(commandment.NewMemoryIdempotencyStore())
+	parent.failNext = true
+	ctx := commandment.WithIdempotencyKey(context.Background(), "request-6")
+
+	var result any
+	for _, children := range [][]string{{"a", "b", "b"}, {"b", "a", "b"}} {
+		op, err := commandment.CreateOperation[*ParentOperation](bus, children)
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		result, _ = op.Execute(ctx)
+	}
+
+	expected := "[child:b child:a child:b]"
+	if got := fmt.Sprint(result); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if child.calls["a"] != 1 || child.calls["b"] != 2 {
+		t.Errorf("Expected children replayed by type and params on the retry, got %v", child.calls)
+	}
+}
+
+func TestMetadataIdempotencyKeySurvivesDescriptorRoundTrip(t *testing.T) {
+	bus, _, child := newIdempotentBus(commandment.NewMemoryIdempotencyStore())
+	commandment.RegisterOperationType[*ChildOperation](bus)
+
+	op, err := commandment.CreateOperation[*ChildOperation](bus, "a")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	op.GetMetadata().IdempotencyKey = "request-1"
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	data, err := json.Marshal(op.Descriptor())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	var descriptor commandment.OperationDescriptor
+	if err := json.Unmarshal(data, &descriptor); err != nil {
+		t.Fatalf("Failed to unmarshal descriptor: %v", err)
+	}
+	recreated, err := bus.CreateFromDescriptor(descriptor)
+	if err != nil {
+		t.Fatalf("Failed to recreate operation: %v", err)
+	}
+	result, err := bus.ExecuteAny(context.Background(), recreated)
+	if err != nil {
+		t.Fatalf("Recreated operation failed: %v", err)
+	}
+
+	if result != "child:a" || child.calls["a"] != 1 {
+		t.Errorf("Expected the recorded result replayed without re-running the service, got %v after %d calls", result, child.calls["a"])
+	}
+}
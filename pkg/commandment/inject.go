@@ -0,0 +1,90 @@
+package commandment
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// injectOption marks exported operation struct fields, besides the conventional
+// Service field, that CreateOperation fills with the service registered for the
+// field's type.
+const injectOption = "inject"
+
+// WithQueryRegistry makes the bus resolve the services of queries from registry,
+// such as one holding read-replica services, rather than from the registry the bus
+// was created with, which then serves commands only.
+func WithQueryRegistry(registry *ServiceRegistry) Option {
+	return func(b *OperationBus) {
+		b.queryRegistry = registry
+	}
+}
+
+// serviceRegistryFor returns the registry resolving services for operations of opType.
+func (b *OperationBus) serviceRegistryFor(opType reflect.Type) *ServiceRegistry {
+	if b.queryRegistry != nil && opType.Implements(reflect.TypeFor[readOnlyOperation]()) {
+		return b.queryRegistry
+	}
+	return b.registry
+}
+
+// serviceInjection is a registered service resolved for an operation struct field.
+type serviceInjection struct {
+	index   []int
+	service any
+}
+
+// operationServiceFields returns the fields of an operation struct filled from the
+// service registry: the Service field and exported fields tagged `commandment:"inject"`.
+func operationServiceFields(opType reflect.Type) []reflect.StructField {
+	if opType.Kind() == reflect.Pointer {
+		opType = opType.Elem()
+	}
+	var fields []reflect.StructField
+	for _, field := range reflect.VisibleFields(opType) {
+		if field.IsExported() && !field.Anonymous && (field.Name == "Service" || hasTagOption(field, injectOption)) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// resolveServices looks up the service for each of fields, returning
+// ErrServiceNotRegistered for the first that has none.
+func (s serviceSnapshot) resolveServices(fields []reflect.StructField, opTypeName string) ([]serviceInjection, error) {
+	injections := make([]serviceInjection, 0, len(fields))
+	for _, field := range fields {
+		service, ok := s.lookup(field.Type)
+		if !ok {
+			return nil, fmt.Errorf("%w: required service %v for operation %s",
+				ErrServiceNotRegistered, field.Type, opTypeName)
+		}
+		injections = append(injections, serviceInjection{index: field.Index, service: service})
+	}
+	return injections, nil
+}
+
+// serviceLogFields returns the log fields naming the services injected into an
+// operation: the Service field's type as service_type, tagged fields' types as
+// injected_services.
+func serviceLogFields(fields []reflect.StructField) []any {
+	var logData []any
+	var injected []string
+	for _, field := range fields {
+		if field.Name == "Service" {
+			logData = append(logData, "service_type", field.Type.Name())
+			continue
+		}
+		injected = append(injected, field.Type.String())
+	}
+	if len(injected) > 0 {
+		logData = append(logData, "injected_services", injected)
+	}
+	return logData
+}
+
+// injectServices sets each resolved service on the operation struct.
+func injectServices(structValue reflect.Value, injections []serviceInjection) {
+	for _, injection := range injections {
+		structValue.FieldByIndex(injection.index).Set(reflect.ValueOf(injection.service))
+	}
+}
@@ -0,0 +1,106 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Test query combining a TestService with an injected RecordService
+type AnnotatedReadQuery struct {
+	Params  string
+	Service TestService
+	Records *RecordService `commandment:"inject"`
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (q *AnnotatedReadQuery) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, q, func(ctx context.Context) (string, error) {
+		record, err := q.Records.Read(ctx, q.Params)
+		if err != nil {
+			return "", err
+		}
+		return q.Service.DoSomething(ctx, record)
+	})
+}
+
+func (q *AnnotatedReadQuery) Metadata() commandment.OperationMetadata {
+	return q.Meta
+}
+
+func (q *AnnotatedReadQuery) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "AnnotatedReadQuery",
+		Params:   q.Params,
+		Metadata: q.Meta,
+	}
+}
+
+func (q *AnnotatedReadQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
+func (q *AnnotatedReadQuery) GetLogger() commandment.Logger               { return q.Logger }
+func (q *AnnotatedReadQuery) ReadOnly()                                   {}
+
+func TestCreateOperationInjectsTaggedServices(t *testing.T) {
+	mockService := &MockTestService{}
+	records := &RecordService{records: map[string]string{"k": "v1"}}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, mockService)
+	commandment.RegisterService(registry, records)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*AnnotatedReadQuery](bus, "k")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if op.Service != mockService || op.Records != records {
+		t.Fatalf("Expected both services to be injected, got %v and %v", op.Service, op.Records)
+	}
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if result != "result: v1" {
+		t.Errorf("Expected result from both services, got %q", result)
+	}
+
+	commandment.RegisterOperationType[*AnnotatedReadQuery](bus)
+	want := []string{"commandment_test.TestService", "*commandment_test.RecordService"}
+	if got := bus.Catalog()[0].Services; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected catalog services %v, got %v", want, got)
+	}
+}
+
+func TestCreateOperationWithUnregisteredInjectedService(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	_, err := commandment.CreateOperation[*AnnotatedReadQuery](bus, "k")
+	if !errors.Is(err, commandment.ErrServiceNotRegistered) {
+		t.Fatalf("Expected ErrServiceNotRegistered, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "RecordService") {
+		t.Errorf("Expected error to name the missing service, got %q", err)
+	}
+}
+
+func TestQueryRegistryResolvesQueryServices(t *testing.T) {
+	readReplica := &RecordService{records: map[string]string{"k": "replica"}}
+	writeRegistry := commandment.NewServiceRegistry()
+	readRegistry := commandment.NewServiceRegistry()
+	commandment.RegisterService(readRegistry, readReplica)
+	bus := commandment.NewOperationBus(writeRegistry, &TestLogger{}, commandment.WithQueryRegistry(readRegistry))
+
+	if got := readRecord(context.Background(), t, bus, "k"); got != "replica" {
+		t.Errorf("Expected query to read from the read registry, got %q", got)
+	}
+	_, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if !errors.Is(err, commandment.ErrServiceNotRegistered) {
+		t.Errorf("Expected command not to resolve services from the read registry, got %v", err)
+	}
+}
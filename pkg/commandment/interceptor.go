@@ -0,0 +1,72 @@
+package commandment
+
+import (
+	"context"
+	"reflect"
+)
+
+// ServiceInterceptor observes individual service method calls, for profiling at a
+// finer grain than whole operations. Before and After run synchronously around the
+// call on its goroutine, so interceptors should return quickly.
+type ServiceInterceptor interface {
+	Before(ctx context.Context, serviceType reflect.Type, method string)
+	After(ctx context.Context, serviceType reflect.Type, method string, err error)
+}
+
+// AddServiceInterceptor registers interceptor to observe the service calls made
+// while operations created by the bus execute. Interceptors are called in
+// registration order before a call and in reverse order after it.
+//
+// Go can't wrap arbitrary interface methods at runtime, so services opt in by
+// running their method bodies through InterceptServiceCall.
+func (b *OperationBus) AddServiceInterceptor(interceptor ServiceInterceptor) {
+	b.interceptorsMu.Lock()
+	defer b.interceptorsMu.Unlock()
+	b.interceptors = append(b.interceptors, interceptor)
+}
+
+// WithServiceInterceptor registers interceptor at construction time, as
+// AddServiceInterceptor does.
+func WithServiceInterceptor(interceptor ServiceInterceptor) Option {
+	return func(b *OperationBus) {
+		b.AddServiceInterceptor(interceptor)
+	}
+}
+
+// InterceptServiceCall runs call, the body of method on a service registered as
+// TService, between the Before and After hooks of the interceptors on the bus
+// executing ctx. Outside an execution it just runs call. For example:
+//
+//	func (s *nodeStore) ShowNode(ctx context.Context, params ShowNodeQueryParams) (Node, error) {
+//		return commandment.InterceptServiceCall[NodeService](ctx, "ShowNode", func() (Node, error) {
+//			return s.load(ctx, params.Ref)
+//		})
+//	}
+func InterceptServiceCall[TService, TResult any](ctx context.Context, method string, call func() (TResult, error)) (TResult, error) {
+	var interceptors []ServiceInterceptor
+	if exec := executionFromContext(ctx); exec != nil {
+		interceptors = exec.bus.serviceInterceptors()
+	}
+	if len(interceptors) == 0 {
+		return call()
+	}
+	serviceType := reflect.TypeFor[TService]()
+	for _, interceptor := range interceptors {
+		interceptor.Before(ctx, serviceType, method)
+	}
+	result, err := call()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptors[i].After(ctx, serviceType, method, err)
+	}
+	return result, err
+}
+
+// serviceInterceptors returns the registered interceptors. It returns nil on a nil bus.
+func (b *OperationBus) serviceInterceptors() []ServiceInterceptor {
+	if b == nil {
+		return nil
+	}
+	b.interceptorsMu.RLock()
+	defer b.interceptorsMu.RUnlock()
+	return b.interceptors
+}
@@ -0,0 +1,70 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service opting in to interception
+type InterceptedService struct {
+	fail bool
+}
+
+func (s *InterceptedService) DoSomething(ctx context.Context, input string) (string, error) {
+	return commandment.InterceptServiceCall[TestService](ctx, "DoSomething", func() (string, error) {
+		if s.fail {
+			return "", errors.New("service failed")
+		}
+		return "ok: " + input, nil
+	})
+}
+
+// Interceptor appending its name and the hook to a shared log
+type NamedInterceptor struct {
+	name string
+	log  *[]string
+}
+
+func (i NamedInterceptor) Before(ctx context.Context, serviceType reflect.Type, method string) {
+	*i.log = append(*i.log, i.name+" before "+serviceType.String()+"."+method)
+}
+
+func (i NamedInterceptor) After(ctx context.Context, serviceType reflect.Type, method string, err error) {
+	entry := i.name + " after"
+	if err != nil {
+		entry += " " + err.Error()
+	}
+	*i.log = append(*i.log, entry)
+}
+
+func TestServiceInterceptorsWrapCallsInOrder(t *testing.T) {
+	var log []string
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &InterceptedService{fail: true})
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithServiceInterceptor(NamedInterceptor{"outer", &log}))
+	child := bus.With()
+	child.AddServiceInterceptor(NamedInterceptor{"inner", &log})
+
+	op, err := commandment.CreateOperation[*TestOperation](child, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err == nil {
+		t.Fatal("Expected service failure")
+	}
+
+	expected := []string{
+		"outer before commandment_test.TestService.DoSomething",
+		"inner before commandment_test.TestService.DoSomething",
+		"inner after service failed",
+		"outer after service failed",
+	}
+	if !reflect.DeepEqual(log, expected) {
+		t.Errorf("Expected %v, got %v", expected, log)
+	}
+}
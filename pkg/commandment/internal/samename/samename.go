@@ -0,0 +1,6 @@
+// Package samename declares a type with the same name as a commandment test
+// fixture, for testing that qualified type names don't collide across packages.
+package samename
+
+// TestOperation shares its name with the commandment tests' TestOperation.
+type TestOperation struct{}
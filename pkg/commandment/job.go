@@ -0,0 +1,74 @@
+package commandment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrJobUnbound is returned when running a Job that isn't attached to a bus.
+var ErrJobUnbound = errors.New("job is not bound to a bus")
+
+// ErrRedactedJob is returned when running a Job whose params hold values masked
+// by redaction, such as one persisted by encoding its descriptor rather than the
+// Job itself. Running it would execute the operation with the mask as its params.
+var ErrRedactedJob = errors.New("job params are redacted")
+
+// Job is a persistable form of an operation. It carries only the operation's
+// descriptor, so it can be serialized, enqueued in a durable job queue and later
+// recreated and run with CreateFromDescriptor. A Job encodes its params in full,
+// fields tagged `commandment:"redact"` included, since the operation needs them to
+// run; store persisted jobs as securely as those params.
+type Job struct {
+	Descriptor OperationDescriptor `json:"descriptor"`
+
+	bus Bus
+}
+
+// ToJob converts op into a Job bound to the bus.
+func (b *OperationBus) ToJob(op Describer) Job {
+	return Job{Descriptor: op.Descriptor(), bus: b}
+}
+
+// MarshalJSON encodes the job with its descriptor's params unredacted.
+func (j Job) MarshalJSON() ([]byte, error) {
+	params, err := rawParams(j.Descriptor.Params)
+	if err != nil {
+		return nil, fmt.Errorf("encoding job params: %w", err)
+	}
+	descriptor, err := j.Descriptor.marshalWithParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Descriptor json.RawMessage `json:"descriptor"`
+	}{Descriptor: descriptor})
+}
+
+// LoadJob decodes a Job serialized with json.Marshal and binds it to the bus.
+func (b *OperationBus) LoadJob(data []byte) (Job, error) {
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return Job{}, fmt.Errorf("decoding job: %w", err)
+	}
+	job.bus = b
+	return job, nil
+}
+
+// Run recreates the job's operation from its descriptor and executes it. Jobs
+// decoded directly rather than with LoadJob fail with ErrJobUnbound, and jobs whose
+// recreated params hold redaction masks fail with ErrRedactedJob.
+func (j Job) Run(ctx context.Context) (any, error) {
+	if j.bus == nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobUnbound, j.Descriptor.Type)
+	}
+	op, err := j.bus.CreateFromDescriptor(j.Descriptor)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := op.(Describer); ok && hasMaskedFields(d.Descriptor().Params) {
+		return nil, fmt.Errorf("%w: %s", ErrRedactedJob, j.Descriptor.Type)
+	}
+	return j.bus.ExecuteAny(ctx, op)
+}
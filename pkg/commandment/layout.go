@@ -0,0 +1,84 @@
+package commandment
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrInvalidOperationType is returned when creating an operation whose type is not
+// a struct, or pointer to one, with the fields the bus fills in.
+var ErrInvalidOperationType = errors.New("invalid operation type")
+
+// requiredOperationFields are the fields the bus sets on every operation it
+// creates, with the type each must accept. Operations also need a Service field
+// or at least one field tagged `commandment:"inject"`.
+var requiredOperationFields = []struct {
+	name      string
+	valueType reflect.Type
+}{
+	{"Params", nil}, // any type; checked against the params given to CreateOperation
+	{"Meta", reflect.TypeFor[OperationMetadata]()},
+	{"Logger", reflect.TypeFor[Logger]()},
+}
+
+// operationLayout is the reflection metadata needed to create operations of one
+// type, computed once per type so creation avoids repeated field lookups.
+type operationLayout struct {
+	structType reflect.Type
+	pointer    bool  // operations are pointers to structType
+	params     []int // field indices
+	meta       []int
+	logger     []int
+	services   []reflect.StructField // fields filled from the service registry
+	err        error                 // ErrInvalidOperationType if the type can't be created
+	pool       sync.Pool             // released operations, for WithOperationPooling
+}
+
+// operationLayouts caches operation layouts by operation type.
+var operationLayouts sync.Map // reflect.Type -> *operationLayout
+
+// layoutOf returns the layout of operations of opType, computing it on first use.
+func layoutOf(opType reflect.Type) *operationLayout {
+	if cached, ok := operationLayouts.Load(opType); ok {
+		return cached.(*operationLayout)
+	}
+	cached, _ := operationLayouts.LoadOrStore(opType, newOperationLayout(opType))
+	return cached.(*operationLayout)
+}
+
+// newOperationLayout computes the layout of opType, recording ErrInvalidOperationType
+// unless it is a struct or a pointer to one with the required operation fields and
+// a service to inject.
+func newOperationLayout(opType reflect.Type) *operationLayout {
+	layout := &operationLayout{structType: opType}
+	if opType.Kind() == reflect.Pointer {
+		layout.structType = opType.Elem()
+		layout.pointer = true
+	}
+	if layout.structType.Kind() != reflect.Struct {
+		layout.err = fmt.Errorf("%w: %s is not a struct or pointer to struct", ErrInvalidOperationType, opType)
+		return layout
+	}
+	indices := make([][]int, len(requiredOperationFields))
+	for i, required := range requiredOperationFields {
+		field, ok := layout.structType.FieldByName(required.name)
+		if !ok || !field.IsExported() {
+			layout.err = fmt.Errorf("%w: %s has no %s field", ErrInvalidOperationType, opType, required.name)
+			return layout
+		}
+		if required.valueType != nil && !required.valueType.AssignableTo(field.Type) {
+			layout.err = fmt.Errorf("%w: %s field %s is %s, expected %s",
+				ErrInvalidOperationType, opType, required.name, field.Type, required.valueType)
+			return layout
+		}
+		indices[i] = field.Index
+	}
+	layout.params, layout.meta, layout.logger = indices[0], indices[1], indices[2]
+	layout.services = operationServiceFields(opType)
+	if len(layout.services) == 0 {
+		layout.err = fmt.Errorf("%w: operation %s has no Service field", ErrInvalidOperationType, opType)
+	}
+	return layout
+}
@@ -0,0 +1,48 @@
+package commandment
+
+import (
+	"context"
+	"time"
+)
+
+// localeKey is the context key for the caller's locale
+const localeKey contextKey = "commandment:locale"
+
+// timezoneKey is the context key for the caller's timezone
+const timezoneKey contextKey = "commandment:timezone"
+
+// WithLocale sets the caller's locale, such as a BCP 47 tag like "en-GB", for
+// operations executed with ctx and their child operations.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// LocaleFromContext retrieves the caller's locale from context.
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeKey).(string)
+	return locale, ok
+}
+
+// WithTimezone sets the caller's timezone for operations executed with ctx and
+// their child operations.
+func WithTimezone(ctx context.Context, tz *time.Location) context.Context {
+	return context.WithValue(ctx, timezoneKey, tz)
+}
+
+// TimezoneFromContext retrieves the caller's timezone from context.
+func TimezoneFromContext(ctx context.Context) (*time.Location, bool) {
+	tz, ok := ctx.Value(timezoneKey).(*time.Location)
+	return tz, ok && tz != nil
+}
+
+// localeLogFields returns log fields for the locale and timezone carried by ctx.
+func localeLogFields(ctx context.Context) []any {
+	var fields []any
+	if locale, ok := LocaleFromContext(ctx); ok {
+		fields = append(fields, "locale", locale)
+	}
+	if tz, ok := TimezoneFromContext(ctx); ok {
+		fields = append(fields, "timezone", tz.String())
+	}
+	return fields
+}
@@ -0,0 +1,76 @@
+package commandment_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service greeting in the caller's locale
+type GreetingService struct{}
+
+func (s *GreetingService) DoSomething(ctx context.Context, input string) (string, error) {
+	locale, _ := commandment.LocaleFromContext(ctx)
+	tz, ok := commandment.TimezoneFromContext(ctx)
+	zone := "none"
+	if ok {
+		zone = tz.String()
+	}
+	return input + " [" + locale + ", " + zone + "]", nil
+}
+
+func TestServiceReadsCallerLocale(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &GreetingService{})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+
+	tz, err := time.LoadLocation("UTC")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+	ctx := commandment.WithTimezone(commandment.WithLocale(context.Background(), "fr-CA"), tz)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "bonjour")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := op.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if result != "bonjour [fr-CA, UTC]" {
+		t.Errorf("Expected service to read the caller's locale and timezone, got %q", result)
+	}
+
+	entry, _ := logger.Find("Operation execution started")
+	if locale, _ := entry.Field("locale"); locale != "fr-CA" {
+		t.Errorf("Expected locale in execution log, got %v", locale)
+	}
+	if zone, _ := entry.Field("timezone"); zone != "UTC" {
+		t.Errorf("Expected timezone in execution log, got %v", zone)
+	}
+}
+
+func TestLocaleOmittedWhenUnset(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &GreetingService{})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "hello")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	entry, _ := logger.Find("Operation execution started")
+	if _, ok := entry.Field("locale"); ok {
+		t.Error("Expected no locale field without a caller locale")
+	}
+}
@@ -0,0 +1,55 @@
+package commandment
+
+import "context"
+
+// loggerKey is the context key for the executing operation's logger
+const loggerKey contextKey = "commandment:logger"
+
+// LoggerFromContext returns the logger of the operation executing with ctx, which
+// adds the operation's identifying fields, such as operation_id, to every record.
+// Outside of execution it returns a logger discarding every record.
+func LoggerFromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey).(Logger); ok {
+		return logger
+	}
+	return noopLogger{}
+}
+
+// withLogger adds logger to the context for LoggerFromContext.
+func withLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// fieldsLogger prepends fields to the key/value pairs of every record.
+type fieldsLogger struct {
+	logger Logger
+	fields []any
+}
+
+func (l fieldsLogger) with(keysAndValues []any) []any {
+	return append(append([]any(nil), l.fields...), keysAndValues...)
+}
+
+func (l fieldsLogger) Info(msg string, keysAndValues ...any) {
+	l.logger.Info(msg, l.with(keysAndValues)...)
+}
+
+func (l fieldsLogger) Error(msg string, keysAndValues ...any) {
+	l.logger.Error(msg, l.with(keysAndValues)...)
+}
+
+func (l fieldsLogger) Warn(msg string, keysAndValues ...any) {
+	l.logger.Warn(msg, l.with(keysAndValues)...)
+}
+
+func (l fieldsLogger) Debug(msg string, keysAndValues ...any) {
+	l.logger.Debug(msg, l.with(keysAndValues)...)
+}
+
+// noopLogger discards every record.
+type noopLogger struct{}
+
+func (noopLogger) Info(msg string, keysAndValues ...any)  {}
+func (noopLogger) Error(msg string, keysAndValues ...any) {}
+func (noopLogger) Warn(msg string, keysAndValues ...any)  {}
+func (noopLogger) Debug(msg string, keysAndValues ...any) {}
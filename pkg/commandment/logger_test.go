@@ -0,0 +1,53 @@
+package commandment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service logging through the executing operation's logger
+type LoggingService struct{}
+
+func (s *LoggingService) DoSomething(ctx context.Context, input string) (string, error) {
+	commandment.LoggerFromContext(ctx).Info("Service called", "input", input)
+	return "done: " + input, nil
+}
+
+func TestLoggerFromContextAddsOperationFields(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &LoggingService{})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	entry, ok := logger.Find("Service called")
+	if !ok {
+		t.Fatal("Expected the service record to reach the bus logger")
+	}
+	if id, _ := entry.Field("operation_id"); id != op.Metadata().UUID {
+		t.Errorf("Expected operation_id %s, got %v", op.Metadata().UUID, id)
+	}
+	if typeName, _ := entry.Field("operation_type"); typeName != "TestOperation" {
+		t.Errorf("Expected operation_type TestOperation, got %v", typeName)
+	}
+	if input, _ := entry.Field("input"); input != "x" {
+		t.Errorf("Expected the service's own fields kept, got %v", input)
+	}
+}
+
+func TestLoggerFromContextOutsideExecutionDiscards(t *testing.T) {
+	logger := commandment.LoggerFromContext(context.Background())
+	if logger == nil {
+		t.Fatal("Expected a no-op logger outside execution, got nil")
+	}
+	logger.Info("Discarded", "key", "value")
+}
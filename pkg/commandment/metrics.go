@@ -0,0 +1,183 @@
+package commandment
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the execution latency
+// histogram buckets for operation types without configured buckets.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram is a snapshot of the observations of one histogram. Counts[i] is the
+// number of observations above Buckets[i-1] and at most Buckets[i]; the final
+// count holds observations above the last bucket.
+type Histogram struct {
+	Buckets []float64 `json:"buckets"`
+	Counts  []uint64  `json:"counts"`
+	Count   uint64    `json:"count"`
+	Sum     float64   `json:"sum"`
+}
+
+// WithHistogramBuckets sets the latency histogram bucket upper bounds, in seconds,
+// for operations of the named type, so slow commands and fast queries can each be
+// measured at a useful resolution. A metrics.Collector created with
+// metrics.WithBuckets(bus.HistogramBuckets) exports its duration histograms with
+// the same buckets. Metrics are shared with child buses, except that a child given
+// its own buckets with With records into a copy of the parent's metrics, leaving
+// the parent's histograms untouched.
+func WithHistogramBuckets(typeName string, buckets []float64) Option {
+	return func(b *OperationBus) {
+		b.metrics = b.metrics.withBuckets(typeName, buckets)
+	}
+}
+
+// HistogramBuckets returns the latency histogram bucket upper bounds, in seconds,
+// for operations of the named type: those set with WithHistogramBuckets, or
+// DefaultLatencyBuckets. Exporters such as metrics.Collector read it so the bus's
+// histograms and the exported ones share one configuration.
+func (b *OperationBus) HistogramBuckets(typeName string) []float64 {
+	return b.metrics.latencyBuckets(typeName)
+}
+
+// LatencyHistogram returns the execution latency histogram of operations of the
+// named type, in seconds.
+func (b *OperationBus) LatencyHistogram(typeName string) Histogram {
+	return b.metrics.snapshot(latencyMetric, typeName)
+}
+
+// DefaultSizeBuckets are the upper bounds, in bytes, of the result size histogram buckets.
+var DefaultSizeBuckets = []float64{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+// WithResultSizeMetrics observes the size in bytes of every successful result, as
+// encoded by codec, or as JSON if codec is nil, in a per-type histogram, to track
+// payload growth.
+func WithResultSizeMetrics(codec Codec[any]) Option {
+	return func(b *OperationBus) {
+		if codec == nil {
+			codec = jsonCodec{}
+		}
+		b.sizeCodec = codec
+	}
+}
+
+// ResultSizeHistogram returns the serialized result size histogram of operations
+// of the named type, in bytes. It is empty unless WithResultSizeMetrics is set.
+func (b *OperationBus) ResultSizeHistogram(typeName string) Histogram {
+	return b.metrics.snapshot(sizeMetric, typeName)
+}
+
+// observeResultSize records the serialized size of result; it is a no-op on a nil
+// bus, without a codec, or for results the codec can't encode.
+func (b *OperationBus) observeResultSize(typeName string, result any) {
+	if b == nil || b.sizeCodec == nil {
+		return
+	}
+	data, err := b.sizeCodec.Encode(result)
+	if err != nil {
+		return
+	}
+	b.metrics.observe(sizeMetric, typeName, float64(len(data)))
+}
+
+// metric names a family of per-type histograms.
+type metric int
+
+const (
+	latencyMetric metric = iota
+	sizeMetric
+)
+
+type histogramKey struct {
+	metric   metric
+	typeName string
+}
+
+// metricsRegistry holds the per-type histograms of a bus.
+type metricsRegistry struct {
+	mu         sync.Mutex
+	buckets    map[string][]float64 // latency buckets by type
+	histograms map[histogramKey]*Histogram
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		buckets:    make(map[string][]float64),
+		histograms: make(map[histogramKey]*Histogram),
+	}
+}
+
+// withBuckets returns a copy of m using buckets for the latency histogram of the
+// named type, which starts empty in the copy. m itself is left unchanged.
+func (m *metricsRegistry) withBuckets(typeName string, buckets []float64) *metricsRegistry {
+	buckets = slices.Clone(buckets)
+	sort.Float64s(buckets)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := &metricsRegistry{
+		buckets:    maps.Clone(m.buckets),
+		histograms: make(map[histogramKey]*Histogram, len(m.histograms)),
+	}
+	copied.buckets[typeName] = buckets
+	for key, h := range m.histograms {
+		if key != (histogramKey{latencyMetric, typeName}) {
+			copied.histograms[key] = &Histogram{Buckets: h.Buckets, Counts: slices.Clone(h.Counts), Count: h.Count, Sum: h.Sum}
+		}
+	}
+	return copied
+}
+
+// latencyBuckets returns a copy of the latency buckets of the named type.
+func (m *metricsRegistry) latencyBuckets(typeName string) []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if buckets, ok := m.buckets[typeName]; ok {
+		return slices.Clone(buckets)
+	}
+	return slices.Clone(DefaultLatencyBuckets)
+}
+
+// histogram returns the live histogram for key; m.mu must be held.
+func (m *metricsRegistry) histogram(key histogramKey) *Histogram {
+	h, ok := m.histograms[key]
+	if !ok {
+		buckets := DefaultSizeBuckets
+		if key.metric == latencyMetric {
+			buckets, ok = m.buckets[key.typeName]
+			if !ok {
+				buckets = DefaultLatencyBuckets
+			}
+		}
+		h = &Histogram{Buckets: buckets, Counts: make([]uint64, len(buckets)+1)}
+		m.histograms[key] = h
+	}
+	return h
+}
+
+func (m *metricsRegistry) observeLatency(typeName string, d time.Duration) {
+	m.observe(latencyMetric, typeName, d.Seconds())
+}
+
+func (m *metricsRegistry) observe(metric metric, typeName string, v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.histogram(histogramKey{metric, typeName})
+	h.Counts[sort.SearchFloat64s(h.Buckets, v)]++
+	h.Count++
+	h.Sum += v
+}
+
+func (m *metricsRegistry) snapshot(metric metric, typeName string) Histogram {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.histogram(histogramKey{metric, typeName})
+	return Histogram{
+		Buckets: slices.Clone(h.Buckets),
+		Counts:  slices.Clone(h.Counts),
+		Count:   h.Count,
+		Sum:     h.Sum,
+	}
+}
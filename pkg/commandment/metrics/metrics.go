@@ -0,0 +1,119 @@
+// Package metrics exports RED metrics for commandment operations to Prometheus.
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Status label values.
+const (
+	statusOK    = "ok"
+	statusError = "error"
+)
+
+// Collector counts operation executions and observes their duration, labelled by
+// operation_type and status ("ok" or "error"). Register it with a Prometheus
+// registry and add it to the bus with AddEventSink. As an EventSink it sees every
+// execution, including those rejected before they start, such as by validation or
+// rate limiting, which are counted as errors.
+type Collector struct {
+	operations *prometheus.CounterVec
+	buckets    func(typeName string) []float64
+
+	mu        sync.Mutex
+	durations map[string]*prometheus.HistogramVec // by operation type
+}
+
+var (
+	_ prometheus.Collector  = (*Collector)(nil)
+	_ commandment.EventSink = (*Collector)(nil)
+)
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithBuckets observes the duration of each operation type in the buckets that
+// buckets returns for it. Pass bus.HistogramBuckets so the exported histograms
+// use the buckets set on the bus with commandment.WithHistogramBuckets.
+func WithBuckets(buckets func(typeName string) []float64) Option {
+	return func(c *Collector) {
+		c.buckets = buckets
+	}
+}
+
+var labels = []string{"operation_type", "status"}
+
+// NewCollector creates a Collector, with the default Prometheus duration buckets
+// unless WithBuckets is given.
+func NewCollector(opts ...Option) *Collector {
+	c := &Collector{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "commandment_operations_total",
+			Help: "Operation executions by type and status.",
+		}, labels),
+		buckets:   func(string) []float64 { return prometheus.DefBuckets },
+		durations: make(map[string]*prometheus.HistogramVec),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// newDurationVec creates a duration histogram with buckets. Histograms of every
+// operation type share one descriptor, so they are exported as a single metric.
+func newDurationVec(buckets []float64) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "commandment_operation_duration_seconds",
+		Help:    "Operation execution duration by type and status.",
+		Buckets: buckets,
+	}, labels)
+}
+
+// duration returns the duration histogram of the named operation type.
+func (c *Collector) duration(typeName string) *prometheus.HistogramVec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	vec, ok := c.durations[typeName]
+	if !ok {
+		vec = newDurationVec(c.buckets(typeName))
+		c.durations[typeName] = vec
+	}
+	return vec
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.operations.Describe(ch)
+	newDurationVec(prometheus.DefBuckets).Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.operations.Collect(ch)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, vec := range c.durations {
+		vec.Collect(ch)
+	}
+}
+
+// OnCreated implements commandment.EventSink.
+func (c *Collector) OnCreated(commandment.OperationDescriptor) {}
+
+// OnStarted implements commandment.EventSink.
+func (c *Collector) OnStarted(commandment.OperationMetadata) {}
+
+// OnCompleted implements commandment.EventSink, recording the execution.
+func (c *Collector) OnCompleted(metadata commandment.OperationMetadata, err error) {
+	status := statusOK
+	if err != nil {
+		status = statusError
+	}
+	c.operations.WithLabelValues(metadata.OperationType(), status).Inc()
+	c.duration(metadata.OperationType()).WithLabelValues(metadata.OperationType(), status).Observe(metadata.Duration().Seconds())
+}
@@ -0,0 +1,177 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+	"github.com/davidlee/commandment/pkg/commandment/metrics"
+)
+
+// Test logger discarding everything
+type TestLogger struct{}
+
+func (l *TestLogger) Info(msg string, keysAndValues ...any)  {}
+func (l *TestLogger) Warn(msg string, keysAndValues ...any)  {}
+func (l *TestLogger) Error(msg string, keysAndValues ...any) {}
+func (l *TestLogger) Debug(msg string, keysAndValues ...any) {}
+
+// Service echoing its input, failing on "fail"
+type EchoService struct{}
+
+func (s *EchoService) Echo(ctx context.Context, input string) (string, error) {
+	if input == "fail" {
+		return "", errors.New("echo failed")
+	}
+	return input, nil
+}
+
+// Test operation echoing its params
+type EchoOperation struct {
+	Params  string
+	Service *EchoService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (op *EchoOperation) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, op, func(ctx context.Context) (string, error) {
+		return op.Service.Echo(ctx, op.Params)
+	})
+}
+
+func (op *EchoOperation) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op *EchoOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "EchoOperation",
+		Params:   op.Params,
+		Metadata: op.Meta,
+	}
+}
+
+func (op *EchoOperation) GetMetadata() *commandment.OperationMetadata { return &op.Meta }
+func (op *EchoOperation) GetLogger() commandment.Logger               { return op.Logger }
+
+func TestCollectorCountsOperationsByStatus(t *testing.T) {
+	collector := metrics.NewCollector()
+	prometheusRegistry := prometheus.NewRegistry()
+	prometheusRegistry.MustRegister(collector)
+
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &EchoService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.AddEventSink(collector)
+
+	for _, input := range []string{"a", "b", "fail"} {
+		op, err := commandment.CreateOperation[*EchoOperation](bus, input)
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		_, _ = op.Execute(context.Background())
+	}
+
+	counts := operationCounts(t, prometheusRegistry)
+	if counts["ok"] != 2 || counts["error"] != 1 {
+		t.Errorf("Expected 2 ok and 1 error, got %v", counts)
+	}
+
+	if n := testutil.CollectAndCount(collector, "commandment_operation_duration_seconds"); n != 2 {
+		t.Errorf("Expected duration series for both statuses, got %d", n)
+	}
+}
+
+func TestCollectorCountsRejectedExecutions(t *testing.T) {
+	collector := metrics.NewCollector()
+	prometheusRegistry := prometheus.NewRegistry()
+	prometheusRegistry.MustRegister(collector)
+
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &EchoService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.AddEventSink(collector)
+
+	op, err := commandment.CreateOperation[*EchoOperation](bus, "a")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := op.Execute(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the execution to be rejected, got %v", err)
+	}
+
+	if counts := operationCounts(t, prometheusRegistry); counts["error"] != 1 {
+		t.Errorf("Expected the rejected execution counted as an error, got %v", counts)
+	}
+}
+
+// operationCounts returns the commandment_operations_total counts by status.
+func operationCounts(t *testing.T, prometheusRegistry *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	families, err := prometheusRegistry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	counts := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "commandment_operations_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["operation_type"] != "EchoOperation" {
+				t.Errorf("Unexpected operation_type %q", labels["operation_type"])
+			}
+			counts[labels["status"]] = metric.GetCounter().GetValue()
+		}
+	}
+	return counts
+}
+
+func TestCollectorUsesBusHistogramBuckets(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &EchoService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithHistogramBuckets("EchoOperation", []float64{0.5, 1}),
+	)
+	collector := metrics.NewCollector(metrics.WithBuckets(bus.HistogramBuckets))
+	prometheusRegistry := prometheus.NewRegistry()
+	prometheusRegistry.MustRegister(collector)
+	bus.AddEventSink(collector)
+
+	op, err := commandment.CreateOperation[*EchoOperation](bus, "a")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	families, err := prometheusRegistry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	var bounds []float64
+	for _, family := range families {
+		if family.GetName() != "commandment_operation_duration_seconds" {
+			continue
+		}
+		for _, bucket := range family.GetMetric()[0].GetHistogram().GetBucket() {
+			bounds = append(bounds, bucket.GetUpperBound())
+		}
+	}
+	if want := bus.HistogramBuckets("EchoOperation"); !slices.Equal(bounds, want) {
+		t.Errorf("Expected exported buckets %v, got %v", want, bounds)
+	}
+}
@@ -0,0 +1,89 @@
+package commandment_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestHistogramBucketsConfiguredPerType(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &SlowService{delay: 20 * time.Millisecond})
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithHistogramBuckets("TestOperation", []float64{10, 0.01, 1}),
+	)
+
+	for range 2 {
+		op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		if _, err := op.Execute(context.Background()); err != nil {
+			t.Fatalf("Operation execution failed: %v", err)
+		}
+	}
+
+	histogram := bus.LatencyHistogram("TestOperation")
+	if want := []float64{0.01, 1, 10}; !slices.Equal(histogram.Buckets, want) {
+		t.Fatalf("Expected configured buckets %v, got %v", want, histogram.Buckets)
+	}
+	if want := []uint64{0, 2, 0, 0}; !slices.Equal(histogram.Counts, want) {
+		t.Errorf("Expected both observations in the (0.01, 1] bucket, got %v", histogram.Counts)
+	}
+	if histogram.Count != 2 || histogram.Sum < 0.04 {
+		t.Errorf("Expected 2 observations summing to at least 40ms, got %d summing to %v", histogram.Count, histogram.Sum)
+	}
+}
+
+func TestHistogramDefaultBucketsForUnconfiguredType(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{},
+		commandment.WithHistogramBuckets("TestOperation", []float64{1}),
+	)
+
+	histogram := bus.LatencyHistogram("OtherOperation")
+	if !slices.Equal(histogram.Buckets, commandment.DefaultLatencyBuckets) {
+		t.Errorf("Expected default buckets, got %v", histogram.Buckets)
+	}
+	if histogram.Count != 0 {
+		t.Errorf("Expected no observations, got %d", histogram.Count)
+	}
+}
+
+func TestHistogramBucketsReportsConfiguredOrDefault(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{},
+		commandment.WithHistogramBuckets("TestOperation", []float64{10, 1}),
+	)
+
+	if buckets := bus.HistogramBuckets("TestOperation"); !slices.Equal(buckets, []float64{1, 10}) {
+		t.Errorf("Expected configured buckets, got %v", buckets)
+	}
+	if buckets := bus.HistogramBuckets("OtherOperation"); !slices.Equal(buckets, commandment.DefaultLatencyBuckets) {
+		t.Errorf("Expected default buckets, got %v", buckets)
+	}
+}
+
+func TestChildHistogramBucketsLeaveParentMetricsIntact(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	parent := commandment.NewOperationBus(registry, &TestLogger{})
+	op, err := commandment.CreateOperation[*TestOperation](parent, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	child := parent.With(commandment.WithHistogramBuckets("TestOperation", []float64{1}))
+
+	if histogram := parent.LatencyHistogram("TestOperation"); histogram.Count != 1 ||
+		!slices.Equal(histogram.Buckets, commandment.DefaultLatencyBuckets) {
+		t.Errorf("Expected the parent's observation and buckets to survive, got %+v", histogram)
+	}
+	if histogram := child.LatencyHistogram("TestOperation"); histogram.Count != 0 || !slices.Equal(histogram.Buckets, []float64{1}) {
+		t.Errorf("Expected an empty child histogram with its own buckets, got %+v", histogram)
+	}
+}
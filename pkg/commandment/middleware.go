@@ -0,0 +1,151 @@
+package commandment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// operationDescriptorKey is the context key for the executing operation's descriptor
+const operationDescriptorKey contextKey = "commandment:operation:descriptor"
+
+// ErrPayloadTooLarge is returned when an operation's serialized params exceed the
+// limit enforced by MaxPayloadSize.
+var ErrPayloadTooLarge = errors.New("operation payload too large")
+
+// ExecuteFunc executes an operation's business logic with an enriched context.
+type ExecuteFunc func(ctx context.Context) (any, error)
+
+// Middleware wraps operation execution, similar to HTTP middleware. A middleware
+// may act before and after calling next, or return without calling it to
+// short-circuit execution. The operation's metadata and descriptor are available
+// from the context via OperationMetadataFromContext and OperationDescriptorFromContext.
+type Middleware func(next ExecuteFunc) ExecuteFunc
+
+// Use registers middleware that wraps the business logic of every operation created
+// by the bus. Middleware runs in registration order: the first registered is the
+// outermost.
+func (b *OperationBus) Use(mw ...Middleware) {
+	b.middlewareMu.Lock()
+	defer b.middlewareMu.Unlock()
+	b.middleware = append(b.middleware, mw...)
+}
+
+// NamedMiddleware names mw so that when it returns without calling next, the
+// execution log identifies it as the middleware that short-circuited the operation.
+func NamedMiddleware(name string, mw Middleware) Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			var called atomic.Bool
+			out, err := mw(func(ctx context.Context) (any, error) {
+				called.Store(true)
+				return next(ctx)
+			})(ctx)
+			if !called.Load() {
+				if exec := executionFromContext(ctx); exec != nil {
+					exec.shortCircuitedBy.CompareAndSwap(nil, &name)
+				}
+			}
+			return out, err
+		}
+	}
+}
+
+// WithMiddleware registers middleware at construction time, as Use does.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(b *OperationBus) {
+		b.Use(mw...)
+	}
+}
+
+// TypedMiddleware wraps execution of operations of type TOp with result type TResult,
+// giving compile-time access to the operation and its result. TOp must be an
+// Operation[TResult], so a result type that doesn't match the operation's fails to
+// compile rather than on every execution.
+type TypedMiddleware[TOp Operation[TResult], TResult any] func(ctx context.Context, op TOp, next func(context.Context) (TResult, error)) (TResult, error)
+
+// UseTyped registers mw on bus, as Use does, for operations of type TOp only.
+// Other operations pass through it untouched.
+func UseTyped[TOp Operation[TResult], TResult any](bus *OperationBus, mw TypedMiddleware[TOp, TResult]) {
+	bus.Use(func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			exec := executionFromContext(ctx)
+			if exec == nil {
+				return next(ctx)
+			}
+			op, ok := exec.op.(TOp)
+			if !ok {
+				return next(ctx)
+			}
+			return mw(ctx, op, func(ctx context.Context) (TResult, error) {
+				return resultAs[TResult](next(ctx))
+			})
+		}
+	})
+}
+
+// chain wraps inner with the bus middleware. It returns inner unchanged on a nil bus.
+func (b *OperationBus) chain(inner ExecuteFunc) ExecuteFunc {
+	if b == nil {
+		return inner
+	}
+	b.middlewareMu.RLock()
+	defer b.middlewareMu.RUnlock()
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		inner = b.middleware[i](inner)
+	}
+	return inner
+}
+
+// withOperationDescriptor adds the executing operation's descriptor to the context
+func withOperationDescriptor(ctx context.Context, descriptor OperationDescriptor) context.Context {
+	return context.WithValue(ctx, operationDescriptorKey, descriptor)
+}
+
+// OperationDescriptorFromContext retrieves the descriptor of the executing operation
+// from context. Returns false outside of execution or for operations without one.
+func OperationDescriptorFromContext(ctx context.Context) (OperationDescriptor, bool) {
+	descriptor, ok := ctx.Value(operationDescriptorKey).(OperationDescriptor)
+	return descriptor, ok
+}
+
+// ServiceTypeFromContext returns the type of the Service field of the executing
+// operation. Returns false outside of execution or for operations without one.
+func ServiceTypeFromContext(ctx context.Context) (string, bool) {
+	exec := executionFromContext(ctx)
+	if exec == nil {
+		return "", false
+	}
+	for _, field := range operationServiceFields(reflect.TypeOf(exec.op)) {
+		if field.Name == "Service" {
+			return field.Type.String(), true
+		}
+	}
+	return "", false
+}
+
+// MaxPayloadSize returns middleware rejecting operations whose JSON-serialized params
+// exceed limit bytes with ErrPayloadTooLarge, protecting downstream systems from
+// oversized requests. Operations without a descriptor are not checked.
+func MaxPayloadSize(limit int) Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			descriptor, ok := OperationDescriptorFromContext(ctx)
+			if !ok {
+				return next(ctx)
+			}
+			params, err := json.Marshal(descriptor.Params)
+			if err != nil {
+				return nil, fmt.Errorf("measuring payload of %s: %w", descriptor.Type, err)
+			}
+			if len(params) > limit {
+				return nil, fmt.Errorf("%w: %s params are %d bytes, limit is %d",
+					ErrPayloadTooLarge, descriptor.Type, len(params), limit)
+			}
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestMiddlewareWrapsExecution(t *testing.T) {
+	service := &CountingService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	var seenType string
+	bus.Use(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			descriptor, _ := commandment.OperationDescriptorFromContext(ctx)
+			seenType = descriptor.Type
+			result, err := next(ctx)
+			if s, ok := result.(string); ok {
+				return strings.ToUpper(s), err
+			}
+			return result, err
+		}
+	})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "wrapped")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if result != "COUNTED: WRAPPED" {
+		t.Errorf("Expected middleware to transform result, got %q", result)
+	}
+	if seenType != "TestOperation" {
+		t.Errorf("Expected middleware to see descriptor type %q, got %q", "TestOperation", seenType)
+	}
+}
+
+func TestMaxPayloadSizeRejectsOversizedParams(t *testing.T) {
+	service := &CountingService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.Use(commandment.MaxPayloadSize(32))
+
+	small, err := commandment.CreateOperation[*TestOperation](bus, "small")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := small.Execute(context.Background()); err != nil {
+		t.Errorf("Expected small payload to be accepted: %v", err)
+	}
+
+	large, err := commandment.CreateOperation[*TestOperation](bus, strings.Repeat("x", 64))
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := large.Execute(context.Background()); !errors.Is(err, commandment.ErrPayloadTooLarge) {
+		t.Errorf("Expected ErrPayloadTooLarge, got %v", err)
+	}
+
+	if calls := service.calls.Load(); calls != 1 {
+		t.Errorf("Expected only the small payload to reach the service, got %d calls", calls)
+	}
+}
+
+// tagging returns middleware appending tag to string results.
+func tagging(tag string) commandment.Middleware {
+	return func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			result, err := next(ctx)
+			if s, ok := result.(string); ok {
+				return s + " [" + tag + "]", err
+			}
+			return result, err
+		}
+	}
+}
+
+func TestChildBusLayersMiddleware(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	parent := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithMiddleware(tagging("parent")))
+	child := parent.With(commandment.WithMiddleware(tagging("auth")))
+	child.Use(tagging("late"))
+
+	execute := func(bus *commandment.OperationBus) string {
+		op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		result, err := op.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("Operation execution failed: %v", err)
+		}
+		return result
+	}
+
+	if got := execute(child); got != "result: x [late] [auth] [parent]" {
+		t.Errorf("Expected child to run parent and child middleware, got %q", got)
+	}
+	if got := execute(parent); got != "result: x [parent]" {
+		t.Errorf("Expected parent to be unaffected by child middleware, got %q", got)
+	}
+}
+
+func TestChildBusDescriptorFactoriesIndependent(t *testing.T) {
+	parent := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+	noopFactory := func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		return "created", nil
+	}
+	parent.RegisterDescriptorFactory("Inherited", noopFactory)
+
+	child := parent.With()
+	child.RegisterDescriptorFactory("ChildOnly", noopFactory)
+
+	if _, err := child.CreateFromDescriptor(commandment.OperationDescriptor{Type: "Inherited"}); err != nil {
+		t.Errorf("Expected child to inherit parent factories: %v", err)
+	}
+	if _, err := parent.CreateFromDescriptor(commandment.OperationDescriptor{Type: "ChildOnly"}); err == nil {
+		t.Error("Expected child registrations not to leak into the parent")
+	}
+}
+
+func TestNamedMiddlewareShortCircuitLogged(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+
+	passThrough := func(next commandment.ExecuteFunc) commandment.ExecuteFunc { return next }
+	errForbidden := errors.New("forbidden")
+	deny := func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			return nil, errForbidden
+		}
+	}
+	bus.Use(
+		commandment.NamedMiddleware("logging", passThrough),
+		commandment.NamedMiddleware("auth", deny),
+		commandment.NamedMiddleware("tenant", passThrough),
+	)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); !errors.Is(err, errForbidden) {
+		t.Fatalf("Expected middleware error, got %v", err)
+	}
+
+	entry, ok := logger.Find("Operation execution failed")
+	if !ok {
+		t.Fatal("Expected execution failure to be logged")
+	}
+	if name, _ := entry.Field("short_circuited_by"); name != "auth" {
+		t.Errorf("Expected short_circuited_by %q, got %v", "auth", name)
+	}
+}
+
+func TestNamedMiddlewareCallingNextFromAnotherGoroutine(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+	bus.Use(commandment.NamedMiddleware("async", func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			type outcome struct {
+				out any
+				err error
+			}
+			done := make(chan outcome)
+			go func() {
+				out, err := next(ctx)
+				done <- outcome{out, err}
+			}()
+			result := <-done
+			return result.out, result.err
+		}
+	}))
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	entry, _ := logger.Find("Operation execution completed")
+	if _, ok := entry.Field("short_circuited_by"); ok {
+		t.Error("Expected no short_circuited_by field when next ran in another goroutine")
+	}
+}
+
+func TestNamedMiddlewarePassThroughNotLogged(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+	bus.Use(commandment.NamedMiddleware("logging", func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return next
+	}))
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	entry, _ := logger.Find("Operation execution completed")
+	if _, ok := entry.Field("short_circuited_by"); ok {
+		t.Error("Expected no short_circuited_by field when business logic ran")
+	}
+}
+
+func TestMiddlewareRunsInRegistrationOrder(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	var trace []string
+	tracing := func(name string) commandment.Middleware {
+		return func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+			return func(ctx context.Context) (any, error) {
+				if meta := commandment.OperationMetadataFromContext(ctx); meta == nil || meta.UUID == "" {
+					t.Errorf("Expected %s middleware to see operation metadata", name)
+				}
+				trace = append(trace, "before "+name)
+				result, err := next(ctx)
+				trace = append(trace, "after "+name)
+				return result, err
+			}
+		}
+	}
+	bus.Use(tracing("first"), tracing("second"))
+	bus.Use(tracing("third"))
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	want := "before first,before second,before third,after third,after second,after first"
+	if got := strings.Join(trace, ","); got != want {
+		t.Errorf("Expected trace %q, got %q", want, got)
+	}
+}
+
+func TestEmptyMiddlewareChainPassesThrough(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.Use()
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if result != "result: x" {
+		t.Errorf("Expected unwrapped result, got %q", result)
+	}
+}
@@ -0,0 +1,34 @@
+package commandment
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResultObserver inspects the result of a successful execution. Returning an error
+// vetoes the result, turning the execution into a failure; this supports
+// post-execution policy such as data-loss prevention.
+type ResultObserver func(ctx context.Context, descriptor OperationDescriptor, result any) error
+
+// WithResultObserver registers observers that run, in order, on every successful
+// result of operations created by the bus, before the result is cached, recorded or
+// returned. The first observer to return an error vetoes the result.
+func WithResultObserver(observers ...ResultObserver) Option {
+	return func(b *OperationBus) {
+		b.observers = append(b.observers, observers...)
+	}
+}
+
+// observeResult runs the bus result observers. It is a no-op on a nil bus.
+func (b *OperationBus) observeResult(ctx context.Context, result any) error {
+	if b == nil || len(b.observers) == 0 {
+		return nil
+	}
+	descriptor, _ := OperationDescriptorFromContext(ctx)
+	for _, observe := range b.observers {
+		if err := observe(ctx, descriptor, result); err != nil {
+			return fmt.Errorf("result rejected by observer: %w", err)
+		}
+	}
+	return nil
+}
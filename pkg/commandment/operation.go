@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"reflect"
+	"strings"
 	"time"
 )
 
@@ -30,6 +31,12 @@ type Operation[TResult any] interface {
 	Descriptor() OperationDescriptor
 }
 
+// Describer is implemented by anything exposing an operation descriptor,
+// including every Operation regardless of its result type.
+type Describer interface {
+	Descriptor() OperationDescriptor
+}
+
 // Command extends Operation for operations that mutate state.
 type Command[TResult any] interface {
 	Operation[TResult]
@@ -40,40 +47,139 @@ type Query[TResult any] interface {
 	Operation[TResult]
 }
 
+// readOnlyOperation is the optional runtime marker of queries. Implementing Query
+// doesn't require it, but only operations with a no-op ReadOnly method are treated
+// as queries by the bus, for example by the query and request caches; the rest are
+// treated as commands.
+type readOnlyOperation interface {
+	ReadOnly()
+}
+
+// isQuery reports whether op is marked as a read-only query.
+func isQuery(op any) bool {
+	_, ok := op.(readOnlyOperation)
+	return ok
+}
+
+// AsAnyOperation adapts an operation with a concrete result type to Operation[any],
+// for APIs such as OperationBus.Cron that handle operations of mixed result types.
+func AsAnyOperation[TResult any](op Operation[TResult]) Operation[any] {
+	return anyOperation[TResult]{op: op}
+}
+
+// anyOperation erases the result type of the wrapped operation.
+type anyOperation[TResult any] struct {
+	op Operation[TResult]
+}
+
+func (a anyOperation[TResult]) Execute(ctx context.Context) (any, error) {
+	return a.op.Execute(ctx)
+}
+
+func (a anyOperation[TResult]) Metadata() OperationMetadata {
+	return a.op.Metadata()
+}
+
+func (a anyOperation[TResult]) Descriptor() OperationDescriptor {
+	return a.op.Descriptor()
+}
+
+func (a anyOperation[TResult]) unwrap() any {
+	return a.op
+}
+
 // OperationMetadata contains timestamps and identifiers for audit trails and debugging.
 type OperationMetadata struct {
 	UUID     string    `json:"uuid"`
 	Created  time.Time `json:"created"`
 	Executed time.Time `json:"executed,omitempty"`
 	Returned time.Time `json:"returned,omitempty"`
+	// CorrelationID links the operation to the request that spawned it, as recorded
+	// with WithCorrelationID when the operation last executed.
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// ParentUUID is the UUID of the operation whose business logic last executed it,
+	// empty for operations executed at the top level.
+	ParentUUID string `json:"parent_uuid,omitempty"`
+	// TraceParent is the W3C traceparent of the trace the operation last executed in.
+	TraceParent string `json:"trace_parent,omitempty"`
+	// Extras holds the context values allowlisted by WithContextSnapshot from the
+	// operation's last execution.
+	Extras map[string]string `json:"extras,omitempty"`
+	// IdempotencyKey, when set, makes executions of the operation idempotent as
+	// WithIdempotencyKey does. A key set with WithIdempotencyKey takes precedence.
+	// Descriptor factories calling RestoreMetadata keep it on recreated operations.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// DurationMs is the duration of the operation's last execution in milliseconds,
+	// recorded when it returned.
+	DurationMs int64 `json:"duration_ms,omitempty"`
+
+	// state links the operation to the bus that created it and its Dependencies.
+	// It is carried with the operation, so it is collected with it.
+	state *operationState
+	// operationType is the short type name recorded when the operation last executed.
+	operationType string
+}
+
+// OperationType returns the short type name of the operation recorded when it last
+// executed, so event sinks can tell completed executions apart by type, rejected
+// ones included. It is empty before the operation first executes.
+func (m OperationMetadata) OperationType() string {
+	return m.operationType
+}
+
+// Duration returns how long the operation's last execution took, or zero if it
+// hasn't been executed or hasn't returned yet.
+func (m OperationMetadata) Duration() time.Duration {
+	if m.Executed.IsZero() || m.Returned.Before(m.Executed) {
+		return 0
+	}
+	return m.Returned.Sub(m.Executed)
 }
 
 // OperationDescriptor provides a serializable representation of an operation
 // including its type, parameters, and metadata for persistence and reconstruction.
 type OperationDescriptor struct {
-	Type     string            `json:"type"`
-	Params   any       `json:"params"`
+	Type string `json:"type"`
+	// Version is the version of the type's params schema; zero for unversioned types.
+	Version  int               `json:"version,omitempty"`
+	Params   any               `json:"params"`
 	Metadata OperationMetadata `json:"metadata"`
 }
 
 // MarshalJSON provides custom JSON serialization for type-safe parameter marshaling.
+// Params fields tagged `commandment:"redact"` are masked in the output; the params
+// held by the descriptor and operation are left intact. The params encoding is
+// memoized like CanonicalParams, so descriptors of operations created repeatedly
+// with identical params encode them once.
 func (od OperationDescriptor) MarshalJSON() ([]byte, error) {
+	params, err := redactedParams(od.Params)
+	if err != nil {
+		return nil, err
+	}
+	return od.marshalWithParams(params)
+}
+
+// marshalWithParams encodes the descriptor with params as its encoded params.
+func (od OperationDescriptor) marshalWithParams(params json.RawMessage) ([]byte, error) {
 	type Alias OperationDescriptor
 	return json.Marshal(&struct {
 		*Alias
 		Params json.RawMessage `json:"params"`
 	}{
 		Alias:  (*Alias)(&od),
-		Params: mustMarshal(od.Params),
+		Params: params,
 	})
 }
 
-func mustMarshal(v any) json.RawMessage {
-	data, err := json.Marshal(v)
+// ParamsJSON returns the descriptor's params encoded as MarshalJSON encodes them,
+// with fields tagged `commandment:"redact"` masked, for codecs that serialize
+// descriptors in another format.
+func (od OperationDescriptor) ParamsJSON() ([]byte, error) {
+	params, err := redactedParams(od.Params)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return data
+	return append([]byte(nil), params...), nil
 }
 
 // Logger defines the interface for structured logging used throughout the operation framework.
@@ -108,6 +214,13 @@ func DependenciesFromContext(ctx context.Context) any {
 	return ctx.Value(dependenciesKey)
 }
 
+// DependenciesAs retrieves dependencies from context as type T.
+// Returns false if no dependencies are available or they are not a T.
+func DependenciesAs[T any](ctx context.Context) (T, bool) {
+	deps, ok := DependenciesFromContext(ctx).(T)
+	return deps, ok
+}
+
 // GetDependencies retrieves dependencies from an operation instance.
 // This is a convenience function for accessing dependencies outside of execution context.
 // During execution, prefer DependenciesFromContext(ctx) for context-based access.
@@ -115,56 +228,64 @@ func GetDependencies(op OperationWithMetadata) any {
 	return GetOperationDependencies(op)
 }
 
-// ExecuteOperation is a context-aware execution wrapper that enriches context with operation metadata
-// before calling the business logic. This allows downstream services to access operation metadata.
-func ExecuteOperation[T any](ctx context.Context, op OperationWithMetadata, businessLogic func(context.Context) (T, error)) (T, error) {
-	op.GetMetadata().Executed = time.Now()
-
-	opTypeName := reflect.TypeOf(op).Elem().Name()
-	logger := op.GetLogger()
-	metadata := op.GetMetadata()
+// OperationWithMetadata is a helper interface for accessing operation metadata and logger.
+// Concrete operations should implement this interface to work with ExecuteOperation.
+type OperationWithMetadata interface {
+	GetMetadata() *OperationMetadata
+	GetLogger() Logger
+}
 
-	// Enrich context with operation metadata
-	ctxWithMeta := WithOperationMetadata(ctx, metadata)
-	
-	// Enrich context with dependencies if available
-	deps := GetOperationDependencies(op)
-	if deps != nil {
-		ctxWithMeta = WithDependencies(ctxWithMeta, deps)
+// operationTypeName returns the short type name of op, dereferencing pointer types
+// and naming the wrapped operation of AsAnyOperation.
+func operationTypeName(op any) string {
+	if erased, ok := op.(interface{ unwrap() any }); ok {
+		op = erased.unwrap()
 	}
+	opType := reflect.TypeOf(op)
+	if opType.Kind() == reflect.Ptr {
+		opType = opType.Elem()
+	}
+	return opType.Name()
+}
 
-	logger.Info("Operation execution started",
-		"operation_type", opTypeName,
-		"operation_id", metadata.UUID,
-	)
-
-	result, err := businessLogic(ctxWithMeta)
-	op.GetMetadata().Returned = time.Now()
+// WithIDGenerator makes the bus identify the operations it creates with IDs from
+// generate, such as a UUIDv7 library for sortable IDs, instead of random hex IDs.
+func WithIDGenerator(generate func() string) Option {
+	return func(b *OperationBus) {
+		b.idGenerator = generate
+	}
+}
 
-	duration := op.GetMetadata().Returned.Sub(op.GetMetadata().Executed)
-	if err != nil {
-		logger.Error("Operation execution failed",
-			"operation_type", opTypeName,
-			"operation_id", metadata.UUID,
-			"duration_ms", duration.Milliseconds(),
-			"error", err,
-		)
-	} else {
-		logger.Info("Operation execution completed",
-			"operation_type", opTypeName,
-			"operation_id", metadata.UUID,
-			"duration_ms", duration.Milliseconds(),
-		)
+// newID returns the ID for a new operation.
+func (b *OperationBus) newID() string {
+	if b.idGenerator != nil {
+		return b.idGenerator()
 	}
+	return generateUUID()
+}
 
-	return result, err
+// QualifiedTypeName returns the package-qualified name of v's type, such as
+// "github.com/acme/lists.CreateListCommand", for use as a descriptor type that
+// doesn't collide across packages. Pointers are dereferenced, so an operation and
+// a pointer to it have the same name; for unnamed types it returns the type's
+// string form.
+func QualifiedTypeName(v any) string {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
 }
 
-// OperationWithMetadata is a helper interface for accessing operation metadata and logger.
-// Concrete operations should implement this interface to work with ExecuteOperation.
-type OperationWithMetadata interface {
-	GetMetadata() *OperationMetadata
-	GetLogger() Logger
+// shortTypeName strips the package qualification from a QualifiedTypeName.
+func shortTypeName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
 }
 
 func generateUUID() string {
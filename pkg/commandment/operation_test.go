@@ -2,7 +2,14 @@ package commandment_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"weak"
 
 	"github.com/davidlee/commandment/pkg/commandment"
 )
@@ -15,6 +22,73 @@ func (l *TestLogger) Warn(msg string, keysAndValues ...any)  {}
 func (l *TestLogger) Error(msg string, keysAndValues ...any) {}
 func (l *TestLogger) Debug(msg string, keysAndValues ...any) {}
 
+// LogEntry is a single record captured by RecordingLogger
+type LogEntry struct {
+	Level         string
+	Msg           string
+	KeysAndValues []any
+}
+
+// Field returns the value logged under key, if present
+func (e LogEntry) Field(key string) (any, bool) {
+	for i := 0; i+1 < len(e.KeysAndValues); i += 2 {
+		if e.KeysAndValues[i] == key {
+			return e.KeysAndValues[i+1], true
+		}
+	}
+	return nil, false
+}
+
+// RecordingLogger captures log entries for assertions
+type RecordingLogger struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+func (l *RecordingLogger) record(level, msg string, keysAndValues []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, LogEntry{Level: level, Msg: msg, KeysAndValues: keysAndValues})
+}
+
+func (l *RecordingLogger) Info(msg string, keysAndValues ...any) {
+	l.record("info", msg, keysAndValues)
+}
+func (l *RecordingLogger) Warn(msg string, keysAndValues ...any) {
+	l.record("warn", msg, keysAndValues)
+}
+func (l *RecordingLogger) Error(msg string, keysAndValues ...any) {
+	l.record("error", msg, keysAndValues)
+}
+func (l *RecordingLogger) Debug(msg string, keysAndValues ...any) {
+	l.record("debug", msg, keysAndValues)
+}
+
+// Entries returns the captured entries at the given level
+func (l *RecordingLogger) Entries(level string) []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var entries []LogEntry
+	for _, entry := range l.entries {
+		if entry.Level == level {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Find returns the first captured entry with the given message
+func (l *RecordingLogger) Find(msg string) (LogEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, entry := range l.entries {
+		if entry.Msg == msg {
+			return entry, true
+		}
+	}
+	return LogEntry{}, false
+}
+
 // Example service interface for testing
 type TestService interface {
 	DoSomething(ctx context.Context, input string) (string, error)
@@ -108,3 +182,184 @@ func TestServiceRegistry(t *testing.T) {
 		t.Error("Retrieved service should be the same instance as registered")
 	}
 }
+
+func TestRegisterServiceAsResolvesInterfaceAndConcreteType(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	mockService := &MockTestService{}
+	commandment.RegisterServiceAs[TestService](registry, mockService)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if op.Service != mockService {
+		t.Errorf("Expected interface-typed Service field to be injected, got %v", op.Service)
+	}
+	if concrete, ok := commandment.TryGetService[*MockTestService](registry); !ok || concrete != mockService {
+		t.Errorf("Expected service to resolve by its concrete type, got %v, %v", concrete, ok)
+	}
+}
+
+func TestInterfaceServiceFieldResolvesConcreteRegistration(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	mockService := &MockTestService{}
+	commandment.RegisterService(registry, mockService)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Expected the only TestService implementation to resolve: %v", err)
+	}
+	if op.Service != mockService {
+		t.Errorf("Expected concrete registration to be injected, got %v", op.Service)
+	}
+
+	commandment.RegisterService(registry, &CountingService{})
+	if _, err := commandment.CreateOperation[*TestOperation](bus, "x"); !errors.Is(err, commandment.ErrServiceNotRegistered) {
+		t.Errorf("Expected ambiguous implementations not to resolve, got %v", err)
+	}
+}
+
+func TestIDGeneratorAssignsOperationIDs(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	next := 0
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithIDGenerator(func() string {
+		next++
+		return fmt.Sprintf("op-%d", next)
+	}))
+
+	for _, want := range []string{"op-1", "op-2"} {
+		op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		if op.Meta.UUID != want {
+			t.Errorf("Expected UUID %q, got %q", want, op.Meta.UUID)
+		}
+	}
+}
+
+func TestTryGetService(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	if _, ok := commandment.TryGetService[TestService](registry); ok {
+		t.Error("Expected TryGetService to report an unregistered service")
+	}
+
+	mockService := &MockTestService{}
+	commandment.RegisterService[TestService](registry, mockService)
+	retrieved, ok := commandment.TryGetService[TestService](registry)
+	if !ok || retrieved != mockService {
+		t.Errorf("Expected registered service, got %v, %v", retrieved, ok)
+	}
+}
+
+func TestCreateOperationWithUnregisteredService(t *testing.T) {
+	bus := commandment.NewOperationBus(commandment.NewServiceRegistry(), &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if !errors.Is(err, commandment.ErrServiceNotRegistered) {
+		t.Fatalf("Expected ErrServiceNotRegistered, got %v", err)
+	}
+	if op != nil {
+		t.Errorf("Expected no operation, got %v", op)
+	}
+	for _, name := range []string{"TestService", "TestOperation"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected error to name %s, got %q", name, err)
+		}
+	}
+}
+
+// Test operation taking optional pointer params
+type OptionalParamsOperation struct {
+	Params  *string
+	Service TestService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (op *OptionalParamsOperation) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, op, func(ctx context.Context) (string, error) {
+		if op.Params == nil {
+			return op.Service.DoSomething(ctx, "default")
+		}
+		return op.Service.DoSomething(ctx, *op.Params)
+	})
+}
+
+func (op *OptionalParamsOperation) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op *OptionalParamsOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "OptionalParamsOperation",
+		Params:   op.Params,
+		Metadata: op.Meta,
+	}
+}
+
+func (op *OptionalParamsOperation) GetMetadata() *commandment.OperationMetadata { return &op.Meta }
+func (op *OptionalParamsOperation) GetLogger() commandment.Logger               { return op.Logger }
+
+func TestCreateOperationWithNilParams(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*OptionalParamsOperation](bus, nil)
+	if err != nil {
+		t.Fatalf("Failed to create operation with nil params: %v", err)
+	}
+	if op.Params != nil {
+		t.Errorf("Expected nil Params, got %v", *op.Params)
+	}
+
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if result != "result: default" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+
+	if _, err := json.Marshal(op.Descriptor()); err != nil {
+		t.Errorf("Failed to marshal descriptor with nil params: %v", err)
+	}
+}
+
+func TestCreateOperationWithMismatchedParams(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	if _, err := commandment.CreateOperation[*TestOperation](bus, 42); err == nil {
+		t.Error("Expected error for params of the wrong type")
+	}
+}
+
+func TestExecutedOperationsAreGarbageCollected(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBusWithDefaultDependencies(registry, &TestLogger{}, "deps")
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if deps := commandment.GetOperationDependencies(op); deps != "deps" {
+		t.Fatalf("Expected the operation's dependencies, got %v", deps)
+	}
+	ref := weak.Make(op)
+	op = nil
+
+	runtime.GC()
+	if ref.Value() != nil {
+		t.Error("Expected the bus to hold no reference to an operation it created")
+	}
+}
@@ -0,0 +1,64 @@
+package commandment
+
+import (
+	"errors"
+	"strings"
+)
+
+// CodeValidation is the OperationError code for domain validation failures.
+const CodeValidation = "validation"
+
+// OperationError is a domain error returned by an operation's business logic, such
+// as a validation failure or a conflict, as opposed to a failure to execute it.
+// ExecuteOperation logs it as a warning, with its code, instead of as an execution
+// failure; it doesn't trip circuit breakers and is retried only if Retryable.
+type OperationError struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Details   []ValidationError `json:"details,omitempty"`
+	Retryable bool              `json:"retryable"`
+}
+
+func (e *OperationError) Error() string {
+	if len(e.Details) == 0 {
+		return e.Code + ": " + e.Message
+	}
+	details := make([]string, 0, len(e.Details))
+	for _, detail := range e.Details {
+		details = append(details, detail.Field+": "+detail.Message)
+	}
+	return e.Code + ": " + e.Message + " (" + strings.Join(details, "; ") + ")"
+}
+
+// AsOperationError returns the OperationError in err's chain, if any.
+func AsOperationError(err error) (*OperationError, bool) {
+	var opErr *OperationError
+	ok := errors.As(err, &opErr)
+	return opErr, ok
+}
+
+// ValidationFailed returns an OperationError with CodeValidation carrying the given
+// field-level validation errors.
+func ValidationFailed(details ...ValidationError) *OperationError {
+	return &OperationError{Code: CodeValidation, Message: "validation failed", Details: details}
+}
+
+// Err returns the result's validation errors as a ValidationFailed error, or nil if
+// it is valid, for operations that return validation failures instead of embedding
+// them in their result.
+func (v Validated[T]) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return ValidationFailed(v.Errors...)
+}
+
+// EmbedValidationErrors moves a validation OperationError returned with value into
+// a Validated result, for operations that embed validation failures in their
+// result instead of returning them. Other errors are returned unchanged.
+func EmbedValidationErrors[T any](value T, err error) (Validated[T], error) {
+	if opErr, ok := AsOperationError(err); ok && opErr.Code == CodeValidation {
+		return Validated[T]{Value: value, Errors: opErr.Details}, nil
+	}
+	return Validated[T]{Value: value}, err
+}
@@ -0,0 +1,95 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service rejecting empty input with a domain validation error
+type DomainValidatingService struct {
+	calls int
+}
+
+func (s *DomainValidatingService) DoSomething(ctx context.Context, input string) (string, error) {
+	s.calls++
+	if input == "" {
+		return "", commandment.ValidationFailed(commandment.ValidationError{Field: "input", Message: "input is required"})
+	}
+	return "ok: " + input, nil
+}
+
+func TestReturnedOperationErrorLoggedAsDomainError(t *testing.T) {
+	logger := &RecordingLogger{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &DomainValidatingService{})
+	bus := commandment.NewOperationBus(registry, logger)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	_, err = op.Execute(context.Background())
+	opErr, ok := commandment.AsOperationError(err)
+	if !ok || opErr.Code != commandment.CodeValidation || len(opErr.Details) != 1 || opErr.Details[0].Field != "input" {
+		t.Fatalf("Expected validation OperationError on input, got %v", err)
+	}
+
+	entry, ok := logger.Find("Operation returned domain error")
+	if !ok || entry.Level != "warn" {
+		t.Fatalf("Expected domain error warning, got %+v", entry)
+	}
+	if code, _ := entry.Field("error_code"); code != commandment.CodeValidation {
+		t.Errorf("Expected error_code %q, got %v", commandment.CodeValidation, code)
+	}
+	if _, ok := logger.Find("Operation execution failed"); ok {
+		t.Error("Expected domain error not to be logged as an execution failure")
+	}
+}
+
+func TestValidationErrorsConvertBetweenStyles(t *testing.T) {
+	embedded := commandment.Validated[string]{
+		Value:  "draft",
+		Errors: []commandment.ValidationError{{Field: "Title", Message: "Title is required"}},
+	}
+
+	err := embedded.Err()
+	opErr, ok := commandment.AsOperationError(err)
+	if !ok || opErr.Code != commandment.CodeValidation || opErr.Details[0] != embedded.Errors[0] {
+		t.Fatalf("Expected embedded errors returned as a validation OperationError, got %v", err)
+	}
+	if valid := (commandment.Validated[string]{Value: "ok"}); valid.Err() != nil {
+		t.Error("Expected a valid result to have no error")
+	}
+
+	back, err := commandment.EmbedValidationErrors("draft", err)
+	if err != nil || back.Valid() || back.Errors[0] != embedded.Errors[0] || back.Value != "draft" {
+		t.Errorf("Expected returned validation error embedded in the result, got %+v, %v", back, err)
+	}
+	transport := errors.New("connection reset")
+	if _, err := commandment.EmbedValidationErrors("", transport); err != transport {
+		t.Errorf("Expected other errors returned unchanged, got %v", err)
+	}
+}
+
+func TestOperationErrorRetriedOnlyIfRetryable(t *testing.T) {
+	service := &DomainValidatingService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	policy := commandment.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, RetryableFunc: commandment.IsRetryable}
+	if _, err := commandment.WithRetry(op, policy).Execute(context.Background()); err == nil {
+		t.Fatal("Expected validation failure")
+	}
+	if service.calls != 1 {
+		t.Errorf("Expected non-retryable domain error not to be retried, got %d calls", service.calls)
+	}
+}
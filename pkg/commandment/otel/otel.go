@@ -0,0 +1,73 @@
+// Package otel traces commandment operation executions with OpenTelemetry.
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// instrumentationName identifies this package as the source of its spans.
+const instrumentationName = "github.com/davidlee/commandment/pkg/commandment/otel"
+
+// NewTracer returns a commandment.Tracer starting spans from provider, or the global
+// provider if nil; install it with commandment.WithTracer. The span is named after
+// the operation type and carries operation_id and service_type attributes; a failed
+// execution, including one rejected before it starts, records its error and sets an
+// error status. The span is current in the context passed on to the middleware and
+// business logic, so spans started by services nest under it.
+func NewTracer(provider trace.TracerProvider) commandment.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// tracer adapts an OpenTelemetry tracer to commandment.Tracer.
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, name string) (context.Context, commandment.Span) {
+	ctx, s := t.tracer.Start(ctx, spanName(ctx, name), trace.WithAttributes(attributes(ctx)...))
+	return ctx, span{span: s}
+}
+
+// span adapts an OpenTelemetry span to commandment.Span.
+type span struct {
+	span trace.Span
+}
+
+func (s span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// spanName returns the executing operation's descriptor type, or name for
+// operations without a descriptor.
+func spanName(ctx context.Context, name string) string {
+	if descriptor, ok := commandment.OperationDescriptorFromContext(ctx); ok {
+		return descriptor.Type
+	}
+	return name
+}
+
+// attributes returns the span attributes identifying the executing operation.
+func attributes(ctx context.Context) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if meta := commandment.OperationMetadataFromContext(ctx); meta != nil {
+		attrs = append(attrs, attribute.String("operation_id", meta.UUID))
+	}
+	if serviceType, ok := commandment.ServiceTypeFromContext(ctx); ok {
+		attrs = append(attrs, attribute.String("service_type", serviceType))
+	}
+	return attrs
+}
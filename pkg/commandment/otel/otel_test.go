@@ -0,0 +1,160 @@
+package otel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+	commandmentotel "github.com/davidlee/commandment/pkg/commandment/otel"
+)
+
+// Test logger discarding everything
+type TestLogger struct{}
+
+func (l *TestLogger) Info(msg string, keysAndValues ...any)  {}
+func (l *TestLogger) Warn(msg string, keysAndValues ...any)  {}
+func (l *TestLogger) Error(msg string, keysAndValues ...any) {}
+func (l *TestLogger) Debug(msg string, keysAndValues ...any) {}
+
+// Service starting its own span, or failing
+type GreetingService struct {
+	tracer trace.Tracer
+	fail   bool
+}
+
+func (s *GreetingService) Greet(ctx context.Context, name string) (string, error) {
+	_, span := s.tracer.Start(ctx, "GreetingService.Greet")
+	defer span.End()
+	if s.fail {
+		return "", errors.New("greeting failed")
+	}
+	return "hello " + name, nil
+}
+
+// Test operation greeting by name
+type GreetOperation struct {
+	Params  string
+	Service *GreetingService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (op *GreetOperation) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, op, func(ctx context.Context) (string, error) {
+		return op.Service.Greet(ctx, op.Params)
+	})
+}
+
+func (op *GreetOperation) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op *GreetOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "GreetOperation",
+		Params:   op.Params,
+		Metadata: op.Meta,
+	}
+}
+
+func (op *GreetOperation) GetMetadata() *commandment.OperationMetadata { return &op.Meta }
+func (op *GreetOperation) GetLogger() commandment.Logger               { return op.Logger }
+
+func executeTraced(t *testing.T, fail bool) (*GreetOperation, tracetest.SpanStubs, error) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &GreetingService{tracer: provider.Tracer("test"), fail: fail})
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithTracer(commandmentotel.NewTracer(provider), nil))
+
+	op, err := commandment.CreateOperation[*GreetOperation](bus, "world")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	_, err = op.Execute(context.Background())
+	return op, exporter.GetSpans(), err
+}
+
+func attributeValue(span tracetest.SpanStub, key string) (string, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == attribute.Key(key) {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func TestTracerSpansExecution(t *testing.T) {
+	op, spans, err := executeTraced(t, false)
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if len(spans) != 2 {
+		t.Fatalf("Expected operation and service spans, got %d", len(spans))
+	}
+	service, operation := spans[0], spans[1]
+
+	if operation.Name != "GreetOperation" {
+		t.Errorf("Expected span named %q, got %q", "GreetOperation", operation.Name)
+	}
+	if id, _ := attributeValue(operation, "operation_id"); id != op.Meta.UUID {
+		t.Errorf("Expected operation_id %q, got %q", op.Meta.UUID, id)
+	}
+	if serviceType, _ := attributeValue(operation, "service_type"); serviceType != "*otel_test.GreetingService" {
+		t.Errorf("Expected service_type %q, got %q", "*otel_test.GreetingService", serviceType)
+	}
+	if service.Parent.SpanID() != operation.SpanContext.SpanID() {
+		t.Error("Expected service span to nest under the operation span")
+	}
+	if operation.Status.Code != codes.Unset {
+		t.Errorf("Expected unset status, got %v", operation.Status.Code)
+	}
+}
+
+func TestTracerRecordsErrorStatus(t *testing.T) {
+	_, spans, err := executeTraced(t, true)
+	if err == nil {
+		t.Fatal("Expected execution to fail")
+	}
+	operation := spans[len(spans)-1]
+	if operation.Status.Code != codes.Error || operation.Status.Description != err.Error() {
+		t.Errorf("Expected error status %q, got %v %q", err, operation.Status.Code, operation.Status.Description)
+	}
+	if len(operation.Events) == 0 || operation.Events[0].Name != "exception" {
+		t.Error("Expected the error to be recorded on the span")
+	}
+}
+
+func TestTracerRecordsRejectedExecutionAsError(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &GreetingService{tracer: provider.Tracer("test")})
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithTracer(commandmentotel.NewTracer(provider), nil))
+	op, err := commandment.CreateOperation[*GreetOperation](bus, "world")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := op.Execute(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the execution to be rejected, got %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected only the operation span, got %d", len(spans))
+	}
+	if spans[0].Name != "GreetOperation" || spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected an error span for GreetOperation, got %q with %v", spans[0].Name, spans[0].Status.Code)
+	}
+}
@@ -0,0 +1,125 @@
+package commandment
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// maxMemoizedParams bounds the params encoding cache. When full it is reset
+// rather than evicting entry by entry; params seen repeatedly are re-added quickly.
+const maxMemoizedParams = 1024
+
+// paramsEncoding identifies an encoding of descriptor params held by paramsMemo.
+type paramsEncoding int
+
+const (
+	// encodingCanonical is canonicalJSON, used for hashing and cache keys.
+	encodingCanonical paramsEncoding = iota
+	// encodingRedacted is the params as a serialized descriptor holds them.
+	encodingRedacted
+)
+
+// paramsMemoKey identifies a memoized encoding by params value.
+type paramsMemoKey struct {
+	encoding paramsEncoding
+	params   any
+}
+
+// paramsMemo caches encodings of descriptor params by value, so operations created
+// repeatedly with identical params are only encoded once.
+var paramsMemo = struct {
+	mu      sync.RWMutex
+	entries map[paramsMemoKey][]byte
+}{entries: make(map[paramsMemoKey][]byte)}
+
+// memoizableTypes caches whether values of a type can serve as paramsMemo keys.
+var memoizableTypes sync.Map // map[reflect.Type]bool
+
+// CanonicalParams returns the descriptor's params encoded as JSON with object keys
+// sorted. Encodings of immutable params values (those free of pointers, maps,
+// slices, interfaces and floats) are memoized, so repeated calls for equal params
+// are cheap.
+func (od OperationDescriptor) CanonicalParams() ([]byte, error) {
+	params, err := canonicalParams(od.Params)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), params...), nil
+}
+
+// canonicalParams is canonicalJSON memoized by params value. The returned slice is
+// shared and must not be modified.
+func canonicalParams(v any) ([]byte, error) {
+	return memoizedParams(v, encodingCanonical, canonicalJSON)
+}
+
+// redactedParams is the JSON encoding of params with fields tagged
+// `commandment:"redact"` masked, memoized by params value. The returned slice is
+// shared and must not be modified.
+func redactedParams(v any) ([]byte, error) {
+	return memoizedParams(v, encodingRedacted, func(v any) ([]byte, error) {
+		return json.Marshal(redactTagged(v))
+	})
+}
+
+// memoizedParams returns the encoding of v, calling encode only for params values
+// not seen before.
+func memoizedParams(v any, encoding paramsEncoding, encode func(any) ([]byte, error)) ([]byte, error) {
+	if v == nil || !memoizable(reflect.TypeOf(v)) {
+		return encode(v)
+	}
+	key := paramsMemoKey{encoding: encoding, params: v}
+
+	paramsMemo.mu.RLock()
+	data, ok := paramsMemo.entries[key]
+	paramsMemo.mu.RUnlock()
+	if ok {
+		return data, nil
+	}
+
+	data, err := encode(v)
+	if err != nil {
+		return nil, err
+	}
+	paramsMemo.mu.Lock()
+	if len(paramsMemo.entries) >= maxMemoizedParams {
+		clear(paramsMemo.entries)
+	}
+	paramsMemo.entries[key] = data
+	paramsMemo.mu.Unlock()
+	return data, nil
+}
+
+// memoizable reports whether values of t are comparable and cannot change after
+// being used as a cache key, i.e. they hold no references to mutable data, and
+// whether equal values always encode alike. Floating-point values don't: +0 and -0
+// compare equal but encode differently, and NaN never matches a key.
+func memoizable(t reflect.Type) bool {
+	if cached, ok := memoizableTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := isImmutableValueType(t)
+	memoizableTypes.Store(t, result)
+	return result
+}
+
+func isImmutableValueType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Array:
+		return isImmutableValueType(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !isImmutableValueType(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,150 @@
+package commandment_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+type memoParams struct {
+	Title    string
+	Priority int
+	Tags     [2]string
+}
+
+func TestCanonicalParamsMatchesUncachedEncoding(t *testing.T) {
+	params := memoParams{Title: "Inbox", Priority: 2, Tags: [2]string{"a", "b"}}
+	descriptor := commandment.OperationDescriptor{Type: "CreateList", Params: params}
+
+	want, err := commandment.CanonicalJSONUncached(params)
+	if err != nil {
+		t.Fatalf("Uncached encoding failed: %v", err)
+	}
+	for range 2 {
+		got, err := descriptor.CanonicalParams()
+		if err != nil {
+			t.Fatalf("CanonicalParams failed: %v", err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestCanonicalParamsReturnsIndependentCopies(t *testing.T) {
+	descriptor := commandment.OperationDescriptor{Type: "CreateList", Params: memoParams{Title: "Copy"}}
+
+	first, _ := descriptor.CanonicalParams()
+	first[0] = 'X'
+
+	second, _ := descriptor.CanonicalParams()
+	if second[0] != '{' {
+		t.Errorf("Expected memoized encoding to be unaffected by caller changes, got %s", second)
+	}
+}
+
+func TestCanonicalParamsReflectsMutableParams(t *testing.T) {
+	params := &memoParams{Title: "Before"}
+	descriptor := commandment.OperationDescriptor{Type: "CreateList", Params: params}
+
+	before, _ := descriptor.CanonicalParams()
+	params.Title = "After"
+	after, _ := descriptor.CanonicalParams()
+
+	if string(before) == string(after) {
+		t.Errorf("Expected pointer params not to be memoized, got %s both times", after)
+	}
+}
+
+func TestHashUnchangedByMemoization(t *testing.T) {
+	params := memoParams{Title: "Inbox"}
+	first, err := commandment.OperationDescriptor{Type: "CreateList", Params: params}.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	second, _ := commandment.OperationDescriptor{Type: "CreateList", Params: params}.Hash()
+	generic, _ := commandment.OperationDescriptor{
+		Type:   "CreateList",
+		Params: map[string]any{"Title": "Inbox", "Priority": 0, "Tags": []any{"", ""}},
+	}.Hash()
+
+	if first != second || first != generic {
+		t.Errorf("Expected equal hashes, got %s, %s and %s", first, second, generic)
+	}
+}
+
+func TestCanonicalParamsDistinguishesSignedZero(t *testing.T) {
+	type weighted struct{ Weight float64 }
+	positive, _ := commandment.OperationDescriptor{Params: weighted{Weight: 0}}.CanonicalParams()
+	negative, _ := commandment.OperationDescriptor{Params: weighted{Weight: math.Copysign(0, -1)}}.CanonicalParams()
+
+	if string(positive) != `{"Weight":0}` || string(negative) != `{"Weight":-0}` {
+		t.Errorf("Expected +0 and -0 to encode as themselves, got %s and %s", positive, negative)
+	}
+}
+
+func TestDescriptorJSONUnchangedByMemoization(t *testing.T) {
+	params := memoParams{Title: "Inbox", Priority: 2}
+	op := describedOperation{params: params}
+
+	want, err := json.Marshal(map[string]any{"type": "CreateList", "params": params, "metadata": commandment.OperationMetadata{}})
+	if err != nil {
+		t.Fatalf("Reference encoding failed: %v", err)
+	}
+	for range 2 {
+		got, err := json.Marshal(op.Descriptor())
+		if err != nil {
+			t.Fatalf("Descriptor encoding failed: %v", err)
+		}
+		if !jsonEqual(t, got, want) {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+// Operation describing itself with fixed params
+type describedOperation struct {
+	params any
+}
+
+func (op describedOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "CreateList", Params: op.params}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var av, bv any
+	if err := json.Unmarshal(a, &av); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", b, err)
+	}
+	ac, _ := json.Marshal(av)
+	bc, _ := json.Marshal(bv)
+	return string(ac) == string(bc)
+}
+
+// BenchmarkDescriptorJSON serializes Descriptor() output repeatedly. Pointer params
+// aren't memoized, so they measure the uncached encoding of the same values.
+func BenchmarkDescriptorJSON(b *testing.B) {
+	params := memoParams{Title: "Inbox", Priority: 2, Tags: [2]string{"a", "b"}}
+
+	for _, bench := range []struct {
+		name string
+		op   describedOperation
+	}{
+		{"uncached", describedOperation{params: &params}},
+		{"memoized", describedOperation{params: params}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := json.Marshal(bench.op.Descriptor()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
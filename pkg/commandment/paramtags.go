@@ -0,0 +1,203 @@
+package commandment
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// tagName is the struct tag key read from params fields, whose comma-separated
+// options are "default=<value>", "logfield" and "redact", and from operation fields, where
+// "inject" requests a registered service. A default containing commas is quoted with
+// single quotes, as in `commandment:"default='a,b',logfield"`.
+const tagName = "commandment"
+
+// applyParamDefaults fills zero-valued fields of struct params from their
+// `commandment:"default=..."` tags. Params are copied rather than modified in
+// place; params without tagged fields are returned unchanged.
+func applyParamDefaults(params any) (any, error) {
+	value := reflect.ValueOf(params)
+	isPtr := value.Kind() == reflect.Pointer
+	if isPtr {
+		if value.IsNil() {
+			return params, nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct || !hasParamDefaults(value.Type()) {
+		return params, nil
+	}
+
+	filled := reflect.New(value.Type())
+	filled.Elem().Set(value)
+	if err := setParamDefaults(filled.Elem()); err != nil {
+		return nil, err
+	}
+	if isPtr {
+		return filled.Interface(), nil
+	}
+	return filled.Elem().Interface(), nil
+}
+
+// hasParamDefaults reports whether t has fields declaring defaults, including
+// fields whose tag is malformed, so that setParamDefaults reports them.
+func hasParamDefaults(t reflect.Type) bool {
+	for i := range t.NumField() {
+		if _, ok, err := paramDefault(t.Field(i)); ok || err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func setParamDefaults(v reflect.Value) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		def, ok, err := paramDefault(t.Field(i))
+		if err != nil {
+			return fmt.Errorf("default for %s.%s: %w", t.Name(), t.Field(i).Name, err)
+		}
+		field := v.Field(i)
+		if !ok || !field.CanSet() || !field.IsZero() {
+			continue
+		}
+		if err := setFromString(field, def); err != nil {
+			return fmt.Errorf("default for %s.%s: %w", t.Name(), t.Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+// paramDefault returns the default declared in a field's commandment tag, without
+// the quotes of a quoted default.
+func paramDefault(field reflect.StructField) (string, bool, error) {
+	options, err := tagOptions(field.Tag.Get(tagName))
+	for _, option := range options {
+		if def, ok := strings.CutPrefix(option, "default="); ok {
+			if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+				def = def[1 : len(def)-1]
+			}
+			return def, true, nil
+		}
+	}
+	return "", false, err
+}
+
+// hasTagOption reports whether a field's commandment tag includes option.
+func hasTagOption(field reflect.StructField, option string) bool {
+	options, _ := tagOptions(field.Tag.Get(tagName))
+	return slices.Contains(options, option)
+}
+
+// tagOptions splits a commandment tag into its options at commas outside single
+// quotes. For a tag with an unterminated quote it returns the options before the
+// quoted one and an error.
+func tagOptions(tag string) ([]string, error) {
+	var options []string
+	start, quoted := 0, false
+	for i := range len(tag) {
+		switch {
+		case tag[i] == '\'':
+			quoted = !quoted
+		case tag[i] == ',' && !quoted:
+			options = append(options, tag[start:i])
+			start = i + 1
+		}
+	}
+	if quoted {
+		return options, fmt.Errorf("unterminated quote in tag %q", tag)
+	}
+	return append(options, tag[start:]), nil
+}
+
+// paramLogFields returns key/value pairs for struct params fields tagged
+// `commandment:"logfield"`, keyed by the snake_case field name.
+func paramLogFields(params any) []any {
+	value := reflect.ValueOf(params)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []any
+	t := value.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.IsExported() && hasTagOption(field, "logfield") {
+			fields = append(fields, snakeCase(field.Name), value.Field(i).Interface())
+		}
+	}
+	return fields
+}
+
+// snakeCase converts a Go identifier such as RootReference to root_reference.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && !unicode.IsUpper(rune(name[i-1]))
+			nextLower := i+1 < len(name) && unicode.IsLower(rune(name[i+1]))
+			if i > 0 && (prevLower || nextLower) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setFromString parses s into v according to v's kind.
+func setFromString(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package commandment_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Params declaring defaults for several field types
+type DefaultedParams struct {
+	Name    string        `commandment:"default=anonymous"`
+	Retries int           `commandment:"default=3"`
+	Ratio   float64       `commandment:"default=0.5"`
+	Enabled bool          `commandment:"default=true"`
+	Timeout time.Duration `commandment:"default=5s"`
+	Plain   int
+}
+
+// Params with a default that doesn't parse
+type BadDefaultParams struct {
+	Count int `commandment:"default=many"`
+}
+
+// Params with a quoted default containing commas
+type ListDefaultParams struct {
+	Tags    string `commandment:"default='a,b,c',logfield"`
+	Literal string `commandment:"default=x"`
+}
+
+// Params with a default whose quote isn't closed
+type UnterminatedDefaultParams struct {
+	Tags string `commandment:"default='a,b"`
+}
+
+// Test operation generic over its params type
+type ParamsOperation[P any] struct {
+	Params  P
+	Service TestService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (op *ParamsOperation[P]) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, op, func(ctx context.Context) (string, error) {
+		return op.Service.DoSomething(ctx, "params")
+	})
+}
+
+func (op *ParamsOperation[P]) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op *ParamsOperation[P]) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "ParamsOperation",
+		Params:   op.Params,
+		Metadata: op.Meta,
+	}
+}
+
+func (op *ParamsOperation[P]) GetMetadata() *commandment.OperationMetadata { return &op.Meta }
+func (op *ParamsOperation[P]) GetLogger() commandment.Logger               { return op.Logger }
+
+func newDefaultsBus() *commandment.OperationBus {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	return commandment.NewOperationBus(registry, &TestLogger{})
+}
+
+func TestParamDefaultsFillZeroFields(t *testing.T) {
+	bus := newDefaultsBus()
+
+	op, err := commandment.CreateOperation[*ParamsOperation[DefaultedParams]](bus, DefaultedParams{Retries: 7})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	expected := DefaultedParams{
+		Name:    "anonymous",
+		Retries: 7,
+		Ratio:   0.5,
+		Enabled: true,
+		Timeout: 5 * time.Second,
+	}
+	if op.Params != expected {
+		t.Errorf("Expected %+v, got %+v", expected, op.Params)
+	}
+}
+
+func TestParamDefaultsDoNotModifyCallerParams(t *testing.T) {
+	bus := newDefaultsBus()
+	params := &DefaultedParams{}
+
+	op, err := commandment.CreateOperation[*ParamsOperation[*DefaultedParams]](bus, params)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+
+	if op.Params.Retries != 3 {
+		t.Errorf("Expected pointer params to receive defaults, got %+v", op.Params)
+	}
+	if params.Retries != 0 {
+		t.Errorf("Expected caller's params to be left unchanged, got %+v", params)
+	}
+}
+
+func TestParamDefaultsInvalidDefault(t *testing.T) {
+	bus := newDefaultsBus()
+
+	if _, err := commandment.CreateOperation[*ParamsOperation[BadDefaultParams]](bus, BadDefaultParams{}); err == nil {
+		t.Error("Expected error for an unparseable default")
+	}
+}
+
+func TestParamDefaultsQuotedDefaultKeepsCommas(t *testing.T) {
+	bus := newDefaultsBus()
+
+	op, err := commandment.CreateOperation[*ParamsOperation[ListDefaultParams]](bus, ListDefaultParams{})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if op.Params.Tags != "a,b,c" || op.Params.Literal != "x" {
+		t.Errorf("Expected quoted default to keep its commas, got %+v", op.Params)
+	}
+}
+
+func TestParamDefaultsUnterminatedQuote(t *testing.T) {
+	bus := newDefaultsBus()
+
+	if _, err := commandment.CreateOperation[*ParamsOperation[UnterminatedDefaultParams]](bus, UnterminatedDefaultParams{}); err == nil {
+		t.Error("Expected error for a default with an unterminated quote")
+	}
+}
+
+// Params tagging fields for logging
+type LoggedParams struct {
+	TenantID   string `commandment:"logfield"`
+	HTTPStatus int    `commandment:"logfield,default=200"`
+	Secret     string
+}
+
+func TestParamLogFieldsAddedToExecutionLogs(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+
+	op, err := commandment.CreateOperation[*ParamsOperation[LoggedParams]](bus, LoggedParams{TenantID: "acme", Secret: "hunter2"})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	entry, ok := logger.Find("Operation execution completed")
+	if !ok {
+		t.Fatal("Expected completion to be logged")
+	}
+	if tenant, _ := entry.Field("tenant_id"); tenant != "acme" {
+		t.Errorf("Expected tenant_id %q, got %v", "acme", tenant)
+	}
+	if status, _ := entry.Field("http_status"); status != 200 {
+		t.Errorf("Expected defaulted http_status 200, got %v", status)
+	}
+	if _, ok := entry.Field("secret"); ok {
+		t.Error("Expected untagged field to be omitted")
+	}
+}
@@ -0,0 +1,57 @@
+package commandment
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// RegisterParamsType records TParams as the params type of operations with the given
+// type name, so DecodeDescriptor can restore their params to TParams.
+func RegisterParamsType[TParams any](bus *OperationBus, typeName string) {
+	bus.registerParamsType(typeName, reflect.TypeFor[TParams]())
+}
+
+func (b *OperationBus) registerParamsType(typeName string, paramsType reflect.Type) {
+	b.paramTypes.mu.Lock()
+	defer b.paramTypes.mu.Unlock()
+	b.paramTypes.types[typeName] = paramsType
+}
+
+// paramsType returns the registered params type for typeName, matching short and
+// qualified names of the same type as CreateFromDescriptor does.
+func (b *OperationBus) paramsType(typeName string) (reflect.Type, bool) {
+	b.paramTypes.mu.RLock()
+	defer b.paramTypes.mu.RUnlock()
+	paramsType, matches := lookupTypeName(b.paramTypes.types, typeName)
+	return paramsType, matches == 1
+}
+
+// DecodeDescriptor decodes data with codec, or JSONDescriptorCodec if codec is nil,
+// and restores its params to their registered params type. A descriptor decoded this
+// way is reported by Descriptor as it was before it was encoded, so encoding it again
+// produces the same JSON. Params of types without a registered params type are left
+// as the codec decoded them.
+func (b *OperationBus) DecodeDescriptor(data []byte, codec DescriptorCodec) (OperationDescriptor, error) {
+	if codec == nil {
+		codec = JSONDescriptorCodec
+	}
+	descriptor, err := codec.Decode(data)
+	if err != nil {
+		return OperationDescriptor{}, fmt.Errorf("decoding descriptor: %w", err)
+	}
+	paramsType, ok := b.paramsType(descriptor.Type)
+	if !ok {
+		return descriptor, nil
+	}
+	raw, err := rawParams(descriptor.Params)
+	if err != nil {
+		return OperationDescriptor{}, fmt.Errorf("encoding params for operation type %q: %w", descriptor.Type, err)
+	}
+	params := reflect.New(paramsType)
+	if err := json.Unmarshal(raw, params.Interface()); err != nil {
+		return OperationDescriptor{}, fmt.Errorf("decoding %s params: %w", descriptor.Type, err)
+	}
+	descriptor.Params = params.Elem().Interface()
+	return descriptor, nil
+}
@@ -0,0 +1,65 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// permissionsKey is the context key for the caller's permission set
+const permissionsKey contextKey = "commandment:permissions"
+
+// ErrForbidden is returned when the caller lacks permissions an operation requires.
+var ErrForbidden = errors.New("operation forbidden")
+
+// PermissionRequirer is implemented by operations that may only be executed by
+// callers holding all of the returned permissions.
+type PermissionRequirer interface {
+	RequiredPermissions() []string
+}
+
+// WithPermissions sets the permissions held by the caller.
+func WithPermissions(ctx context.Context, permissions ...string) context.Context {
+	return context.WithValue(ctx, permissionsKey, permissions)
+}
+
+// PermissionsFromContext retrieves the caller's permissions from context.
+func PermissionsFromContext(ctx context.Context) []string {
+	permissions, _ := ctx.Value(permissionsKey).([]string)
+	return permissions
+}
+
+// RequirePermissions returns middleware rejecting operations implementing
+// PermissionRequirer with ErrForbidden, listing the missing permissions, unless
+// the permissions in context include all of those required.
+func RequirePermissions() Middleware {
+	return func(next ExecuteFunc) ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			exec := executionFromContext(ctx)
+			if exec == nil {
+				return next(ctx)
+			}
+			requirer, ok := exec.op.(PermissionRequirer)
+			if !ok {
+				return next(ctx)
+			}
+			if missing := missingPermissions(requirer.RequiredPermissions(), PermissionsFromContext(ctx)); len(missing) > 0 {
+				return nil, fmt.Errorf("%w: %s requires %s", ErrForbidden, exec.typeName, strings.Join(missing, ", "))
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// missingPermissions returns the required permissions not in held.
+func missingPermissions(required, held []string) []string {
+	var missing []string
+	for _, permission := range required {
+		if !slices.Contains(held, permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
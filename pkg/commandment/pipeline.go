@@ -0,0 +1,81 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Pipeline executes a sequence of operations, feeding each stage's result into the
+// factory of the next. When a stage fails, the undos of the stages that already
+// completed run in reverse order, as a Saga's compensations do, each one logged to
+// the logger of the bus that created the stage's operation.
+type Pipeline struct {
+	stages []pipelineStage
+}
+
+// pipelineStage pairs an operation factory with an optional compensation.
+type pipelineStage struct {
+	factory func(prevResult any) Operation[any]
+	undo    func(ctx context.Context, result any) error
+}
+
+// NewPipeline creates an empty Pipeline.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Then appends a stage whose operation is built from the previous stage's result
+// (nil for the first stage).
+func (p *Pipeline) Then(factory func(prevResult any) Operation[any]) *Pipeline {
+	p.stages = append(p.stages, pipelineStage{factory: factory})
+	return p
+}
+
+// ThenWithUndo appends a stage with a compensation that reverses its effect. The undo
+// receives the stage's own result and runs only if a later stage fails.
+func (p *Pipeline) ThenWithUndo(factory func(prevResult any) Operation[any], undo func(ctx context.Context, result any) error) *Pipeline {
+	p.stages = append(p.stages, pipelineStage{factory: factory, undo: undo})
+	return p
+}
+
+// Run executes the stages in order and returns the final stage's result. If a stage
+// fails, completed stages are compensated in reverse order, even if ctx is cancelled,
+// and the returned error joins the stage failure with any compensation failures.
+func (p *Pipeline) Run(ctx context.Context) (any, error) {
+	result, _, err := p.RunWithSteps(ctx)
+	return result, err
+}
+
+// RunWithSteps executes the pipeline as Run does, also returning the descriptor of
+// each stage that executed, with the metadata of its execution, in order. When a
+// stage fails, the last descriptor is the failed stage's.
+func (p *Pipeline) RunWithSteps(ctx context.Context) (any, []OperationDescriptor, error) {
+	var (
+		prev      any
+		completed []compensation
+		steps     []OperationDescriptor
+	)
+	for i, stage := range p.stages {
+		op := stage.factory(prev)
+		if op == nil {
+			err := fmt.Errorf("pipeline stage %d: factory returned no operation", i)
+			return nil, steps, errors.Join(err, rollback(ctx, completed))
+		}
+
+		result, err := op.Execute(ctx)
+		steps = append(steps, op.Descriptor())
+		if err != nil {
+			err = fmt.Errorf("pipeline stage %d: %w", i, err)
+			return nil, steps, errors.Join(err, rollback(ctx, completed))
+		}
+
+		if stage.undo != nil {
+			completed = append(completed, newCompensation(op, func(ctx context.Context) error {
+				return stage.undo(ctx, result)
+			}, LoggerFromContext(ctx)))
+		}
+		prev = result
+	}
+	return prev, steps, nil
+}
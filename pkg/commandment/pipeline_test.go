@@ -0,0 +1,220 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Stateful inventory mock recording reservations and compensations
+type InventoryService struct {
+	reserved map[string]bool
+	log      []string
+}
+
+func (s *InventoryService) Reserve(ctx context.Context, item string) (string, error) {
+	if strings.HasPrefix(item, "unavailable") {
+		return "", errors.New("item unavailable: " + item)
+	}
+	s.reserved[item] = true
+	s.log = append(s.log, "reserve:"+item)
+	return item, nil
+}
+
+func (s *InventoryService) Release(item string) error {
+	delete(s.reserved, item)
+	s.log = append(s.log, "release:"+item)
+	return nil
+}
+
+// Test operation reserving an inventory item
+type ReserveCommand struct {
+	Params  string
+	Service *InventoryService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *ReserveCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return c.Service.Reserve(ctx, c.Params)
+	})
+}
+
+func (c *ReserveCommand) Metadata() commandment.OperationMetadata {
+	return c.Meta
+}
+
+func (c *ReserveCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "ReserveCommand",
+		Params:   c.Params,
+		Metadata: c.Meta,
+	}
+}
+
+func (c *ReserveCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *ReserveCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+// Undo releases the reserved item
+func (c *ReserveCommand) Undo(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Service.Release(c.Params)
+}
+
+func newInventoryBus() (*commandment.OperationBus, *InventoryService) {
+	inventory := &InventoryService{reserved: make(map[string]bool)}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, inventory)
+	return commandment.NewOperationBus(registry, &TestLogger{}), inventory
+}
+
+func reserveStage(t *testing.T, bus *commandment.OperationBus, item string) func(any) commandment.Operation[any] {
+	t.Helper()
+	return func(prev any) commandment.Operation[any] {
+		op, err := commandment.CreateOperation[*ReserveCommand](bus, item)
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		return commandment.AsAnyOperation[string](op)
+	}
+}
+
+func releaseUndo(inventory *InventoryService) func(context.Context, any) error {
+	return func(ctx context.Context, result any) error {
+		item, ok := result.(string)
+		if !ok {
+			return errors.New("unexpected result type")
+		}
+		return inventory.Release(item)
+	}
+}
+
+func TestPipelineCompensatesCompletedStagesInReverse(t *testing.T) {
+	bus, inventory := newInventoryBus()
+
+	_, err := commandment.NewPipeline().
+		ThenWithUndo(reserveStage(t, bus, "first"), releaseUndo(inventory)).
+		ThenWithUndo(reserveStage(t, bus, "second"), releaseUndo(inventory)).
+		ThenWithUndo(reserveStage(t, bus, "unavailable-third"), releaseUndo(inventory)).
+		Run(context.Background())
+	if err == nil {
+		t.Fatal("Expected pipeline to fail at stage 3")
+	}
+	if !strings.Contains(err.Error(), "pipeline stage 2") {
+		t.Errorf("Expected error to identify the failing stage, got %v", err)
+	}
+
+	expectedLog := []string{"reserve:first", "reserve:second", "release:second", "release:first"}
+	if !reflect.DeepEqual(inventory.log, expectedLog) {
+		t.Errorf("Expected log %v, got %v", expectedLog, inventory.log)
+	}
+	if len(inventory.reserved) != 0 {
+		t.Errorf("Expected no reservations left after compensation, got %v", inventory.reserved)
+	}
+}
+
+func TestPipelineCompensatesAfterCancellation(t *testing.T) {
+	bus, inventory := newInventoryBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := commandment.NewPipeline().
+		ThenWithUndo(reserveStage(t, bus, "first"), func(ctx context.Context, result any) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return inventory.Release(result.(string))
+		}).
+		Then(func(prev any) commandment.Operation[any] {
+			cancel()
+			return reserveStage(t, bus, "second")(prev)
+		}).
+		Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancellation, got %v", err)
+	}
+
+	expectedLog := []string{"reserve:first", "release:first"}
+	if !reflect.DeepEqual(inventory.log, expectedLog) {
+		t.Errorf("Expected log %v, got %v", expectedLog, inventory.log)
+	}
+}
+
+func TestPipelineThreadsResultsWithoutCompensation(t *testing.T) {
+	bus, inventory := newInventoryBus()
+
+	var seen []any
+	result, err := commandment.NewPipeline().
+		ThenWithUndo(reserveStage(t, bus, "first"), releaseUndo(inventory)).
+		Then(func(prev any) commandment.Operation[any] {
+			seen = append(seen, prev)
+			return reserveStage(t, bus, "second")(prev)
+		}).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("Pipeline failed: %v", err)
+	}
+
+	if result != "second" {
+		t.Errorf("Expected final result %q, got %v", "second", result)
+	}
+	if !reflect.DeepEqual(seen, []any{"first"}) {
+		t.Errorf("Expected second stage to receive %q, got %v", "first", seen)
+	}
+	if len(inventory.reserved) != 2 {
+		t.Errorf("Expected both reservations kept, got %v", inventory.reserved)
+	}
+}
+
+func TestPipelineJoinsCompensationErrors(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &InventoryService{reserved: make(map[string]bool)})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+	undoErr := errors.New("undo failed")
+
+	_, err := commandment.NewPipeline().
+		ThenWithUndo(reserveStage(t, bus, "first"), func(ctx context.Context, result any) error {
+			return undoErr
+		}).
+		Then(reserveStage(t, bus, "unavailable-second")).
+		Run(context.Background())
+
+	if !errors.Is(err, undoErr) {
+		t.Errorf("Expected compensation error to be surfaced, got %v", err)
+	}
+	entry, ok := logger.Find("Operation compensation failed")
+	if !ok {
+		t.Fatal("Expected the failed compensation to be logged")
+	}
+	if typeName, _ := entry.Field("operation_type"); typeName != "ReserveCommand" {
+		t.Errorf("Expected compensation logged for ReserveCommand, got %v", typeName)
+	}
+}
+
+func TestPipelineStepsIncludeFailedStage(t *testing.T) {
+	bus, _ := newInventoryBus()
+
+	_, steps, err := commandment.NewPipeline().
+		Then(reserveStage(t, bus, "first")).
+		Then(reserveStage(t, bus, "unavailable-second")).
+		Then(reserveStage(t, bus, "third")).
+		RunWithSteps(context.Background())
+	if err == nil {
+		t.Fatal("Expected pipeline to fail at stage 2")
+	}
+
+	if len(steps) != 2 {
+		t.Fatalf("Expected steps up to the failed stage, got %d", len(steps))
+	}
+	if steps[1].Params != "unavailable-second" || steps[1].Metadata.Returned.IsZero() {
+		t.Errorf("Expected the failed stage's executed descriptor last, got %+v", steps[1])
+	}
+}
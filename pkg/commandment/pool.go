@@ -0,0 +1,38 @@
+package commandment
+
+import "reflect"
+
+// WithOperationPooling makes the bus reuse operation structs released with
+// ReleaseOperation, instead of allocating one per CreateOperation, for
+// high-throughput callers. Only pointer operation types are pooled. Operations
+// that are never released are simply garbage collected.
+func WithOperationPooling() Option {
+	return func(b *OperationBus) {
+		b.pooling = true
+	}
+}
+
+// ReleaseOperation returns op to its type's pool once the caller is done with it,
+// after clearing every field, including Params, Service, Meta and unexported state,
+// and forgetting its bus and Dependencies. The caller must not use op afterwards,
+// nor release it while anything else may still hold it, such as the undo stack, a
+// Tx awaiting compensation, a Job or a pending ExecuteAsync. Values of non-pointer
+// types are ignored.
+func ReleaseOperation(op any) {
+	value := reflect.ValueOf(op)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return
+	}
+	value.Elem().SetZero()
+	layoutOf(value.Type()).pool.Put(op)
+}
+
+// newStruct returns a zero operation struct, reused from the pool if pooled.
+func (l *operationLayout) newStruct(pooled bool) reflect.Value {
+	if pooled && l.pointer {
+		if op := l.pool.Get(); op != nil {
+			return reflect.ValueOf(op).Elem()
+		}
+	}
+	return reflect.New(l.structType).Elem()
+}
@@ -0,0 +1,56 @@
+package commandment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestReleaseOperationResetsFields(t *testing.T) {
+	bus := newValueOpBus(commandment.WithOperationPooling())
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "first")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	firstID := op.Meta.UUID
+	commandment.ReleaseOperation(op)
+
+	// Inspecting a released operation is only safe in this test
+	if op.Params != "" || op.Service != nil || op.Logger != nil || op.Meta.UUID != "" {
+		t.Errorf("Expected released operation to be reset, got %+v", op)
+	}
+
+	reused, err := commandment.CreateOperation[*TestOperation](bus, "second")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if reused.Params != "second" || reused.Meta.UUID == firstID || !reused.Meta.Executed.IsZero() {
+		t.Errorf("Expected a freshly initialized operation, got %+v", reused)
+	}
+	if result, err := reused.Execute(context.Background()); err != nil || result != "result: second" {
+		t.Errorf("Expected reused operation to execute normally, got %q, %v", result, err)
+	}
+}
+
+func BenchmarkPooledCreateOperation(b *testing.B) {
+	for name, bus := range map[string]*commandment.OperationBus{
+		"fresh":  newValueOpBus(),
+		"pooled": newValueOpBus(commandment.WithOperationPooling()),
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+				if err != nil {
+					b.Fatal(err)
+				}
+				commandment.ReleaseOperation(op)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package commandment
+
+import "context"
+
+// PostHook receives the typed result of a successful execution, for example to
+// populate an external cache, record metrics or warm a read model.
+type PostHook[T any] func(ctx context.Context, descriptor OperationDescriptor, result T)
+
+// WithPostHook registers hook to run after every successful execution, by the bus,
+// of an operation whose result type is T, whether the result was computed or
+// served from a cache. Hooks run in registration order and cannot fail the execution.
+func WithPostHook[T any](hook PostHook[T]) Option {
+	return func(b *OperationBus) {
+		b.postHooks = append(b.postHooks, func(ctx context.Context, descriptor OperationDescriptor, result any) {
+			if typed, ok := result.(T); ok {
+				hook(ctx, descriptor, typed)
+			}
+		})
+	}
+}
+
+// runPostHooks passes result to the bus post-hooks. It is a no-op on a nil bus.
+func (b *OperationBus) runPostHooks(ctx context.Context, result any) {
+	if b == nil || len(b.postHooks) == 0 {
+		return
+	}
+	descriptor, _ := OperationDescriptorFromContext(ctx)
+	for _, hook := range b.postHooks {
+		hook(ctx, descriptor, result)
+	}
+}
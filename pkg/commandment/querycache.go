@@ -0,0 +1,221 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// QueryCache stores query results across requests. Implementations must be safe
+// for concurrent use.
+type QueryCache interface {
+	Get(key string) (any, bool)
+	Set(key string, value any, ttl time.Duration)
+	Delete(key string)
+}
+
+// WithQueryCache caches successful query results in cache for ttl, keyed by the
+// query's descriptor type and params. Commands don't invalidate cached results;
+// callers that know the underlying data changed evict entries with InvalidateCache.
+func WithQueryCache(cache QueryCache, ttl time.Duration) Option {
+	return func(b *OperationBus) {
+		b.queryCache = cache
+		b.queryCacheTTL = ttl
+		b.queryRefreshes = &sync.Map{}
+		b.queryCacheTypes = &sync.Map{}
+	}
+}
+
+// ErrNotFound reports that a query found no data. Services return it, possibly
+// wrapped, so that the query cache can cache the miss with WithNegativeCacheTTL.
+var ErrNotFound = errors.New("not found")
+
+// WithNegativeCacheTTL caches query results failing with ErrNotFound for ttl,
+// typically shorter than the query cache TTL, so repeated lookups of missing data
+// don't reach the service. It has no effect without WithQueryCache.
+func WithNegativeCacheTTL(ttl time.Duration) Option {
+	return func(b *OperationBus) {
+		b.negativeCacheTTL = ttl
+	}
+}
+
+// StaleWhileRevalidator is implemented by queries tolerant of slightly stale results.
+// For such a query, a cached result up to StaleWhileRevalidate() past its TTL is
+// returned immediately while a single background execution refreshes it.
+type StaleWhileRevalidator interface {
+	StaleWhileRevalidate() time.Duration
+}
+
+// InvalidateCache evicts the cached result of the query with the given descriptor
+// type name and params, so the next execution re-hits its service. typeName may be
+// short or qualified, as for CreateFromDescriptor; a short name shared by cached
+// queries from several packages only evicts an entry cached under that exact name.
+func (b *OperationBus) InvalidateCache(typeName string, params any) {
+	if b.queryCache == nil {
+		return
+	}
+	cached := make(map[string]string)
+	b.queryCacheTypes.Range(func(name, _ any) bool {
+		cached[name.(string)] = name.(string)
+		return true
+	})
+	if name, matches := lookupTypeName(cached, typeName); matches == 1 {
+		typeName = name
+	}
+	if key, ok := cacheKey(typeName, params); ok {
+		b.queryCache.Delete(key)
+	}
+}
+
+// cachedQueryResult is the value stored in the query cache, recording until when
+// the result is fresh. A zero freshUntil never goes stale. Negative entries carry
+// the not-found error the query returned instead of a value.
+type cachedQueryResult struct {
+	value      any
+	err        error
+	freshUntil time.Time
+}
+
+// queryCacheScope ties one query execution to the bus query cache.
+type queryCacheScope struct {
+	cache     QueryCache
+	ttl       time.Duration
+	stale     time.Duration // how long past ttl a result may be served stale
+	negative  time.Duration // ttl of not-found results; zero disables negative caching
+	key       string
+	refreshes *sync.Map // keys with a background refresh in progress
+}
+
+// beginQueryCache prepares query-cache handling for op. It returns an empty scope
+// on a nil bus, one without a query cache, or for commands.
+func (b *OperationBus) beginQueryCache(op any) queryCacheScope {
+	if b == nil || b.queryCache == nil || !isQuery(op) {
+		return queryCacheScope{}
+	}
+	d, ok := op.(Describer)
+	if !ok {
+		return queryCacheScope{}
+	}
+	descriptor := d.Descriptor()
+	key, ok := cacheKey(descriptor.Type, descriptor.Params)
+	if !ok {
+		return queryCacheScope{}
+	}
+	b.queryCacheTypes.Store(descriptor.Type, struct{}{})
+	scope := queryCacheScope{
+		cache:     b.queryCache,
+		ttl:       b.queryCacheTTL,
+		negative:  b.negativeCacheTTL,
+		key:       key,
+		refreshes: b.queryRefreshes,
+	}
+	if swr, ok := op.(StaleWhileRevalidator); ok && scope.ttl > 0 {
+		scope.stale = swr.StaleWhileRevalidate()
+	}
+	return scope
+}
+
+// lookup returns the cached result for the query, if present, and whether it is
+// stale. Negative entries are never served stale.
+func (s queryCacheScope) lookup() (entry cachedQueryResult, stale, ok bool) {
+	if s.cache == nil {
+		return cachedQueryResult{}, false, false
+	}
+	cached, ok := s.cache.Get(s.key)
+	if !ok {
+		return cachedQueryResult{}, false, false
+	}
+	entry, ok = cached.(cachedQueryResult)
+	if !ok {
+		return cachedQueryResult{}, false, false
+	}
+	stale = !entry.freshUntil.IsZero() && time.Now().After(entry.freshUntil)
+	if stale && (s.stale <= 0 || entry.err != nil) {
+		return cachedQueryResult{}, false, false
+	}
+	return entry, stale, true
+}
+
+// finish caches a successful query result, or a not-found result as a negative entry.
+func (s queryCacheScope) finish(result any, err error) {
+	switch {
+	case s.cache == nil:
+	case err == nil:
+		entry := cachedQueryResult{value: result}
+		ttl := s.ttl
+		if ttl > 0 {
+			entry.freshUntil = time.Now().Add(ttl)
+			ttl += s.stale
+		}
+		s.cache.Set(s.key, entry, ttl)
+	case s.negative > 0 && errors.Is(err, ErrNotFound):
+		s.cache.Set(s.key, cachedQueryResult{err: err, freshUntil: time.Now().Add(s.negative)}, s.negative)
+	}
+}
+
+// revalidate refreshes a stale result in the background with refresh, unless a
+// refresh for the same key is already running. The refresh outlives cancellation
+// of the request that triggered it.
+func (s queryCacheScope) revalidate(ctx context.Context, refresh func(context.Context) (any, error)) {
+	if _, running := s.refreshes.LoadOrStore(s.key, struct{}{}); running {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer s.refreshes.Delete(s.key)
+		result, err := refresh(ctx)
+		s.finish(result, err)
+	}()
+}
+
+// MemoryQueryCache is an in-memory QueryCache with per-entry expiry.
+type MemoryQueryCache struct {
+	mu      sync.Mutex
+	entries map[string]queryCacheEntry
+}
+
+type queryCacheEntry struct {
+	value   any
+	expires time.Time // zero for entries that never expire
+}
+
+// NewMemoryQueryCache creates an empty MemoryQueryCache.
+func NewMemoryQueryCache() *MemoryQueryCache {
+	return &MemoryQueryCache{
+		entries: make(map[string]queryCacheEntry),
+	}
+}
+
+// Get returns the unexpired value cached under key.
+func (c *MemoryQueryCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set caches value under key for ttl. A non-positive ttl never expires.
+func (c *MemoryQueryCache) Set(key string, value any, ttl time.Duration) {
+	entry := queryCacheEntry{value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
+
+// Delete evicts the value cached under key.
+func (c *MemoryQueryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
@@ -0,0 +1,334 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestQueryCacheServesRepeatedQueries(t *testing.T) {
+	bus, service := newRecordBus(commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute))
+	ctx := context.Background()
+
+	readRecord(ctx, t, bus, "k")
+	readRecord(ctx, t, bus, "k")
+	if service.reads != 1 {
+		t.Errorf("Expected repeated query to be served from query cache, got %d reads", service.reads)
+	}
+}
+
+func TestInvalidateCacheEvictsMatchingQuery(t *testing.T) {
+	bus, service := newRecordBus(commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute))
+	ctx := context.Background()
+
+	readRecord(ctx, t, bus, "k")
+	readRecord(ctx, t, bus, "other")
+	service.records["k"] = "changed"
+
+	bus.InvalidateCache("ReadRecordQuery", "k")
+
+	if got := readRecord(ctx, t, bus, "k"); got != "changed" {
+		t.Errorf("Expected invalidated query to see %q, got %q", "changed", got)
+	}
+	readRecord(ctx, t, bus, "other")
+	if service.reads != 3 {
+		t.Errorf("Expected only the invalidated query to re-hit the service, got %d reads", service.reads)
+	}
+}
+
+// Test query described under its qualified type name, like operations in other
+// packages that use QualifiedTypeName for their descriptors
+type QualifiedLookupQuery struct {
+	Params  string
+	Service *DirectoryService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (q *QualifiedLookupQuery) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, q, func(ctx context.Context) (string, error) {
+		return q.Service.Lookup(ctx, q.Params)
+	})
+}
+
+func (q *QualifiedLookupQuery) Metadata() commandment.OperationMetadata {
+	return q.Meta
+}
+
+func (q *QualifiedLookupQuery) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     commandment.QualifiedTypeName(q),
+		Params:   q.Params,
+		Metadata: q.Meta,
+	}
+}
+
+func (q *QualifiedLookupQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
+func (q *QualifiedLookupQuery) GetLogger() commandment.Logger               { return q.Logger }
+func (q *QualifiedLookupQuery) ReadOnly()                                   {}
+
+func TestInvalidateCacheResolvesShortAndQualifiedNames(t *testing.T) {
+	for _, typeName := range []string{"QualifiedLookupQuery", commandment.QualifiedTypeName(&QualifiedLookupQuery{})} {
+		t.Run(typeName, func(t *testing.T) {
+			bus, service := newDirectoryBus(commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute))
+			query := func() string {
+				t.Helper()
+				q, err := commandment.CreateOperation[*QualifiedLookupQuery](bus, "alice")
+				if err != nil {
+					t.Fatalf("Failed to create query: %v", err)
+				}
+				result, err := q.Execute(context.Background())
+				if err != nil {
+					t.Fatalf("Query execution failed: %v", err)
+				}
+				return result
+			}
+
+			query()
+			service.entries["alice"] = "room 2"
+			bus.InvalidateCache(typeName, "alice")
+
+			if got := query(); got != "room 2" {
+				t.Errorf("Expected invalidated query to see %q, got %q", "room 2", got)
+			}
+			if service.lookups != 2 {
+				t.Errorf("Expected invalidated query to re-hit the service, got %d lookups", service.lookups)
+			}
+		})
+	}
+}
+
+func TestQueryCacheEntriesExpire(t *testing.T) {
+	bus, service := newRecordBus(commandment.WithQueryCache(commandment.NewMemoryQueryCache(), 10*time.Millisecond))
+	ctx := context.Background()
+
+	readRecord(ctx, t, bus, "k")
+	time.Sleep(20 * time.Millisecond)
+	readRecord(ctx, t, bus, "k")
+	if service.reads != 2 {
+		t.Errorf("Expected expired entry to re-hit the service, got %d reads", service.reads)
+	}
+}
+
+func TestQueryCacheSkipsCommands(t *testing.T) {
+	bus, service := newRecordBus(commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute))
+	ctx := context.Background()
+
+	writeRecord(ctx, t, bus, "k", "v2")
+	writeRecord(ctx, t, bus, "k", "v3")
+	if service.records["k"] != "v3" {
+		t.Errorf("Expected every command to execute, got record %q", service.records["k"])
+	}
+}
+
+// Service whose reads after the first block until released
+type RevalidatingService struct {
+	reads   atomic.Int32
+	release chan struct{}
+	done    chan struct{}
+}
+
+func (s *RevalidatingService) Read(ctx context.Context, key string) (string, error) {
+	n := s.reads.Add(1)
+	if n == 1 {
+		return "initial", nil
+	}
+	<-s.release
+	defer func() { s.done <- struct{}{} }()
+	return "refreshed", nil
+}
+
+// Test query tolerating stale results while they are refreshed
+type StaleReadQuery struct {
+	Params  string
+	Service *RevalidatingService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (q *StaleReadQuery) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, q, func(ctx context.Context) (string, error) {
+		return q.Service.Read(ctx, q.Params)
+	})
+}
+
+func (q *StaleReadQuery) Metadata() commandment.OperationMetadata {
+	return q.Meta
+}
+
+func (q *StaleReadQuery) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "StaleReadQuery",
+		Params:   q.Params,
+		Metadata: q.Meta,
+	}
+}
+
+func (q *StaleReadQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
+func (q *StaleReadQuery) GetLogger() commandment.Logger               { return q.Logger }
+func (q *StaleReadQuery) ReadOnly()                                   {}
+func (q *StaleReadQuery) StaleWhileRevalidate() time.Duration         { return time.Minute }
+
+func TestStaleWhileRevalidateRefreshesOnceInBackground(t *testing.T) {
+	service := &RevalidatingService{release: make(chan struct{}), done: make(chan struct{}, 1)}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithQueryCache(commandment.NewMemoryQueryCache(), 10*time.Millisecond))
+
+	read := func() string {
+		query, err := commandment.CreateOperation[*StaleReadQuery](bus, "k")
+		if err != nil {
+			t.Errorf("Failed to create query: %v", err)
+			return ""
+		}
+		result, err := query.Execute(context.Background())
+		if err != nil {
+			t.Errorf("Query execution failed: %v", err)
+		}
+		return result
+	}
+
+	read()
+	time.Sleep(20 * time.Millisecond)
+
+	// Concurrent stale hits return the stale value without waiting on the refresh
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := read(); got != "initial" {
+				t.Errorf("Expected stale value %q, got %q", "initial", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(service.release)
+	<-service.done
+
+	if reads := service.reads.Load(); reads != 2 {
+		t.Errorf("Expected exactly one background refresh, got %d reads", reads)
+	}
+
+	// The refreshed value is stored; wait for it to be visible
+	deadline := time.Now().Add(time.Second)
+	for read() != "refreshed" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected refreshed value to be served after revalidation")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Service looking up entries that may not exist
+type DirectoryService struct {
+	entries map[string]string
+	lookups int
+}
+
+func (s *DirectoryService) Lookup(ctx context.Context, name string) (string, error) {
+	s.lookups++
+	entry, ok := s.entries[name]
+	if !ok {
+		return "", fmt.Errorf("entry %q: %w", name, commandment.ErrNotFound)
+	}
+	return entry, nil
+}
+
+// Test query looking up a directory entry
+type LookupQuery struct {
+	Params  string
+	Service *DirectoryService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (q *LookupQuery) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, q, func(ctx context.Context) (string, error) {
+		return q.Service.Lookup(ctx, q.Params)
+	})
+}
+
+func (q *LookupQuery) Metadata() commandment.OperationMetadata {
+	return q.Meta
+}
+
+func (q *LookupQuery) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "LookupQuery",
+		Params:   q.Params,
+		Metadata: q.Meta,
+	}
+}
+
+func (q *LookupQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
+func (q *LookupQuery) GetLogger() commandment.Logger               { return q.Logger }
+func (q *LookupQuery) ReadOnly()                                   {}
+
+func newDirectoryBus(opts ...commandment.Option) (*commandment.OperationBus, *DirectoryService) {
+	service := &DirectoryService{entries: map[string]string{"alice": "room 1"}}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	return commandment.NewOperationBus(registry, &TestLogger{}, opts...), service
+}
+
+func lookup(t *testing.T, bus *commandment.OperationBus, name string) (string, error) {
+	t.Helper()
+	query, err := commandment.CreateOperation[*LookupQuery](bus, name)
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	return query.Execute(context.Background())
+}
+
+func TestNegativeCacheServesNotFound(t *testing.T) {
+	bus, service := newDirectoryBus(
+		commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute),
+		commandment.WithNegativeCacheTTL(time.Minute),
+	)
+
+	for range 2 {
+		if _, err := lookup(t, bus, "bob"); !errors.Is(err, commandment.ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	}
+	if service.lookups != 1 {
+		t.Errorf("Expected cached not-found to skip the service, got %d lookups", service.lookups)
+	}
+}
+
+func TestNegativeCacheEntriesExpireSooner(t *testing.T) {
+	bus, service := newDirectoryBus(
+		commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute),
+		commandment.WithNegativeCacheTTL(10*time.Millisecond),
+	)
+
+	_, _ = lookup(t, bus, "bob")
+	service.entries["bob"] = "room 2"
+	time.Sleep(20 * time.Millisecond)
+
+	if got, err := lookup(t, bus, "bob"); err != nil || got != "room 2" {
+		t.Errorf("Expected expired negative entry to re-hit the service, got %q, %v", got, err)
+	}
+	if service.lookups != 2 {
+		t.Errorf("Expected 2 lookups, got %d", service.lookups)
+	}
+}
+
+func TestNotFoundNotCachedWithoutNegativeTTL(t *testing.T) {
+	bus, service := newDirectoryBus(commandment.WithQueryCache(commandment.NewMemoryQueryCache(), time.Minute))
+
+	_, _ = lookup(t, bus, "bob")
+	_, _ = lookup(t, bus, "bob")
+	if service.lookups != 2 {
+		t.Errorf("Expected not-found results not to be cached, got %d lookups", service.lookups)
+	}
+}
@@ -0,0 +1,117 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// identityKey is the context key for the caller identity
+const identityKey contextKey = "commandment:identity"
+
+// ErrRateLimited is returned when a caller exceeds its per-identity execution limit.
+var ErrRateLimited = errors.New("operation rate limited")
+
+// WithIdentity sets the caller identity used for per-identity limits.
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// IdentityFromContext retrieves the caller identity from context.
+func IdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(identityKey).(string)
+	return identity, ok
+}
+
+// WithPerIdentityLimit limits each caller identity to rps executions per second,
+// allowing bursts of up to burst executions. Executions over the limit fail with
+// ErrRateLimited; executions without an identity in context are not limited.
+func WithPerIdentityLimit(rps float64, burst int) Option {
+	return func(b *OperationBus) {
+		b.identityLimiter = newIdentityLimiter(rps, burst)
+	}
+}
+
+// checkRateLimit reports ErrRateLimited if the identity in ctx is over its limit.
+// It is a no-op on a nil bus or one without a per-identity limit.
+func (b *OperationBus) checkRateLimit(ctx context.Context) error {
+	if b == nil || b.identityLimiter == nil {
+		return nil
+	}
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if !b.identityLimiter.allow(identity, time.Now()) {
+		return fmt.Errorf("%w: identity %q", ErrRateLimited, identity)
+	}
+	return nil
+}
+
+// maxIdleBuckets is the number of tracked identities above which full buckets are pruned.
+const maxIdleBuckets = 10000
+
+// identityLimiter keeps an independent token bucket per identity.
+type identityLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newIdentityLimiter(rps float64, burst int) *identityLimiter {
+	return &identityLimiter{
+		rate:    rps,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow consumes a token for identity if one is available at now.
+func (l *identityLimiter) allow(identity string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[identity]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.pruneFull(now)
+		}
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[identity] = bucket
+	}
+
+	l.refill(bucket, now)
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+func (l *identityLimiter) refill(bucket *tokenBucket, now time.Time) {
+	elapsed := now.Sub(bucket.last).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = min(l.burst, bucket.tokens+elapsed*l.rate)
+		bucket.last = now
+	}
+}
+
+// pruneFull forgets identities whose buckets have refilled completely, since a
+// fresh bucket would behave identically.
+func (l *identityLimiter) pruneFull(now time.Time) {
+	for identity, bucket := range l.buckets {
+		l.refill(bucket, now)
+		if bucket.tokens >= l.burst {
+			delete(l.buckets, identity)
+		}
+	}
+}
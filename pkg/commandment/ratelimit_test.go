@@ -0,0 +1,81 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func newRateLimitedBus(t *testing.T, service *CountingService) *commandment.OperationBus {
+	t.Helper()
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	// A near-zero refill rate keeps the test independent of timing
+	return commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithPerIdentityLimit(0.001, 2))
+}
+
+func executeAs(t *testing.T, bus *commandment.OperationBus, identity string) error {
+	t.Helper()
+	op, err := commandment.CreateOperation[*TestOperation](bus, identity)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	ctx := context.Background()
+	if identity != "" {
+		ctx = commandment.WithIdentity(ctx, identity)
+	}
+	_, err = op.Execute(ctx)
+	return err
+}
+
+func TestPerIdentityLimitIsolatesIdentities(t *testing.T) {
+	service := &CountingService{}
+	bus := newRateLimitedBus(t, service)
+
+	// alice exhausts her burst
+	for i := range 2 {
+		if err := executeAs(t, bus, "alice"); err != nil {
+			t.Fatalf("Execution %d for alice should be allowed: %v", i+1, err)
+		}
+	}
+	err := executeAs(t, bus, "alice")
+	if !errors.Is(err, commandment.ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited for alice, got %v", err)
+	}
+
+	// bob is unaffected by alice's throttling
+	for i := range 2 {
+		if err := executeAs(t, bus, "bob"); err != nil {
+			t.Errorf("Execution %d for bob should be allowed: %v", i+1, err)
+		}
+	}
+
+	if calls := service.calls.Load(); calls != 4 {
+		t.Errorf("Expected rejected execution not to reach the service, got %d calls", calls)
+	}
+}
+
+func TestPerIdentityLimitIgnoresAnonymousCallers(t *testing.T) {
+	bus := newRateLimitedBus(t, &CountingService{})
+
+	for i := range 5 {
+		if err := executeAs(t, bus, ""); err != nil {
+			t.Fatalf("Anonymous execution %d should not be limited: %v", i+1, err)
+		}
+	}
+}
+
+func TestIdentityFromContext(t *testing.T) {
+	ctx := commandment.WithIdentity(context.Background(), "carol")
+
+	identity, ok := commandment.IdentityFromContext(ctx)
+	if !ok || identity != "carol" {
+		t.Errorf("Expected identity %q, got %q (ok=%v)", "carol", identity, ok)
+	}
+
+	if _, ok := commandment.IdentityFromContext(context.Background()); ok {
+		t.Error("Expected no identity in empty context")
+	}
+}
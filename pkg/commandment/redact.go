@@ -0,0 +1,209 @@
+package commandment
+
+import (
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces redacted string values.
+const redactedValue = "***"
+
+// RedactionPolicy selects params values to mask wherever the bus logs or persists
+// params: tagged log fields and audit records. Masked strings read "***";
+// masked values of other kinds are zeroed. Params fields tagged
+// `commandment:"redact"` are always masked there, and in serialized descriptors,
+// whatever the policy.
+type RedactionPolicy struct {
+	// Fields are dot-separated paths of Go field names, such as "Owner.Email",
+	// whose segments may be path.Match patterns. A pattern without a dot matches
+	// the field at any depth, so "Description" masks every Description field.
+	Fields []string
+	// Types are types whose values are always masked, such as a Secret type.
+	Types []reflect.Type
+}
+
+// WithRedactionPolicy masks params matching policy in logs and audit records. Audit
+// records then hold the masked params and are marked Redacted, so ReplayFailed
+// refuses to replay them.
+func WithRedactionPolicy(policy RedactionPolicy) Option {
+	return func(b *OperationBus) {
+		b.redaction = &policy
+	}
+}
+
+// redactParams returns a copy of params with the values selected by the bus
+// redaction policy or redact tags masked. It returns params unchanged when there
+// is nothing to mask.
+func (b *OperationBus) redactParams(params any) any {
+	if b == nil || b.redaction == nil {
+		return redactTagged(params)
+	}
+	if params == nil {
+		return params
+	}
+	v := reflect.ValueOf(params)
+	if b.redaction.matchesType(v.Type()) {
+		return mask(v).Interface()
+	}
+	return b.redaction.redact(v, "").Interface()
+}
+
+// redactTagged returns a copy of params with its `commandment:"redact"` fields
+// masked, or params itself when its type has none.
+func redactTagged(params any) any {
+	if params == nil || !hasRedactTags(reflect.TypeOf(params)) {
+		return params
+	}
+	return (&RedactionPolicy{}).redact(reflect.ValueOf(params), "").Interface()
+}
+
+// redactTaggedTypes caches whether a type holds fields tagged for redaction.
+var redactTaggedTypes sync.Map // map[reflect.Type]bool
+
+func hasRedactTags(t reflect.Type) bool {
+	if cached, ok := redactTaggedTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := containsRedactTags(t, make(map[reflect.Type]bool))
+	redactTaggedTypes.Store(t, result)
+	return result
+}
+
+// containsRedactTags reports whether t, or a struct reached from it through the
+// pointers, slices and fields redact traverses, has a field tagged for redaction.
+func containsRedactTags(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice:
+		return containsRedactTags(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if hasTagOption(field, "redact") || containsRedactTags(field.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redact copies v, masking the fields the policy selects. Map contents are not traversed.
+func (p *RedactionPolicy) redact(v reflect.Value, fieldPath string) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		ptr := reflect.New(v.Type().Elem())
+		ptr.Elem().Set(p.redact(v.Elem(), fieldPath))
+		return ptr
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			childPath := field.Name
+			if fieldPath != "" {
+				childPath = fieldPath + "." + field.Name
+			}
+			if hasTagOption(field, "redact") || p.matchesType(field.Type) || p.matchesField(childPath) {
+				out.Field(i).Set(mask(v.Field(i)))
+			} else {
+				out.Field(i).Set(p.redact(v.Field(i), childPath))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(p.redact(v.Index(i), fieldPath))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func (p *RedactionPolicy) matchesType(t reflect.Type) bool {
+	return slices.ContainsFunc(p.Types, func(redacted reflect.Type) bool {
+		return t == redacted || (t.Kind() == reflect.Pointer && t.Elem() == redacted)
+	})
+}
+
+func (p *RedactionPolicy) matchesField(fieldPath string) bool {
+	name := fieldPath[strings.LastIndex(fieldPath, ".")+1:]
+	for _, pattern := range p.Fields {
+		target := fieldPath
+		if !strings.Contains(pattern, ".") {
+			target = name
+		}
+		// Match on slash-separated paths so "*" doesn't span segments.
+		matched, _ := path.Match(strings.ReplaceAll(pattern, ".", "/"), strings.ReplaceAll(target, ".", "/"))
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// mask returns the masked form of v: redactedValue for strings, else the zero value.
+func mask(v reflect.Value) reflect.Value {
+	masked := reflect.New(v.Type()).Elem()
+	if v.Kind() == reflect.String {
+		masked.SetString(redactedValue)
+	}
+	return masked
+}
+
+// hasMaskedFields reports whether a `commandment:"redact"` string field of params,
+// or of a struct reached from it as redact traverses, holds redactedValue, as
+// params decoded from a serialized descriptor do.
+func hasMaskedFields(params any) bool {
+	if params == nil || !hasRedactTags(reflect.TypeOf(params)) {
+		return false
+	}
+	return holdsMask(reflect.ValueOf(params))
+}
+
+func holdsMask(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer:
+		return !v.IsNil() && holdsMask(v.Elem())
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if hasTagOption(field, "redact") && v.Field(i).Kind() == reflect.String {
+				if v.Field(i).String() == redactedValue {
+					return true
+				}
+			} else if holdsMask(v.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			if holdsMask(v.Index(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
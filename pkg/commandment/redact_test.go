@@ -0,0 +1,276 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Params of a list-creating operation with a logged description
+type ListParams struct {
+	Title       string `commandment:"logfield"`
+	Description string `commandment:"logfield"`
+}
+
+// Params of a node-editing operation with a logged description
+type NodeEditParams struct {
+	NodeID      int64  `commandment:"logfield"`
+	Description string `commandment:"logfield"`
+	Owner       ContactParams
+}
+
+// Nested contact details
+type ContactParams struct {
+	Name  string
+	Email string
+}
+
+func executeLogged[P any](t *testing.T, policy commandment.RedactionPolicy, params P) LogEntry {
+	t.Helper()
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger, commandment.WithRedactionPolicy(policy))
+
+	op, err := commandment.CreateOperation[*ParamsOperation[P]](bus, params)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	entry, ok := logger.Find("Operation execution completed")
+	if !ok {
+		t.Fatal("Expected completion to be logged")
+	}
+	return entry
+}
+
+func TestRedactionPolicyMasksFieldsInLogsAcrossTypes(t *testing.T) {
+	policy := commandment.RedactionPolicy{Fields: []string{"Description"}}
+
+	entry := executeLogged(t, policy, ListParams{Title: "Groceries", Description: "private notes"})
+	if got, _ := entry.Field("description"); got != "***" {
+		t.Errorf("Expected list description to be redacted, got %v", got)
+	}
+	if got, _ := entry.Field("title"); got != "Groceries" {
+		t.Errorf("Expected unmatched field to be logged, got %v", got)
+	}
+
+	entry = executeLogged(t, policy, NodeEditParams{NodeID: 3, Description: "private notes"})
+	if got, _ := entry.Field("description"); got != "***" {
+		t.Errorf("Expected node description to be redacted, got %v", got)
+	}
+	if got, _ := entry.Field("node_id"); got != int64(3) {
+		t.Errorf("Expected unmatched field to be logged, got %v", got)
+	}
+}
+
+type SecretToken string
+
+// Params carrying a secret by type
+type TokenParams struct {
+	Account string
+	Token   SecretToken
+}
+
+func TestRedactionPolicyMasksAuditedParamsByPathAndType(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	sink := &RecordingAuditSink{}
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithAuditSink(sink),
+		commandment.WithRedactionPolicy(commandment.RedactionPolicy{
+			Fields: []string{"Owner.Email"},
+			Types:  []reflect.Type{reflect.TypeFor[SecretToken]()},
+		}))
+
+	params := NodeEditParams{NodeID: 3, Owner: ContactParams{Name: "Ann", Email: "ann@example.com"}}
+	edit, err := commandment.CreateOperation[*ParamsOperation[NodeEditParams]](bus, params)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	token, err := commandment.CreateOperation[*ParamsOperation[TokenParams]](bus, TokenParams{Account: "a", Token: "s3cr3t"})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := edit.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if _, err := token.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if len(sink.records) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", len(sink.records))
+	}
+	audited, _ := sink.records[0].Descriptor.Params.(NodeEditParams)
+	if audited.Owner.Email != "***" || audited.Owner.Name != "Ann" {
+		t.Errorf("Expected only Owner.Email to be redacted, got %+v", audited.Owner)
+	}
+	if audited, _ := sink.records[1].Descriptor.Params.(TokenParams); audited.Token != "***" || audited.Account != "a" {
+		t.Errorf("Expected SecretToken values to be redacted, got %+v", audited)
+	}
+	if edit.Params.Owner.Email != "ann@example.com" {
+		t.Errorf("Expected the operation's own params to be left intact, got %q", edit.Params.Owner.Email)
+	}
+}
+
+// Params of a sign-in command with a redacted password
+type SignInParams struct {
+	User     string
+	Password string `commandment:"redact"`
+}
+
+// Credential service remembering the passwords it was given
+type CredentialService struct {
+	passwords map[string]string
+}
+
+func (s *CredentialService) SignIn(ctx context.Context, params SignInParams) (string, error) {
+	s.passwords[params.User] = params.Password
+	return "signed in: " + params.User, nil
+}
+
+// Test command signing a user in
+type SignInCommand struct {
+	Params  SignInParams
+	Service *CredentialService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *SignInCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return c.Service.SignIn(ctx, c.Params)
+	})
+}
+
+func (c *SignInCommand) Metadata() commandment.OperationMetadata {
+	return c.Meta
+}
+
+func (c *SignInCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "SignInCommand", Params: c.Params, Metadata: c.Meta}
+}
+
+func (c *SignInCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *SignInCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func TestRedactTagMasksSerializedDescriptorParams(t *testing.T) {
+	service := &CredentialService{passwords: make(map[string]string)}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	sink := &RecordingAuditSink{}
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithAuditSink(sink))
+
+	cmd, err := commandment.CreateOperation[*SignInCommand](bus, SignInParams{User: "ann", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	data, err := json.Marshal(cmd.Descriptor())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), `"Password":"***"`) {
+		t.Errorf("Expected the password masked in the serialized descriptor, got %s", data)
+	}
+	if !strings.Contains(string(data), `"User":"ann"`) {
+		t.Errorf("Expected untagged fields serialized as is, got %s", data)
+	}
+
+	if _, err := cmd.Execute(context.Background()); err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if service.passwords["ann"] != "hunter2" {
+		t.Errorf("Expected the service to receive the real password, got %q", service.passwords["ann"])
+	}
+	if audited, _ := sink.records[0].Descriptor.Params.(SignInParams); audited.Password != "***" {
+		t.Errorf("Expected the password masked in the audit record, got %+v", audited)
+	}
+}
+
+func TestJobEncodesRedactTaggedParamsInFull(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, &CredentialService{passwords: make(map[string]string)})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	cmd, err := commandment.CreateOperation[*SignInCommand](bus, SignInParams{User: "ann", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	data, err := json.Marshal(bus.ToJob(cmd))
+	if err != nil {
+		t.Fatalf("Failed to serialize job: %v", err)
+	}
+	if !strings.Contains(string(data), `"Password":"hunter2"`) {
+		t.Errorf("Expected the job to hold the real password, got %s", data)
+	}
+}
+
+func newSignInJobBus(service *CredentialService) *commandment.OperationBus {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	bus.RegisterDescriptorFactory("SignInCommand", func(params json.RawMessage, meta commandment.OperationMetadata) (any, error) {
+		var p SignInParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return commandment.CreateOperation[*SignInCommand](bus, p)
+	})
+	return bus
+}
+
+func TestLoadedJobRunsWithRedactTaggedParamsIntact(t *testing.T) {
+	service := &CredentialService{passwords: make(map[string]string)}
+	bus := newSignInJobBus(service)
+
+	cmd, err := commandment.CreateOperation[*SignInCommand](bus, SignInParams{User: "ann", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	data, err := json.Marshal(bus.ToJob(cmd))
+	if err != nil {
+		t.Fatalf("Failed to serialize job: %v", err)
+	}
+	job, err := bus.LoadJob(data)
+	if err != nil {
+		t.Fatalf("Failed to load job: %v", err)
+	}
+	if _, err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Job run failed: %v", err)
+	}
+	if service.passwords["ann"] != "hunter2" {
+		t.Errorf("Expected the job to execute with the real password, got %q", service.passwords["ann"])
+	}
+}
+
+func TestJobFromRedactedDescriptorFailsToRun(t *testing.T) {
+	service := &CredentialService{passwords: make(map[string]string)}
+	bus := newSignInJobBus(service)
+
+	cmd, err := commandment.CreateOperation[*SignInCommand](bus, SignInParams{User: "ann", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	descriptor, err := json.Marshal(cmd.Descriptor())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	job, err := bus.LoadJob([]byte(`{"descriptor":` + string(descriptor) + `}`))
+	if err != nil {
+		t.Fatalf("Failed to load job: %v", err)
+	}
+	if _, err := job.Run(context.Background()); !errors.Is(err, commandment.ErrRedactedJob) {
+		t.Errorf("Expected ErrRedactedJob, got %v", err)
+	}
+	if _, ok := service.passwords["ann"]; ok {
+		t.Errorf("Expected the redacted job not to execute, got password %q", service.passwords["ann"])
+	}
+}
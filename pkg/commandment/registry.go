@@ -1,41 +1,100 @@
 package commandment
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
 )
 
+// ErrServiceNotRegistered is returned when creating an operation whose service type
+// has not been registered.
+var ErrServiceNotRegistered = errors.New("service not registered")
+
 // ServiceRegistry manages service instances using reflection-based type mapping.
+// Registration is copy-on-write: each registration publishes a new immutable
+// snapshot of the services, so lookups never block and services may be
+// re-registered, for example on config reload, while operations are being created.
 type ServiceRegistry struct {
-	services map[reflect.Type]any
+	mu       sync.Mutex // serializes registrations
+	services atomic.Pointer[serviceSnapshot]
 }
 
+// serviceSnapshot is an immutable view of the registered services.
+type serviceSnapshot map[reflect.Type]any
+
 // NewServiceRegistry creates a new empty service registry.
 func NewServiceRegistry() *ServiceRegistry {
-	return &ServiceRegistry{
-		services: make(map[reflect.Type]any),
-	}
+	r := &ServiceRegistry{}
+	r.services.Store(&serviceSnapshot{})
+	return r
 }
 
 // register stores a service instance by its type.
 func (r *ServiceRegistry) register(serviceType reflect.Type, service any) {
-	r.services[serviceType] = service
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := maps.Clone(*r.services.Load())
+	next[serviceType] = service
+	r.services.Store(&next)
+}
+
+// snapshot returns the services registered at the time of the call. Later
+// registrations don't affect it.
+func (r *ServiceRegistry) snapshot() serviceSnapshot {
+	return *r.services.Load()
 }
 
 // get retrieves a service instance by its type.
 func (r *ServiceRegistry) get(serviceType reflect.Type) any {
-	service, exists := r.services[serviceType]
+	return r.snapshot().get(serviceType)
+}
+
+// get retrieves a service instance by its type from the snapshot.
+func (s serviceSnapshot) get(serviceType reflect.Type) any {
+	service, exists := s.lookup(serviceType)
 	if !exists {
 		panic(fmt.Sprintf("Service type %v not registered", serviceType))
 	}
 	return service
 }
 
+// lookup retrieves a service instance by its type from the snapshot, reporting
+// whether it is registered. An interface type without a registration of its own
+// resolves to the one registered service implementing it, if there is exactly one.
+func (s serviceSnapshot) lookup(serviceType reflect.Type) (any, bool) {
+	if service, exists := s[serviceType]; exists {
+		return service, true
+	}
+	if serviceType == nil || serviceType.Kind() != reflect.Interface {
+		return nil, false
+	}
+	var match any
+	matches := 0
+	for registeredType, service := range s {
+		if registeredType.Kind() != reflect.Interface && registeredType.Implements(serviceType) {
+			match = service
+			matches++
+		}
+	}
+	return match, matches == 1
+}
+
 // RegisterService registers a service instance of type T in the registry.
 func RegisterService[T any](r *ServiceRegistry, service T) {
 	r.register(reflect.TypeOf((*T)(nil)).Elem(), service)
 }
 
+// RegisterServiceAs registers service under the interface type TIface and under
+// its concrete type, so it resolves for Service fields typed either way.
+func RegisterServiceAs[TIface any](r *ServiceRegistry, service TIface) {
+	r.register(reflect.TypeOf((*TIface)(nil)).Elem(), service)
+	r.register(reflect.TypeOf(service), service)
+}
+
 // GetService retrieves a service of type T from the registry.
 func GetService[T any](r *ServiceRegistry) T {
 	serviceType := reflect.TypeOf((*T)(nil)).Elem()
@@ -48,7 +107,26 @@ func GetService[T any](r *ServiceRegistry) T {
 	return result
 }
 
+// TryGetService retrieves a service of type T from the registry, reporting false
+// instead of panicking when none is registered.
+func TryGetService[T any](r *ServiceRegistry) (T, bool) {
+	service, ok := r.snapshot().lookup(reflect.TypeOf((*T)(nil)).Elem())
+	result, isT := service.(T)
+	return result, ok && isT
+}
+
 // GetServiceByType retrieves a service by its reflect.Type
 func (r *ServiceRegistry) GetServiceByType(serviceType reflect.Type) any {
 	return r.get(serviceType)
 }
+
+// serviceTypeNames returns the sorted names of all registered service types.
+func (r *ServiceRegistry) serviceTypeNames() []string {
+	services := r.snapshot()
+	names := make([]string, 0, len(services))
+	for serviceType := range services {
+		names = append(names, serviceType.String())
+	}
+	sort.Strings(names)
+	return names
+}
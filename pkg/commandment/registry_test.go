@@ -1,7 +1,9 @@
 package commandment_test
 
 import (
+  "context"
   "reflect"
+  "sync"
   "testing"
 
   "github.com/davidlee/commandment/pkg/commandment"
@@ -92,4 +94,53 @@ func TestGetServiceByType_MultipleServices(t *testing.T) {
   if dbResult.ConnectionString != "prod:5432" {
     t.Errorf("Expected ConnectionString 'prod:5432', got %q", dbResult.ConnectionString)
   }
-}
\ No newline at end of file
+}
+// Service identifying which registration produced it
+type VersionedService struct {
+  version string
+}
+
+func (s *VersionedService) DoSomething(ctx context.Context, input string) (string, error) {
+  return s.version + ":" + input, nil
+}
+
+func TestOperationKeepsServiceFromCreationSnapshot(t *testing.T) {
+  registry := commandment.NewServiceRegistry()
+  commandment.RegisterService[TestService](registry, &VersionedService{version: "v1"})
+  bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+  op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+  if err != nil {
+    t.Fatalf("Failed to create operation: %v", err)
+  }
+
+  // Re-register concurrently with execution, as a config reload would
+  var wg sync.WaitGroup
+  wg.Add(1)
+  go func() {
+    defer wg.Done()
+    for range 100 {
+      commandment.RegisterService[TestService](registry, &VersionedService{version: "v2"})
+      _ = registry.GetServiceByType(reflect.TypeOf((*TestService)(nil)).Elem())
+    }
+  }()
+
+  for range 10 {
+    result, err := op.Execute(context.Background())
+    if err != nil {
+      t.Fatalf("Operation execution failed: %v", err)
+    }
+    if result != "v1:x" {
+      t.Errorf("Expected operation to use the service registered at creation, got %q", result)
+    }
+  }
+  wg.Wait()
+
+  later, err := commandment.CreateOperation[*TestOperation](bus, "x")
+  if err != nil {
+    t.Fatalf("Failed to create operation: %v", err)
+  }
+  if result, _ := later.Execute(context.Background()); result != "v2:x" {
+    t.Errorf("Expected operations created after re-registration to use the new service, got %q", result)
+  }
+}
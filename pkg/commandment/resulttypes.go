@@ -0,0 +1,59 @@
+package commandment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// ErrUnknownResultType is returned by DecodeResult for operation type names without
+// a registered result type.
+var ErrUnknownResultType = errors.New("unknown result type")
+
+// typeRegistry maps operation type names to the Go types of their results or params.
+type typeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+func newTypeRegistry() *typeRegistry {
+	return &typeRegistry{types: make(map[string]reflect.Type)}
+}
+
+// clone returns an independent copy of the registry.
+func (r *typeRegistry) clone() *typeRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &typeRegistry{types: maps.Clone(r.types)}
+}
+
+// RegisterResultType records TResult as the result type of operations with the given
+// type name, so DecodeResult can decode their serialized results without the caller
+// knowing the Go type.
+func RegisterResultType[TResult any](bus *OperationBus, typeName string) {
+	bus.resultTypes.mu.Lock()
+	defer bus.resultTypes.mu.Unlock()
+	bus.resultTypes.types[typeName] = reflect.TypeFor[TResult]()
+}
+
+// DecodeResult unmarshals the JSON result of an operation of the named type into
+// its registered result type, returning the decoded value (not a pointer to it).
+func (b *OperationBus) DecodeResult(typeName string, data []byte) (any, error) {
+	b.resultTypes.mu.RLock()
+	resultType, matches := lookupTypeName(b.resultTypes.types, typeName)
+	b.resultTypes.mu.RUnlock()
+	switch {
+	case matches == 0:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownResultType, typeName)
+	case matches > 1:
+		return nil, fmt.Errorf("%w: %q matches result types of several operation types", ErrUnknownResultType, typeName)
+	}
+	result := reflect.New(resultType)
+	if err := json.Unmarshal(data, result.Interface()); err != nil {
+		return nil, fmt.Errorf("decoding %s result: %w", typeName, err)
+	}
+	return result.Elem().Interface(), nil
+}
@@ -0,0 +1,107 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryPolicy bounds the retries of a failing operation.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of executions, including the first.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles after each retry.
+	Backoff time.Duration
+	// RetryableFunc reports whether a failed attempt may be retried. When nil, no
+	// attempt is retried; use IsRetryable for the default classification.
+	RetryableFunc func(error) bool
+}
+
+// DefaultRetryPolicy makes three attempts, waiting 10ms and then 20ms between them,
+// while attempts fail with errors that IsRetryable accepts.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond, RetryableFunc: IsRetryable}
+
+// IsRetryable reports whether err may succeed on retry: an OperationError if it is
+// Retryable, and any other error but ErrNotFound and ErrForbidden.
+func IsRetryable(err error) bool {
+	if opErr, ok := AsOperationError(err); ok {
+		return opErr.Retryable
+	}
+	return !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrForbidden)
+}
+
+// WithRetry wraps op so that each execution is retried under policy while it fails
+// with a retryable error and ctx is not done. It returns the last error when
+// attempts run out.
+func WithRetry[TResult any](op Operation[TResult], policy RetryPolicy) Operation[TResult] {
+	return retryOperation[TResult]{op: op, policy: policy}
+}
+
+// retryOperation retries the wrapped operation under a RetryPolicy.
+type retryOperation[TResult any] struct {
+	op     Operation[TResult]
+	policy RetryPolicy
+	// idempotentOnly limits retries to executions with an idempotency key.
+	idempotentOnly bool
+}
+
+func (r retryOperation[TResult]) Execute(ctx context.Context) (TResult, error) {
+	if r.idempotentOnly && !hasIdempotencyKey(ctx, r.op.Metadata()) {
+		return r.op.Execute(ctx)
+	}
+	return retry(ctx, r.policy, func(ctx context.Context, attempt int) (TResult, error) {
+		return r.op.Execute(ctx)
+	})
+}
+
+func (r retryOperation[TResult]) Metadata() OperationMetadata {
+	return r.op.Metadata()
+}
+
+func (r retryOperation[TResult]) Descriptor() OperationDescriptor {
+	return r.op.Descriptor()
+}
+
+// ExecuteWithRetry executes op as ExecuteOperation does, retrying its business logic
+// under policy. Each failed attempt is logged with its attempt number; middleware,
+// caching and auditing see a single execution.
+func ExecuteWithRetry[T any](ctx context.Context, op OperationWithMetadata, policy RetryPolicy, businessLogic func(context.Context) (T, error)) (T, error) {
+	return ExecuteOperation(ctx, op, func(ctx context.Context) (T, error) {
+		return retry(ctx, policy, func(ctx context.Context, attempt int) (T, error) {
+			result, err := businessLogic(ctx)
+			if err != nil {
+				if exec := executionFromContext(ctx); exec != nil {
+					exec.logger.Warn("Operation attempt failed",
+						exec.logFields("attempt", attempt, "max_attempts", policy.MaxAttempts, "error", err)...)
+				}
+			}
+			return result, err
+		})
+	})
+}
+
+// retry calls fn under policy until it succeeds, fails with an error that is not
+// retryable, ctx is done or attempts run out.
+func retry[T any](ctx context.Context, policy RetryPolicy, fn func(ctx context.Context, attempt int) (T, error)) (T, error) {
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		result, err := fn(ctx, attempt)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(ctx, err) {
+			return result, err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return result, errors.Join(err, ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether err, returned with ctx, may succeed on retry.
+func (p RetryPolicy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || p.RetryableFunc == nil {
+		return false
+	}
+	return p.RetryableFunc(err)
+}
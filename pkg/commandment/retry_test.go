@@ -0,0 +1,152 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Test query retrying its lookup inside a single execution
+type RetryingLookupQuery struct {
+	Params  string
+	Service *UnreliableLookupService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+	Policy  commandment.RetryPolicy
+}
+
+func (q *RetryingLookupQuery) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteWithRetry(ctx, q, q.Policy, func(ctx context.Context) (string, error) {
+		return q.Service.Lookup(ctx, q.Params)
+	})
+}
+
+func (q *RetryingLookupQuery) Metadata() commandment.OperationMetadata {
+	return q.Meta
+}
+
+func (q *RetryingLookupQuery) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "RetryingLookupQuery",
+		Params:   q.Params,
+		Metadata: q.Meta,
+	}
+}
+
+func (q *RetryingLookupQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
+func (q *RetryingLookupQuery) GetLogger() commandment.Logger               { return q.Logger }
+func (q *RetryingLookupQuery) ReadOnly()                                   {}
+
+func newRetryingLookup(t *testing.T, service *UnreliableLookupService, policy commandment.RetryPolicy) (*RetryingLookupQuery, *RecordingLogger) {
+	t.Helper()
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+	query, err := commandment.CreateOperation[*RetryingLookupQuery](bus, "k")
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	query.Policy = policy
+	return query, logger
+}
+
+func TestExecuteWithRetrySucceedsAfterTransientFailures(t *testing.T) {
+	service := &UnreliableLookupService{failures: 2}
+	query, logger := newRetryingLookup(t, service, commandment.RetryPolicy{
+		MaxAttempts:   3,
+		Backoff:       time.Millisecond,
+		RetryableFunc: func(error) bool { return true },
+	})
+
+	result, err := query.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Expected third attempt to succeed: %v", err)
+	}
+	if result != "value of k" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+	if calls := service.Calls(); calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+
+	var attempts []any
+	for _, entry := range logger.Entries("warn") {
+		if entry.Msg == "Operation attempt failed" {
+			attempt, _ := entry.Field("attempt")
+			attempts = append(attempts, attempt)
+		}
+	}
+	if !reflect.DeepEqual(attempts, []any{1, 2}) {
+		t.Errorf("Expected failed attempts 1 and 2 to be logged, got %v", attempts)
+	}
+	if started := countEntries(logger, "Operation execution started"); started != 1 {
+		t.Errorf("Expected retries within a single execution, got %d executions", started)
+	}
+}
+
+func TestExecuteWithRetryOnlyRetriesRetryableErrors(t *testing.T) {
+	service := &UnreliableLookupService{failures: 2}
+	query, _ := newRetryingLookup(t, service, commandment.RetryPolicy{
+		MaxAttempts:   3,
+		Backoff:       time.Millisecond,
+		RetryableFunc: func(error) bool { return false },
+	})
+
+	if _, err := query.Execute(context.Background()); err == nil {
+		t.Fatal("Expected non-retryable failure to be returned")
+	}
+	if calls := service.Calls(); calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+}
+
+func TestExecuteWithRetryWithoutRetryableFuncDoesNotRetry(t *testing.T) {
+	service := &UnreliableLookupService{failures: 2}
+	query, _ := newRetryingLookup(t, service, commandment.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	if _, err := query.Execute(context.Background()); err == nil {
+		t.Fatal("Expected the first failure to be returned")
+	}
+	if calls := service.Calls(); calls != 1 {
+		t.Errorf("Expected a policy without RetryableFunc not to retry, got %d calls", calls)
+	}
+}
+
+func TestExecuteWithRetryStopsWhenContextCancelled(t *testing.T) {
+	service := &UnreliableLookupService{failures: 5}
+	query, _ := newRetryingLookup(t, service, commandment.RetryPolicy{
+		MaxAttempts:   5,
+		Backoff:       time.Second,
+		RetryableFunc: commandment.IsRetryable,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := query.Execute(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected backoff to be cut short by cancellation, took %v", elapsed)
+	}
+	if calls := service.Calls(); calls != 1 {
+		t.Errorf("Expected no attempts after cancellation, got %d", calls)
+	}
+}
+
+func countEntries(logger *RecordingLogger, msg string) int {
+	n := 0
+	for _, level := range []string{"info", "warn", "error"} {
+		for _, entry := range logger.Entries(level) {
+			if entry.Msg == msg {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,54 @@
+package commandment
+
+import (
+	"context"
+	"fmt"
+)
+
+// Saga executes a sequence of commands, each paired with a compensation. If a step
+// fails, the steps that already completed are compensated in reverse order.
+type Saga struct {
+	bus   *OperationBus
+	steps []sagaStep
+}
+
+// sagaStep pairs a command with the compensation reversing its effect.
+type sagaStep struct {
+	op         any
+	compensate func(ctx context.Context, result any) error
+}
+
+// NewSaga creates an empty Saga executing its steps on bus.
+func NewSaga(bus *OperationBus) *Saga {
+	return &Saga{bus: bus}
+}
+
+// Step appends op, compensated with compensate, which receives op's result, should a
+// later step fail. With a nil compensate, op is compensated with its Undo method if
+// it implements Undoable, and not at all otherwise.
+func (s *Saga) Step(op any, compensate func(ctx context.Context, result any) error) *Saga {
+	s.steps = append(s.steps, sagaStep{op: op, compensate: compensate})
+	return s
+}
+
+// Run executes the steps in order as a Transaction on the saga's bus. If a step
+// fails, the completed steps are compensated in reverse order, each compensation
+// being logged, and the returned error joins the step's failure with any
+// compensation failures. A saga aborted by ctx's cancellation or deadline is still
+// compensated.
+func (s *Saga) Run(ctx context.Context) error {
+	return s.bus.Transaction(ctx, func(tx *Tx) error {
+		for i, step := range s.steps {
+			var err error
+			if step.compensate == nil {
+				_, err = tx.Execute(step.op)
+			} else {
+				_, err = tx.ExecuteWithCompensation(step.op, step.compensate)
+			}
+			if err != nil {
+				return fmt.Errorf("saga step %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,120 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestSagaCompensatesFirstStepWhenSecondFails(t *testing.T) {
+	bus, records, logger := newSagaBus()
+	write, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	reserve, err := commandment.CreateOperation[*ReserveCommand](bus, "unavailable-item")
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+
+	err = commandment.NewSaga(bus).
+		Step(write, func(ctx context.Context, result any) error {
+			records.records["k"] = "v1"
+			return nil
+		}).
+		Step(reserve, nil).
+		Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "saga step 1") {
+		t.Fatalf("Expected the second step's failure, got %v", err)
+	}
+
+	if records.records["k"] != "v1" {
+		t.Errorf("Expected first step compensated, got %q", records.records["k"])
+	}
+	entry, ok := logger.Find("Operation compensated")
+	if !ok {
+		t.Fatal("Expected the compensation to be logged")
+	}
+	if id, _ := entry.Field("operation_id"); id != write.Meta.UUID {
+		t.Errorf("Expected compensation logged for %s, got %v", write.Meta.UUID, id)
+	}
+}
+
+func TestSagaSurfacesOriginalAndCompensationErrors(t *testing.T) {
+	bus, _, logger := newSagaBus()
+	compensationErr := errors.New("compensation failed")
+	write, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	reserve, err := commandment.CreateOperation[*ReserveCommand](bus, "unavailable-item")
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+
+	err = commandment.NewSaga(bus).
+		Step(write, func(ctx context.Context, result any) error { return compensationErr }).
+		Step(reserve, nil).
+		Run(context.Background())
+
+	if !errors.Is(err, compensationErr) || !strings.Contains(err.Error(), "unavailable-item") {
+		t.Errorf("Expected both the step and compensation errors, got %v", err)
+	}
+	if _, ok := logger.Find("Operation compensation failed"); !ok {
+		t.Error("Expected the failed compensation to be logged")
+	}
+}
+
+func TestSagaCompensatesUndoableStepsWithoutCompensationFunc(t *testing.T) {
+	bus, inventory := newInventoryBus()
+	saga := commandment.NewSaga(bus)
+	for _, item := range []string{"first", "second", "unavailable-third"} {
+		op, err := commandment.CreateOperation[*ReserveCommand](bus, item)
+		if err != nil {
+			t.Fatalf("Failed to create command: %v", err)
+		}
+		saga.Step(op, nil)
+	}
+
+	if err := saga.Run(context.Background()); err == nil {
+		t.Fatal("Expected the saga to fail")
+	}
+	expectedLog := []string{"reserve:first", "reserve:second", "release:second", "release:first"}
+	if !reflect.DeepEqual(inventory.log, expectedLog) {
+		t.Errorf("Expected log %v, got %v", expectedLog, inventory.log)
+	}
+}
+
+func TestSagaAbortedByDeadlineIsCompensated(t *testing.T) {
+	inventory := &InventoryService{reserved: make(map[string]bool)}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, inventory)
+	commandment.RegisterService[TestService](registry, &SlowService{delay: time.Second})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	reserve, err := commandment.CreateOperation[*ReserveCommand](bus, "first")
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	slow, err := commandment.CreateOperation[*TestOperation](bus, "slow")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = commandment.NewSaga(bus).Step(reserve, nil).Step(slow, nil).Run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the saga to time out, got %v", err)
+	}
+
+	expectedLog := []string{"reserve:first", "release:first"}
+	if !reflect.DeepEqual(inventory.log, expectedLog) {
+		t.Errorf("Expected log %v, got %v", expectedLog, inventory.log)
+	}
+}
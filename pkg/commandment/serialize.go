@@ -0,0 +1,113 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrSerializationKeyBusy is returned when an operation's serialization key is held
+// by another execution and no more executions may queue for it.
+var ErrSerializationKeyBusy = errors.New("operation serialization key busy")
+
+// SerializationKeyer is implemented by operations that must not run concurrently
+// with other operations returning the same key, such as commands on one aggregate.
+// An empty key is not serialized.
+type SerializationKeyer interface {
+	SerializationKey() string
+}
+
+// WithSerializationKeys serializes the execution of operations sharing a
+// SerializationKey. While a key is held, up to maxQueue further executions wait for
+// it and then run in submission (FIFO) order; beyond that they fail with
+// ErrSerializationKeyBusy. A maxQueue of zero rejects every execution while the key
+// is held. Waiting executions give up when their context is done.
+func WithSerializationKeys(maxQueue int) Option {
+	return func(b *OperationBus) {
+		b.serializer = &serializer{maxQueue: maxQueue, keys: make(map[string]*keyQueue)}
+	}
+}
+
+// acquireSerialization waits for op's serialization key, returning a func that
+// releases it. It is a no-op on a nil bus or one without serialization keys.
+func (b *OperationBus) acquireSerialization(ctx context.Context, op any) (release func(), err error) {
+	keyer, ok := op.(SerializationKeyer)
+	if b == nil || b.serializer == nil || !ok || keyer.SerializationKey() == "" {
+		return func() {}, nil
+	}
+	return b.serializer.acquire(ctx, keyer.SerializationKey())
+}
+
+// serializer grants each serialization key to one execution at a time.
+type serializer struct {
+	maxQueue int
+
+	mu   sync.Mutex
+	keys map[string]*keyQueue // held keys
+}
+
+// keyQueue holds the executions waiting for a key, in arrival order.
+type keyQueue struct {
+	waiters []chan struct{}
+}
+
+func (s *serializer) acquire(ctx context.Context, key string) (func(), error) {
+	release := func() { s.release(key) }
+	s.mu.Lock()
+	q, held := s.keys[key]
+	if !held {
+		s.keys[key] = &keyQueue{}
+		s.mu.Unlock()
+		return release, nil
+	}
+	if len(q.waiters) >= s.maxQueue {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %q", ErrSerializationKeyBusy, key)
+	}
+	granted := make(chan struct{})
+	q.waiters = append(q.waiters, granted)
+	s.mu.Unlock()
+
+	select {
+	case <-granted:
+		return release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		i := slices.Index(q.waiters, granted)
+		if i >= 0 {
+			q.waiters = slices.Delete(q.waiters, i, i+1)
+		}
+		s.mu.Unlock()
+		if i < 0 {
+			// Granted while giving up: pass the key on.
+			s.release(key)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release hands key to the next waiting execution, or frees it.
+func (s *serializer) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.keys[key]
+	if len(q.waiters) == 0 {
+		delete(s.keys, key)
+		return
+	}
+	next := q.waiters[0]
+	q.waiters = q.waiters[1:]
+	close(next)
+}
+
+// queued returns the number of executions waiting for key.
+func (s *serializer) queued(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if q, ok := s.keys[key]; ok {
+		return len(q.waiters)
+	}
+	return 0
+}
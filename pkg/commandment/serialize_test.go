@@ -0,0 +1,159 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Account service recording the order of postings, holding the first until released
+type LedgerService struct {
+	mu      sync.Mutex
+	order   []string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *LedgerService) Post(ctx context.Context, params PostingParams) (string, error) {
+	if params.Hold {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order = append(s.order, params.Name)
+	return params.Name, nil
+}
+
+// Params for PostingCommand
+type PostingParams struct {
+	Account string
+	Name    string
+	Hold    bool
+}
+
+// Test command serialized per account
+type PostingCommand struct {
+	Params  PostingParams
+	Service *LedgerService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *PostingCommand) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, c, func(ctx context.Context) (string, error) {
+		return c.Service.Post(ctx, c.Params)
+	})
+}
+
+func (c *PostingCommand) Metadata() commandment.OperationMetadata {
+	return c.Meta
+}
+
+func (c *PostingCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "PostingCommand",
+		Params:   c.Params,
+		Metadata: c.Meta,
+	}
+}
+
+func (c *PostingCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *PostingCommand) GetLogger() commandment.Logger               { return c.Logger }
+func (c *PostingCommand) SerializationKey() string                    { return c.Params.Account }
+
+func newLedgerBus(maxQueue int) (*commandment.OperationBus, *LedgerService) {
+	ledger := &LedgerService{started: make(chan struct{}), release: make(chan struct{})}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, ledger)
+	return commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithSerializationKeys(maxQueue)), ledger
+}
+
+func post(t *testing.T, bus *commandment.OperationBus, params PostingParams) error {
+	t.Helper()
+	cmd, err := commandment.CreateOperation[*PostingCommand](bus, params)
+	if err != nil {
+		t.Errorf("Failed to create command: %v", err)
+		return err
+	}
+	_, err = cmd.Execute(context.Background())
+	return err
+}
+
+// waitQueued waits until n executions are queued for key.
+func waitQueued(t *testing.T, bus *commandment.OperationBus, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for commandment.QueuedForSerializationKey(bus, key) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d executions queued for %q", n, key)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSerializationKeyQueuesInSubmissionOrder(t *testing.T) {
+	bus, ledger := newLedgerBus(10)
+
+	var wg sync.WaitGroup
+	submit := func(params PostingParams) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := post(t, bus, params); err != nil {
+				t.Errorf("Posting %s failed: %v", params.Name, err)
+			}
+		}()
+	}
+
+	submit(PostingParams{Account: "acct", Name: "first", Hold: true})
+	<-ledger.started
+	for i := range 5 {
+		submit(PostingParams{Account: "acct", Name: fmt.Sprint("queued-", i)})
+		waitQueued(t, bus, "acct", i+1)
+	}
+	if err := post(t, bus, PostingParams{Account: "other", Name: "unrelated"}); err != nil {
+		t.Errorf("Expected other keys not to wait: %v", err)
+	}
+	close(ledger.release)
+	wg.Wait()
+
+	expected := []string{"unrelated", "first", "queued-0", "queued-1", "queued-2", "queued-3", "queued-4"}
+	if !reflect.DeepEqual(ledger.order, expected) {
+		t.Errorf("Expected order %v, got %v", expected, ledger.order)
+	}
+}
+
+func TestSerializationKeyRejectsBeyondMaxQueue(t *testing.T) {
+	bus, ledger := newLedgerBus(1)
+
+	var wg sync.WaitGroup
+	for _, params := range []PostingParams{
+		{Account: "acct", Name: "first", Hold: true},
+		{Account: "acct", Name: "queued"},
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := post(t, bus, params); err != nil {
+				t.Errorf("Posting %s failed: %v", params.Name, err)
+			}
+		}()
+		if params.Hold {
+			<-ledger.started
+		}
+	}
+	waitQueued(t, bus, "acct", 1)
+
+	if err := post(t, bus, PostingParams{Account: "acct", Name: "overflow"}); !errors.Is(err, commandment.ErrSerializationKeyBusy) {
+		t.Errorf("Expected ErrSerializationKeyBusy beyond the queue limit, got %v", err)
+	}
+	close(ledger.release)
+	wg.Wait()
+}
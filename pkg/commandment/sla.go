@@ -0,0 +1,69 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// slaBudgetKey is the context key for the end-to-end SLA budget
+	slaBudgetKey contextKey = "commandment:sla:budget"
+	// slaBudgetActiveKey marks a context already executing against the SLA budget
+	slaBudgetActiveKey contextKey = "commandment:sla:active"
+)
+
+// ErrSLABudgetExhausted is returned, without executing the operation, once the SLA
+// budget in context has been used up.
+var ErrSLABudgetExhausted = errors.New("SLA budget exhausted")
+
+// slaBudget is the time left for the remaining operations of a request.
+type slaBudget struct {
+	mu        sync.Mutex
+	remaining time.Duration
+}
+
+// WithSLABudget returns a context carrying an end-to-end time budget of total. Each
+// operation executed with it runs under a deadline of the remaining budget and, when
+// done, consumes its elapsed time from it. Operations executed once the budget is
+// used up fail fast with ErrSLABudgetExhausted. Operations executed by a budgeted
+// operation share its deadline and don't consume the budget again.
+func WithSLABudget(ctx context.Context, total time.Duration) context.Context {
+	return context.WithValue(ctx, slaBudgetKey, &slaBudget{remaining: total})
+}
+
+// SLABudgetRemaining returns the unconsumed budget in ctx, if it carries one.
+func SLABudgetRemaining(ctx context.Context) (time.Duration, bool) {
+	budget, ok := ctx.Value(slaBudgetKey).(*slaBudget)
+	if !ok {
+		return 0, false
+	}
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+	return budget.remaining, true
+}
+
+// startSLABudget derives the execution deadline from the budget in ctx. The returned
+// func consumes the execution's elapsed time from the budget.
+func startSLABudget(ctx context.Context) (context.Context, func(), error) {
+	budget, ok := ctx.Value(slaBudgetKey).(*slaBudget)
+	if !ok || ctx.Value(slaBudgetActiveKey) != nil {
+		return ctx, func() {}, nil
+	}
+	remaining, _ := SLABudgetRemaining(ctx)
+	if remaining <= 0 {
+		return ctx, nil, fmt.Errorf("%w: overspent by %v", ErrSLABudgetExhausted, -remaining)
+	}
+
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, remaining)
+	ctx = context.WithValue(ctx, slaBudgetActiveKey, true)
+	return ctx, func() {
+		cancel()
+		budget.mu.Lock()
+		defer budget.mu.Unlock()
+		budget.remaining -= time.Since(started)
+	}, nil
+}
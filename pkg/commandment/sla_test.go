@@ -0,0 +1,60 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func TestSLABudgetConsumedUntilExhausted(t *testing.T) {
+	bus := newSlowBus(40*time.Millisecond, time.Second)
+	ctx := commandment.WithSLABudget(context.Background(), 100*time.Millisecond)
+
+	execute := func() error {
+		op, err := commandment.CreateOperation[*TestOperation](bus, "step")
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		_, err = op.Execute(ctx)
+		return err
+	}
+
+	for i := range 2 {
+		if err := execute(); err != nil {
+			t.Fatalf("Expected operation %d to fit in the budget: %v", i+1, err)
+		}
+	}
+	if remaining, _ := commandment.SLABudgetRemaining(ctx); remaining > 20*time.Millisecond {
+		t.Errorf("Expected operations to consume the budget, %v remaining", remaining)
+	}
+
+	if err := execute(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected operation to time out at the remaining budget, got %v", err)
+	}
+
+	start := time.Now()
+	if err := execute(); !errors.Is(err, commandment.ErrSLABudgetExhausted) {
+		t.Fatalf("Expected ErrSLABudgetExhausted, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("Expected exhausted budget to fail fast, took %v", elapsed)
+	}
+}
+
+func TestSLABudgetAbsentLeavesExecutionUnbounded(t *testing.T) {
+	bus := newSlowBus(10*time.Millisecond, time.Second)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "step")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if _, ok := commandment.SLABudgetRemaining(context.Background()); ok {
+		t.Error("Expected no budget without WithSLABudget")
+	}
+}
@@ -0,0 +1,41 @@
+package commandment
+
+import (
+	"context"
+	"fmt"
+)
+
+// snapshotKey names a context key whose value is recorded in descriptor extras.
+type snapshotKey struct {
+	name string
+	key  any
+}
+
+// WithContextSnapshot allowlists a context key whose value, when present at
+// execution, is recorded under name in the operation's metadata Extras, so it is
+// persisted with the descriptor. Context values not allowlisted are never captured.
+func WithContextSnapshot(name string, key any) Option {
+	return func(b *OperationBus) {
+		b.snapshotKeys = append(b.snapshotKeys, snapshotKey{name: name, key: key})
+	}
+}
+
+// snapshotContext returns the allowlisted context values of ctx, or nil if there
+// are none. It returns nil on a nil bus.
+func (b *OperationBus) snapshotContext(ctx context.Context) map[string]string {
+	if b == nil {
+		return nil
+	}
+	var extras map[string]string
+	for _, k := range b.snapshotKeys {
+		v := ctx.Value(k.key)
+		if v == nil {
+			continue
+		}
+		if extras == nil {
+			extras = make(map[string]string, len(b.snapshotKeys))
+		}
+		extras[k.name] = fmt.Sprint(v)
+	}
+	return extras
+}
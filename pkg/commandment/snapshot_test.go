@@ -0,0 +1,68 @@
+package commandment_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+type (
+	tenantKey    struct{}
+	authTokenKey struct{}
+)
+
+func TestContextSnapshotCapturesOnlyAllowlistedKeys(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{},
+		commandment.WithContextSnapshot("tenant", tenantKey{}),
+	)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = context.WithValue(ctx, authTokenKey{}, "secret")
+	if _, err := op.Execute(ctx); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	data, err := json.Marshal(op.Descriptor())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor: %v", err)
+	}
+	var persisted commandment.OperationDescriptor
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		t.Fatalf("Failed to unmarshal descriptor: %v", err)
+	}
+
+	extras := persisted.Metadata.Extras
+	if extras["tenant"] != "acme" {
+		t.Errorf("Expected allowlisted tenant to be captured, got %v", extras)
+	}
+	if len(extras) != 1 {
+		t.Errorf("Expected only allowlisted keys in extras, got %v", extras)
+	}
+}
+
+func TestContextSnapshotOmittedWithoutAllowlist(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	if _, err := op.Execute(ctx); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	if extras := op.Descriptor().Metadata.Extras; extras != nil {
+		t.Errorf("Expected no extras without an allowlist, got %v", extras)
+	}
+}
@@ -0,0 +1,34 @@
+package commandment
+
+import (
+	"context"
+	"fmt"
+)
+
+// StreamEncoder encodes streamed operation results, one value per call. It is
+// satisfied by *json.Encoder and by encoders writing frames to a websocket or
+// other message-oriented transport.
+type StreamEncoder interface {
+	Encode(v any) error
+}
+
+// ExecuteStream executes a streaming operation, pushing each value passed to emit
+// through enc as it is produced. It wraps ExecuteOperation, so streaming operations
+// get the same context enrichment, middleware and logging as any other operation.
+// It returns the number of values encoded; encoding stops at the first error.
+func ExecuteStream[T any](ctx context.Context, op OperationWithMetadata, enc StreamEncoder, stream func(ctx context.Context, emit func(T) error) error) (int, error) {
+	return ExecuteOperation(ctx, op, func(ctx context.Context) (int, error) {
+		count := 0
+		err := stream(ctx, func(v T) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := enc.Encode(v); err != nil {
+				return fmt.Errorf("encoding streamed result %d: %w", count, err)
+			}
+			count++
+			return nil
+		})
+		return count, err
+	})
+}
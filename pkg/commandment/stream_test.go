@@ -0,0 +1,145 @@
+package commandment_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Test node streamed by StreamNodesQuery
+type StreamedNode struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Service producing nodes one at a time
+type NodeStreamService struct {
+	nodes []StreamedNode
+}
+
+func (s *NodeStreamService) Each(ctx context.Context, emit func(StreamedNode) error) error {
+	for _, node := range s.nodes {
+		if err := emit(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Test query streaming nodes through an encoder
+type StreamNodesQuery struct {
+	Params  string
+	Service *NodeStreamService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (q *StreamNodesQuery) Stream(ctx context.Context, enc commandment.StreamEncoder) (int, error) {
+	return commandment.ExecuteStream(ctx, q, enc, q.Service.Each)
+}
+
+func (q *StreamNodesQuery) Execute(ctx context.Context) (int, error) {
+	return q.Stream(ctx, json.NewEncoder(&bytes.Buffer{}))
+}
+
+func (q *StreamNodesQuery) Metadata() commandment.OperationMetadata {
+	return q.Meta
+}
+
+func (q *StreamNodesQuery) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "StreamNodesQuery",
+		Params:   q.Params,
+		Metadata: q.Meta,
+	}
+}
+
+func (q *StreamNodesQuery) GetMetadata() *commandment.OperationMetadata { return &q.Meta }
+func (q *StreamNodesQuery) GetLogger() commandment.Logger               { return q.Logger }
+func (q *StreamNodesQuery) ReadOnly()                                   {}
+
+// Encoder recording every value it is asked to encode
+type RecordingEncoder struct {
+	values []any
+	failAt int // fail on this call (1-based); zero never fails
+}
+
+func (e *RecordingEncoder) Encode(v any) error {
+	if e.failAt > 0 && len(e.values)+1 == e.failAt {
+		return errors.New("connection closed")
+	}
+	e.values = append(e.values, v)
+	return nil
+}
+
+func newStreamNodesQuery(t *testing.T) *StreamNodesQuery {
+	t.Helper()
+	service := &NodeStreamService{nodes: []StreamedNode{
+		{ID: "1", Title: "Inbox"},
+		{ID: "2", Title: "Groceries"},
+		{ID: "3", Title: "Errands"},
+	}}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	query, err := commandment.CreateOperation[*StreamNodesQuery](bus, "root")
+	if err != nil {
+		t.Fatalf("Failed to create query: %v", err)
+	}
+	return query
+}
+
+func TestExecuteStreamEncodesEachNode(t *testing.T) {
+	query := newStreamNodesQuery(t)
+	enc := &RecordingEncoder{}
+
+	count, err := query.Stream(context.Background(), enc)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if count != 3 || len(enc.values) != 3 {
+		t.Fatalf("Expected 3 nodes encoded, got count %d and %d values", count, len(enc.values))
+	}
+	for i, want := range query.Service.nodes {
+		if enc.values[i] != want {
+			t.Errorf("Expected node %d to be %+v, got %+v", i, want, enc.values[i])
+		}
+	}
+}
+
+func TestExecuteStreamWithJSONEncoder(t *testing.T) {
+	query := newStreamNodesQuery(t)
+	var buf bytes.Buffer
+
+	if _, err := query.Stream(context.Background(), json.NewEncoder(&buf)); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 JSON frames, got %d: %q", len(lines), buf.String())
+	}
+	if lines[1] != `{"id":"2","title":"Groceries"}` {
+		t.Errorf("Unexpected frame: %s", lines[1])
+	}
+}
+
+func TestExecuteStreamStopsOnEncoderError(t *testing.T) {
+	query := newStreamNodesQuery(t)
+	enc := &RecordingEncoder{failAt: 2}
+
+	count, err := query.Stream(context.Background(), enc)
+	if err == nil {
+		t.Fatal("Expected encoder error to fail the stream")
+	}
+	if count != 1 || len(enc.values) != 1 {
+		t.Errorf("Expected streaming to stop after 1 node, got count %d and %d values", count, len(enc.values))
+	}
+}
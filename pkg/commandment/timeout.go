@@ -0,0 +1,120 @@
+package commandment
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// WithServiceTimeout bounds the business logic of every operation created by the bus
+// to d. Only the service call is timed: middleware, logging and other framework work
+// around it don't count against the budget. The service sees the deadline on its
+// context and should return once the context is done.
+func WithServiceTimeout(d time.Duration) Option {
+	return func(b *OperationBus) {
+		b.serviceTimeout = d
+	}
+}
+
+// WithDefaultTimeout bounds the whole execution of every operation created by the
+// bus to d, including middleware, and cancels the business logic when it elapses.
+// Operations that time out fail with context.DeadlineExceeded.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(b *OperationBus) {
+		b.defaultTimeout = d
+	}
+}
+
+// typeTimeouts maps operation type names to the timeouts set with SetTimeout.
+type typeTimeouts struct {
+	mu       sync.RWMutex
+	timeouts map[string]time.Duration
+}
+
+func newTypeTimeouts() *typeTimeouts {
+	return &typeTimeouts{timeouts: make(map[string]time.Duration)}
+}
+
+// clone returns an independent copy of the table.
+func (t *typeTimeouts) clone() *typeTimeouts {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return &typeTimeouts{timeouts: maps.Clone(t.timeouts)}
+}
+
+// SetTimeout bounds the whole execution of operations of the named type to d, as
+// WithDefaultTimeout does for every operation, taking precedence over the default
+// timeout. typeName is the short or qualified type name; a d of zero or less
+// removes the type's timeout, falling back to the default.
+func (b *OperationBus) SetTimeout(typeName string, d time.Duration) {
+	b.typeTimeouts.mu.Lock()
+	defer b.typeTimeouts.mu.Unlock()
+	if d <= 0 {
+		delete(b.typeTimeouts.timeouts, typeName)
+		return
+	}
+	b.typeTimeouts.timeouts[typeName] = d
+}
+
+// executionTimeout returns the timeout set for op's type with SetTimeout, or else
+// the bus default timeout. It returns zero on a nil bus.
+func (b *OperationBus) executionTimeout(op any) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.typeTimeouts.mu.RLock()
+	defer b.typeTimeouts.mu.RUnlock()
+	if d, ok := b.typeTimeouts.timeouts[operationTypeName(op)]; ok {
+		return d
+	}
+	if d, ok := b.typeTimeouts.timeouts[QualifiedTypeName(op)]; ok {
+		return d
+	}
+	return b.defaultTimeout
+}
+
+// withExecutionDeadline applies timeout to ctx, returning ctx unchanged for a
+// timeout of zero or less.
+func withExecutionDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// withServiceDeadline applies the bus service timeout to ctx. It returns ctx
+// unchanged on a nil bus or one without a service timeout.
+func (b *OperationBus) withServiceDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b == nil || b.serviceTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, b.serviceTimeout)
+}
+
+// WithTimeout wraps op so that each execution is bounded by d, keeping op's result
+// type. Unlike WithServiceTimeout, which applies to every operation on a bus, it
+// applies to a single operation and covers its whole execution.
+func WithTimeout[TResult any](op Operation[TResult], d time.Duration) Operation[TResult] {
+	return timeoutOperation[TResult]{op: op, timeout: d}
+}
+
+// timeoutOperation bounds the execution of the wrapped operation.
+type timeoutOperation[TResult any] struct {
+	op      Operation[TResult]
+	timeout time.Duration
+}
+
+func (t timeoutOperation[TResult]) Execute(ctx context.Context) (TResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	return t.op.Execute(ctx)
+}
+
+func (t timeoutOperation[TResult]) Metadata() OperationMetadata {
+	return t.op.Metadata()
+}
+
+func (t timeoutOperation[TResult]) Descriptor() OperationDescriptor {
+	return t.op.Descriptor()
+}
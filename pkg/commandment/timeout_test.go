@@ -0,0 +1,213 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service that takes a fixed time unless its context is done first
+type SlowService struct {
+	delay time.Duration
+}
+
+func (s *SlowService) DoSomething(ctx context.Context, input string) (string, error) {
+	select {
+	case <-time.After(s.delay):
+		return "done: " + input, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func newSlowBus(delay, timeout time.Duration) *commandment.OperationBus {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &SlowService{delay: delay})
+	return commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithServiceTimeout(timeout))
+}
+
+func TestServiceTimeoutCancelsServiceCall(t *testing.T) {
+	bus := newSlowBus(time.Second, 20*time.Millisecond)
+
+	var after bool
+	bus.Use(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			result, err := next(ctx)
+			after = true
+			if ctx.Err() != nil {
+				t.Errorf("Expected middleware context to outlive the service timeout, got %v", ctx.Err())
+			}
+			return result, err
+		}
+	})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "slow")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	start := time.Now()
+	_, err = op.Execute(context.Background())
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected service call to be cancelled at the timeout, took %v", elapsed)
+	}
+	if !after {
+		t.Error("Expected middleware to run after the service call was cancelled")
+	}
+}
+
+func TestServiceTimeoutExcludesMiddleware(t *testing.T) {
+	bus := newSlowBus(5*time.Millisecond, 50*time.Millisecond)
+	bus.Use(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			time.Sleep(80 * time.Millisecond)
+			return next(ctx)
+		}
+	})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "fast")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := op.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Expected slow middleware not to consume the service budget: %v", err)
+	}
+	if result != "done: fast" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+}
+
+func TestWithTimeoutBoundsSingleOperation(t *testing.T) {
+	bus := newSlowBus(time.Second, 0)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "slow")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	var wrapped commandment.Operation[string] = commandment.WithTimeout[string](op, 20*time.Millisecond)
+
+	if _, err := wrapped.Execute(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if wrapped.Descriptor().Type != "TestOperation" || wrapped.Metadata().UUID != op.Metadata().UUID {
+		t.Error("Expected wrapper to expose the wrapped operation's descriptor and metadata")
+	}
+}
+
+func TestDefaultTimeoutCancelsExecution(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &SlowService{delay: time.Second})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger, commandment.WithDefaultTimeout(20*time.Millisecond))
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "slow")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	start := time.Now()
+	if _, err := op.Execute(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected execution to be cancelled at the timeout, took %v", elapsed)
+	}
+	if op.Meta.Returned.IsZero() {
+		t.Error("Expected Returned to be recorded for a timed-out operation")
+	}
+	if _, ok := logger.Find("Operation execution failed"); !ok {
+		t.Error("Expected timed-out execution to be logged as failed")
+	}
+}
+
+func TestCancelledContextSkipsService(t *testing.T) {
+	service := &CountingService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := op.Execute(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if calls := service.calls.Load(); calls != 0 {
+		t.Errorf("Expected cancelled operation not to call the service, got %d calls", calls)
+	}
+	if op.Meta.Returned.IsZero() {
+		t.Error("Expected Returned to be recorded for a cancelled operation")
+	}
+	entry, ok := logger.Find("Operation execution rejected")
+	if !ok {
+		t.Fatal("Expected cancellation to be logged")
+	}
+	if _, ok := entry.Field("duration_ms"); !ok {
+		t.Error("Expected rejection log to include duration_ms")
+	}
+}
+
+func TestSetTimeoutAppliesPerOperationType(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &SlowService{delay: 60 * time.Millisecond})
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger, commandment.WithDefaultTimeout(time.Second))
+	bus.SetTimeout("TestOperation", 20*time.Millisecond)
+	bus.SetTimeout(commandment.QualifiedTypeName(&OptionalParamsOperation{}), 500*time.Millisecond)
+
+	short, err := commandment.CreateOperation[*TestOperation](bus, "slow")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := short.Execute(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected TestOperation to time out, got %v", err)
+	}
+	entry, ok := logger.Find("Operation execution failed")
+	if !ok {
+		t.Fatal("Expected the timeout to be logged")
+	}
+	if budget, _ := entry.Field("timeout"); budget != 20*time.Millisecond {
+		t.Errorf("Expected the configured budget logged, got %v", budget)
+	}
+
+	input := "slow"
+	long, err := commandment.CreateOperation[*OptionalParamsOperation](bus, &input)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if result, err := long.Execute(context.Background()); err != nil || result != "done: slow" {
+		t.Errorf("Expected OptionalParamsOperation to finish within its longer budget, got %q, %v", result, err)
+	}
+}
+
+func TestSetTimeoutFallsBackToDefaultTimeout(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &SlowService{delay: time.Second})
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithDefaultTimeout(20*time.Millisecond))
+	bus.SetTimeout("OptionalParamsOperation", 5*time.Second)
+	bus.SetTimeout("OptionalParamsOperation", 0)
+
+	input := "slow"
+	op, err := commandment.CreateOperation[*OptionalParamsOperation](bus, &input)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	start := time.Now()
+	if _, err := op.Execute(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the default timeout to apply, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected execution cancelled at the default timeout, took %v", elapsed)
+	}
+}
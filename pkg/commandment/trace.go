@@ -0,0 +1,79 @@
+package commandment
+
+import "context"
+
+// sampledKey is the context key for the trace sampling decision
+const sampledKey contextKey = "commandment:trace:sampled"
+
+// Span is a unit of traced work started by a Tracer.
+type Span interface {
+	End(err error)
+}
+
+// Tracer starts spans around operation execution, typically by adapting a tracing
+// library such as OpenTelemetry.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Sampler decides whether an execution without an upstream sampling decision is traced.
+type Sampler func(descriptor OperationDescriptor) bool
+
+// WithTracer traces operation executions with tracer. Executions rejected before they
+// start, such as by validation or rate limiting, are traced too, their span ending
+// with the rejection error. The sampling decision is made once, by sampler, for an
+// execution whose context carries no decision; it is then propagated so child
+// operations follow the root's decision instead of re-sampling. A nil sampler
+// samples every execution.
+func WithTracer(tracer Tracer, sampler Sampler) Option {
+	return func(b *OperationBus) {
+		b.tracer = tracer
+		b.sampler = sampler
+	}
+}
+
+// WithSampled records an upstream sampling decision, such as one received from an
+// incoming request's trace headers, for operations executed with ctx.
+func WithSampled(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, sampledKey, sampled)
+}
+
+// SampledFromContext retrieves the sampling decision from context. The second value
+// is false if no decision has been made.
+func SampledFromContext(ctx context.Context) (sampled, ok bool) {
+	sampled, ok = ctx.Value(sampledKey).(bool)
+	return sampled, ok
+}
+
+// noopSpan is the span of an untraced execution.
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
+
+// startSpan makes or inherits the sampling decision for an execution and starts a
+// span if it is sampled. The decision is recorded in the returned context.
+func (b *OperationBus) startSpan(ctx context.Context, op any, name string) (context.Context, Span) {
+	if b == nil || b.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	sampled, ok := SampledFromContext(ctx)
+	if !ok {
+		sampled = b.sample(op, name)
+		ctx = WithSampled(ctx, sampled)
+	}
+	if !sampled {
+		return ctx, noopSpan{}
+	}
+	return b.tracer.Start(ctx, name)
+}
+
+func (b *OperationBus) sample(op any, name string) bool {
+	if b.sampler == nil {
+		return true
+	}
+	descriptor := OperationDescriptor{Type: name}
+	if d, ok := op.(Describer); ok {
+		descriptor = d.Descriptor()
+	}
+	return b.sampler(descriptor)
+}
@@ -0,0 +1,96 @@
+package commandment_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Tracer recording the names of started spans
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+type recordingSpan struct{}
+
+func (recordingSpan) End(error) {}
+
+func (t *RecordingTracer) Start(ctx context.Context, name string) (context.Context, commandment.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, name)
+	return ctx, recordingSpan{}
+}
+
+func (t *RecordingTracer) Spans() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.spans...)
+}
+
+func newTracedBus(sampler commandment.Sampler) (*commandment.OperationBus, *RecordingTracer) {
+	tracer := &RecordingTracer{}
+	registry := commandment.NewServiceRegistry()
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithTracer(tracer, sampler))
+	commandment.RegisterService(registry, &ParentService{bus: bus})
+	commandment.RegisterService(registry, &ChildService{calls: make(map[string]int)})
+	return bus, tracer
+}
+
+func runParent(ctx context.Context, t *testing.T, bus *commandment.OperationBus) {
+	t.Helper()
+	op, err := commandment.CreateOperation[*ParentOperation](bus, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(ctx); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+}
+
+func TestSampledOutRootSuppressesChildSpans(t *testing.T) {
+	bus, tracer := newTracedBus(nil)
+
+	runParent(commandment.WithSampled(context.Background(), false), t, bus)
+
+	if spans := tracer.Spans(); len(spans) != 0 {
+		t.Errorf("Expected no spans for a sampled-out root, got %v", spans)
+	}
+}
+
+func TestSampledRootTracesChildren(t *testing.T) {
+	bus, tracer := newTracedBus(nil)
+
+	runParent(commandment.WithSampled(context.Background(), true), t, bus)
+
+	expected := []string{"ParentOperation", "ChildOperation", "ChildOperation"}
+	spans := tracer.Spans()
+	if len(spans) != len(expected) {
+		t.Fatalf("Expected spans %v, got %v", expected, spans)
+	}
+	for i := range expected {
+		if spans[i] != expected[i] {
+			t.Errorf("Expected span %d to be %q, got %q", i, expected[i], spans[i])
+		}
+	}
+}
+
+func TestSamplerDecidesOnlyAtRoot(t *testing.T) {
+	var decisions []string
+	bus, tracer := newTracedBus(func(descriptor commandment.OperationDescriptor) bool {
+		decisions = append(decisions, descriptor.Type)
+		return descriptor.Type != "ParentOperation"
+	})
+
+	runParent(context.Background(), t, bus)
+
+	if len(decisions) != 1 || decisions[0] != "ParentOperation" {
+		t.Errorf("Expected a single sampling decision at the root, got %v", decisions)
+	}
+	if spans := tracer.Spans(); len(spans) != 0 {
+		t.Errorf("Expected children to follow the root's decision, got spans %v", spans)
+	}
+}
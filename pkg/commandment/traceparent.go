@@ -0,0 +1,71 @@
+package commandment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// traceParentKey is the context key for the W3C traceparent of the current trace
+const traceParentKey contextKey = "commandment:trace:traceparent"
+
+// WithTraceParent records a W3C traceparent header value, such as one received on
+// an incoming request, for operations executed with ctx. Operations reuse a valid
+// traceparent instead of generating one.
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return context.WithValue(ctx, traceParentKey, traceParent)
+}
+
+// TraceParentFromContext retrieves the W3C traceparent from context. During
+// execution it is the traceparent recorded in the operation's metadata.
+func TraceParentFromContext(ctx context.Context) (string, bool) {
+	traceParent, ok := ctx.Value(traceParentKey).(string)
+	return traceParent, ok
+}
+
+// resolveTraceParent returns the valid traceparent carried by ctx, or generates
+// one for a new trace whose sampled flag follows any sampling decision in ctx.
+func resolveTraceParent(ctx context.Context) string {
+	if traceParent, ok := TraceParentFromContext(ctx); ok && validTraceParent(traceParent) {
+		return traceParent
+	}
+	flags := "01"
+	if sampled, ok := SampledFromContext(ctx); ok && !sampled {
+		flags = "00"
+	}
+	return "00-" + randomHex(16) + "-" + randomHex(8) + "-" + flags
+}
+
+// validTraceParent reports whether s is a well-formed version 00 traceparent:
+// version, 32-digit trace ID, 16-digit parent ID and flags, in lowercase hex, with
+// non-zero IDs.
+func validTraceParent(s string) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return false
+	}
+	for i, length := range []int{2, 32, 16, 2} {
+		if len(parts[i]) != length || !isLowerHex(parts[i]) {
+			return false
+		}
+	}
+	return strings.Trim(parts[1], "0") != "" && strings.Trim(parts[2], "0") != ""
+}
+
+func isLowerHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		panic("failed to generate random bytes for traceparent: " + err.Error())
+	}
+	return hex.EncodeToString(bytes)
+}
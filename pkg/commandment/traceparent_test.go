@@ -0,0 +1,86 @@
+package commandment_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+var traceParentPattern = regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// Service reporting the traceparent it observes
+type TraceParentService struct {
+	seen string
+}
+
+func (s *TraceParentService) DoSomething(ctx context.Context, input string) (string, error) {
+	s.seen, _ = commandment.TraceParentFromContext(ctx)
+	return input, nil
+}
+
+func executeTraced(ctx context.Context, t *testing.T) (*TestOperation, *TraceParentService) {
+	t.Helper()
+	service := &TraceParentService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(ctx); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	return op, service
+}
+
+func TestSuppliedTraceParentPreserved(t *testing.T) {
+	supplied := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := commandment.WithTraceParent(context.Background(), supplied)
+
+	op, service := executeTraced(ctx, t)
+
+	if op.Metadata().TraceParent != supplied {
+		t.Errorf("Expected metadata traceparent %q, got %q", supplied, op.Metadata().TraceParent)
+	}
+	if service.seen != supplied {
+		t.Errorf("Expected service to see traceparent %q, got %q", supplied, service.seen)
+	}
+}
+
+func TestGeneratedTraceParentWellFormed(t *testing.T) {
+	op, service := executeTraced(context.Background(), t)
+
+	traceParent := op.Metadata().TraceParent
+	if !traceParentPattern.MatchString(traceParent) {
+		t.Errorf("Expected well-formed traceparent, got %q", traceParent)
+	}
+	if service.seen != traceParent {
+		t.Errorf("Expected service to see the generated traceparent, got %q", service.seen)
+	}
+}
+
+func TestInvalidTraceParentReplaced(t *testing.T) {
+	for _, invalid := range []string{
+		"garbage",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+	} {
+		op, _ := executeTraced(commandment.WithTraceParent(context.Background(), invalid), t)
+		traceParent := op.Metadata().TraceParent
+		if traceParent == invalid || !traceParentPattern.MatchString(traceParent) {
+			t.Errorf("Expected %q to be replaced with a generated traceparent, got %q", invalid, traceParent)
+		}
+	}
+}
+
+func TestGeneratedTraceParentFollowsSamplingDecision(t *testing.T) {
+	op, _ := executeTraced(commandment.WithSampled(context.Background(), false), t)
+
+	if flags := op.Metadata().TraceParent[53:]; flags != "00" {
+		t.Errorf("Expected unsampled trace flags %q, got %q", "00", flags)
+	}
+}
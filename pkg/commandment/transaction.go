@@ -0,0 +1,108 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Undoable is implemented by commands that can compensate for their own effect
+// after executing successfully.
+type Undoable interface {
+	Undo(ctx context.Context) error
+}
+
+// Tx executes the operations of a Transaction, remembering those to compensate.
+type Tx struct {
+	ctx      context.Context
+	bus      *OperationBus
+	executed []compensation
+}
+
+// compensation reverses the effect of one successfully executed operation.
+type compensation struct {
+	typeName string
+	id       string
+	logger   Logger
+	undo     func(ctx context.Context) error
+}
+
+// newCompensation returns a compensation running undo for op, logged to the logger
+// of the bus that created op, or to logger for operations created without a bus.
+func newCompensation(op any, undo func(ctx context.Context) error, logger Logger) compensation {
+	c := compensation{typeName: operationTypeName(op), logger: logger, undo: undo}
+	if m, ok := op.(interface{ Metadata() OperationMetadata }); ok {
+		meta := m.Metadata()
+		c.id = meta.UUID
+		if meta.state != nil && meta.state.bus != nil {
+			c.logger = meta.state.bus.logger
+		}
+	}
+	return c
+}
+
+// compensate runs compensations in reverse order, logging each one and continuing
+// past failures so every operation gets a chance to compensate. It is shared by
+// transactions, sagas, pipelines and the undo stack, and returns the compensation
+// failures joined.
+func compensate(ctx context.Context, compensations []compensation) error {
+	var errs []error
+	for i := len(compensations) - 1; i >= 0; i-- {
+		c := compensations[i]
+		fields := []any{"operation_type", c.typeName, "operation_id", c.id}
+		if err := c.undo(ctx); err != nil {
+			c.logger.Error("Operation compensation failed", append(fields, "error", err)...)
+			errs = append(errs, fmt.Errorf("undoing %s: %w", c.typeName, err))
+			continue
+		}
+		c.logger.Info("Operation compensated", fields...)
+	}
+	return errors.Join(errs...)
+}
+
+// rollback compensates a failed transaction or pipeline. Compensations run on ctx
+// detached from its cancellation, so a transaction aborted by cancellation or its
+// deadline still reverses the commands that already ran.
+func rollback(ctx context.Context, compensations []compensation) error {
+	return compensate(context.WithoutCancel(ctx), compensations)
+}
+
+// Execute runs op with the transaction's context, as ExecuteAny does. If op
+// succeeds and implements Undoable, it is compensated should the transaction fail.
+func (tx *Tx) Execute(op any) (any, error) {
+	result, err := tx.bus.ExecuteAny(tx.ctx, op)
+	if err != nil {
+		return result, err
+	}
+	if undoable, ok := op.(Undoable); ok {
+		tx.executed = append(tx.executed, newCompensation(op, undoable.Undo, tx.bus.logger))
+	}
+	return result, nil
+}
+
+// ExecuteWithCompensation runs op as Execute does, compensating it with compensate,
+// which receives op's result, should the transaction fail. It suits commands that
+// don't implement Undoable, or whose compensation depends on the saga.
+func (tx *Tx) ExecuteWithCompensation(op any, compensate func(ctx context.Context, result any) error) (any, error) {
+	result, err := tx.bus.ExecuteAny(tx.ctx, op)
+	if err != nil {
+		return result, err
+	}
+	tx.executed = append(tx.executed, newCompensation(op, func(ctx context.Context) error {
+		return compensate(ctx, result)
+	}, tx.bus.logger))
+	return result, nil
+}
+
+// Transaction runs fn, a saga of operations executed through tx. If fn returns an
+// error, the commands it executed successfully are compensated in reverse order,
+// each compensation being logged, and the returned error joins fn's error with any
+// compensation failures. Compensations run even if ctx is cancelled or past its
+// deadline, on a context keeping its values but not its cancellation.
+func (b *OperationBus) Transaction(ctx context.Context, fn func(tx *Tx) error) error {
+	tx := &Tx{ctx: ctx, bus: b}
+	if err := fn(tx); err != nil {
+		return errors.Join(err, rollback(ctx, tx.executed))
+	}
+	return nil
+}
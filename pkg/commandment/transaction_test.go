@@ -0,0 +1,155 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func reserveIn(t *testing.T, tx *commandment.Tx, bus *commandment.OperationBus, item string) error {
+	t.Helper()
+	op, err := commandment.CreateOperation[*ReserveCommand](bus, item)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	_, err = tx.Execute(op)
+	return err
+}
+
+func TestTransactionCompensatesEarlierCommandsOnFailure(t *testing.T) {
+	bus, inventory := newInventoryBus()
+
+	err := bus.Transaction(context.Background(), func(tx *commandment.Tx) error {
+		for _, item := range []string{"first", "second", "unavailable-third", "fourth"} {
+			if err := reserveIn(t, tx, bus, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "unavailable-third") {
+		t.Fatalf("Expected transaction to fail on the unavailable item, got %v", err)
+	}
+
+	expectedLog := []string{"reserve:first", "reserve:second", "release:second", "release:first"}
+	if !reflect.DeepEqual(inventory.log, expectedLog) {
+		t.Errorf("Expected log %v, got %v", expectedLog, inventory.log)
+	}
+	if len(inventory.reserved) != 0 {
+		t.Errorf("Expected no reservations left after rollback, got %v", inventory.reserved)
+	}
+}
+
+func TestTransactionCompensatesAfterCancellation(t *testing.T) {
+	bus, inventory := newInventoryBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := bus.Transaction(ctx, func(tx *commandment.Tx) error {
+		if err := reserveIn(t, tx, bus, "first"); err != nil {
+			return err
+		}
+		cancel()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancellation, got %v", err)
+	}
+
+	expectedLog := []string{"reserve:first", "release:first"}
+	if !reflect.DeepEqual(inventory.log, expectedLog) {
+		t.Errorf("Expected log %v, got %v", expectedLog, inventory.log)
+	}
+	if len(inventory.reserved) != 0 {
+		t.Errorf("Expected no reservations left after rollback, got %v", inventory.reserved)
+	}
+}
+
+func TestTransactionCommitsWithoutCompensation(t *testing.T) {
+	bus, inventory := newInventoryBus()
+
+	err := bus.Transaction(context.Background(), func(tx *commandment.Tx) error {
+		if err := reserveIn(t, tx, bus, "first"); err != nil {
+			return err
+		}
+		return reserveIn(t, tx, bus, "second")
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	expectedLog := []string{"reserve:first", "reserve:second"}
+	if !reflect.DeepEqual(inventory.log, expectedLog) {
+		t.Errorf("Expected log %v, got %v", expectedLog, inventory.log)
+	}
+}
+
+func newSagaBus() (*commandment.OperationBus, *RecordService, *RecordingLogger) {
+	records := &RecordService{records: map[string]string{"k": "v1"}}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, records)
+	commandment.RegisterService(registry, &InventoryService{reserved: make(map[string]bool)})
+	logger := &RecordingLogger{}
+	return commandment.NewOperationBus(registry, logger), records, logger
+}
+
+func TestTransactionRunsCompensationFuncWhenLaterStepFails(t *testing.T) {
+	bus, records, logger := newSagaBus()
+
+	err := bus.Transaction(context.Background(), func(tx *commandment.Tx) error {
+		write, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+		if err != nil {
+			t.Fatalf("Failed to create command: %v", err)
+		}
+		if _, err := tx.ExecuteWithCompensation(write, func(ctx context.Context, result any) error {
+			records.records["k"] = "v1"
+			return nil
+		}); err != nil {
+			return err
+		}
+		return reserveIn(t, tx, bus, "unavailable-item")
+	})
+	if err == nil || !strings.Contains(err.Error(), "unavailable-item") {
+		t.Fatalf("Expected the second step's failure, got %v", err)
+	}
+
+	if records.records["k"] != "v1" {
+		t.Errorf("Expected first step compensated, got %q", records.records["k"])
+	}
+	entry, ok := logger.Find("Operation compensated")
+	if !ok {
+		t.Fatal("Expected the compensation to be logged")
+	}
+	if typeName, _ := entry.Field("operation_type"); typeName != "WriteRecordCommand" {
+		t.Errorf("Expected compensation logged for WriteRecordCommand, got %v", typeName)
+	}
+}
+
+func TestTransactionSurfacesOriginalAndCompensationErrors(t *testing.T) {
+	bus, _, logger := newSagaBus()
+	compensationErr := errors.New("compensation failed")
+
+	err := bus.Transaction(context.Background(), func(tx *commandment.Tx) error {
+		write, err := commandment.CreateOperation[*WriteRecordCommand](bus, WriteRecordParams{Key: "k", Value: "v2"})
+		if err != nil {
+			t.Fatalf("Failed to create command: %v", err)
+		}
+		if _, err := tx.ExecuteWithCompensation(write, func(ctx context.Context, result any) error {
+			return compensationErr
+		}); err != nil {
+			return err
+		}
+		return reserveIn(t, tx, bus, "unavailable-item")
+	})
+
+	if !errors.Is(err, compensationErr) || !strings.Contains(err.Error(), "unavailable-item") {
+		t.Errorf("Expected both the step and compensation errors, got %v", err)
+	}
+	if _, ok := logger.Find("Operation compensation failed"); !ok {
+		t.Error("Expected the failed compensation to be logged")
+	}
+}
@@ -0,0 +1,96 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrNotUndoable is returned by ExecuteWithUndo for operations that don't implement
+// Undoable. The operation is not executed.
+var ErrNotUndoable = errors.New("operation is not undoable")
+
+// ErrUndoStackEmpty is returned by UndoLast when there is nothing to undo.
+var ErrUndoStackEmpty = errors.New("undo stack empty")
+
+// DefaultUndoCapacity is the number of commands a bus undo stack holds unless set
+// with WithUndoCapacity.
+const DefaultUndoCapacity = 100
+
+// WithUndoCapacity bounds the bus undo stack to the capacity most recent commands
+// executed with ExecuteWithUndo; once it is full, recording a command forgets the
+// oldest one. A capacity below one disables the undo stack.
+func WithUndoCapacity(capacity int) Option {
+	return func(b *OperationBus) {
+		b.undo = newUndoStack(capacity)
+	}
+}
+
+// undoStack holds the commands executed with ExecuteWithUndo, most recent last.
+type undoStack struct {
+	mu       sync.Mutex
+	capacity int
+	entries  []Undoable
+}
+
+func newUndoStack(capacity int) *undoStack {
+	return &undoStack{capacity: capacity}
+}
+
+// push records op, dropping the oldest entry when the stack is full.
+func (s *undoStack) push(op Undoable) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.capacity < 1 {
+		return
+	}
+	if len(s.entries) == s.capacity {
+		s.entries = slices.Delete(s.entries, 0, 1)
+	}
+	s.entries = append(s.entries, op)
+}
+
+func (s *undoStack) pop() (Undoable, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) == 0 {
+		return nil, false
+	}
+	op := s.entries[len(s.entries)-1]
+	s.entries = s.entries[:len(s.entries)-1]
+	return op, true
+}
+
+// ExecuteWithUndo executes op and, if it succeeds, records it on the undo stack of
+// bus for UndoLast. Operations that don't implement Undoable are refused with
+// ErrNotUndoable. Dry runs are not recorded.
+func ExecuteWithUndo[TResult any](ctx context.Context, bus *OperationBus, op Operation[TResult]) (TResult, error) {
+	undoable, ok := op.(Undoable)
+	if !ok {
+		var zero TResult
+		return zero, fmt.Errorf("%w: %s", ErrNotUndoable, operationTypeName(op))
+	}
+	result, err := op.Execute(ctx)
+	if err != nil || IsDryRun(ctx) {
+		return result, err
+	}
+	bus.undo.push(undoable)
+	return result, nil
+}
+
+// UndoLast undoes the most recent command executed with ExecuteWithUndo and removes
+// it from the undo stack. It returns ErrUndoStackEmpty if there is nothing to undo.
+// A command whose Undo fails stays on the stack so it can be retried.
+func (b *OperationBus) UndoLast(ctx context.Context) error {
+	op, ok := b.undo.pop()
+	if !ok {
+		return ErrUndoStackEmpty
+	}
+	if err := compensate(ctx, []compensation{newCompensation(op, op.Undo, b.logger)}); err != nil {
+		b.undo.push(op)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+func reserveWithUndo(t *testing.T, bus *commandment.OperationBus, item string) {
+	t.Helper()
+	op, err := commandment.CreateOperation[*ReserveCommand](bus, item)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := commandment.ExecuteWithUndo(context.Background(), bus, op); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+}
+
+func TestUndoLastUndoesInReverseOrder(t *testing.T) {
+	bus, inventory := newInventoryBus()
+	reserveWithUndo(t, bus, "first")
+	reserveWithUndo(t, bus, "second")
+
+	for range 2 {
+		if err := bus.UndoLast(context.Background()); err != nil {
+			t.Fatalf("Undo failed: %v", err)
+		}
+	}
+
+	expectedLog := []string{"reserve:first", "reserve:second", "release:second", "release:first"}
+	if !reflect.DeepEqual(inventory.log, expectedLog) {
+		t.Errorf("Expected log %v, got %v", expectedLog, inventory.log)
+	}
+	if err := bus.UndoLast(context.Background()); !errors.Is(err, commandment.ErrUndoStackEmpty) {
+		t.Errorf("Expected ErrUndoStackEmpty once everything is undone, got %v", err)
+	}
+}
+
+func TestUndoLastOnEmptyStack(t *testing.T) {
+	bus, _ := newInventoryBus()
+
+	if err := bus.UndoLast(context.Background()); !errors.Is(err, commandment.ErrUndoStackEmpty) {
+		t.Errorf("Expected ErrUndoStackEmpty, got %v", err)
+	}
+}
+
+func TestExecuteWithUndoRefusesNonUndoable(t *testing.T) {
+	service := &CountingService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := commandment.ExecuteWithUndo(context.Background(), bus, op); !errors.Is(err, commandment.ErrNotUndoable) {
+		t.Fatalf("Expected ErrNotUndoable, got %v", err)
+	}
+	if calls := service.calls.Load(); calls != 0 {
+		t.Errorf("Expected non-undoable operation not to execute, got %d calls", calls)
+	}
+}
+
+func TestUndoStackForgetsOldestBeyondCapacity(t *testing.T) {
+	inventory := &InventoryService{reserved: make(map[string]bool)}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, inventory)
+	bus := commandment.NewOperationBus(registry, &TestLogger{}, commandment.WithUndoCapacity(2))
+	for _, item := range []string{"first", "second", "third"} {
+		reserveWithUndo(t, bus, item)
+	}
+
+	for range 2 {
+		if err := bus.UndoLast(context.Background()); err != nil {
+			t.Fatalf("Undo failed: %v", err)
+		}
+	}
+	if err := bus.UndoLast(context.Background()); !errors.Is(err, commandment.ErrUndoStackEmpty) {
+		t.Errorf("Expected the oldest command to be forgotten, got %v", err)
+	}
+	if !inventory.reserved["first"] {
+		t.Error("Expected the forgotten reservation to stay")
+	}
+}
+
+func TestChildBusHasItsOwnUndoStack(t *testing.T) {
+	parent, inventory := newInventoryBus()
+	child := parent.With()
+	reserveWithUndo(t, child, "child")
+
+	if err := parent.UndoLast(context.Background()); !errors.Is(err, commandment.ErrUndoStackEmpty) {
+		t.Errorf("Expected the parent's undo stack to be empty, got %v", err)
+	}
+	if err := child.UndoLast(context.Background()); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if inventory.reserved["child"] {
+		t.Error("Expected the child's reservation to be undone")
+	}
+}
@@ -0,0 +1,68 @@
+package commandment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidOperation wraps the error returned by a Validatable operation that
+// fails validation.
+var ErrInvalidOperation = errors.New("invalid operation")
+
+// Validatable is implemented by operations that check their params before
+// executing. ExecuteOperation calls Validate first and, if it fails, returns the
+// error without running middleware or the business logic.
+type Validatable interface {
+	Validate() error
+}
+
+// validate runs op's Validate method, if it has one.
+func validate(op any) error {
+	v, ok := op.(Validatable)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOperation, err)
+	}
+	return nil
+}
+
+// ValidationError describes a domain validation failure for a single field.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validated pairs an operation result with the domain validation errors found
+// while producing it. Validation errors are part of a successful result; a
+// returned Go error still signals an execution failure.
+type Validated[T any] struct {
+	Value  T                 `json:"value"`
+	Errors []ValidationError `json:"errors,omitempty"`
+}
+
+// Valid reports whether the result carries no validation errors.
+func (v Validated[T]) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// ExecuteValidated wraps ExecuteOperation for operations returning Validated results,
+// logging a warning when the result carries validation errors but no execution error.
+func ExecuteValidated[T any](ctx context.Context, op OperationWithMetadata, businessLogic func(context.Context) (Validated[T], error)) (Validated[T], error) {
+	result, err := ExecuteOperation(ctx, op, businessLogic)
+	if err == nil && !result.Valid() {
+		fields := make([]string, 0, len(result.Errors))
+		for _, validationErr := range result.Errors {
+			fields = append(fields, validationErr.Field)
+		}
+		op.GetLogger().Warn("Operation returned validation errors",
+			"operation_type", operationTypeName(op),
+			"operation_id", op.GetMetadata().UUID,
+			"validation_error_count", len(result.Errors),
+			"validation_fields", fields,
+		)
+	}
+	return result, err
+}
@@ -0,0 +1,176 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Params for the validating test operation
+type RenameParams struct {
+	Name string
+}
+
+// Service validating names before renaming
+type RenameService struct {
+	fail bool
+}
+
+func (s *RenameService) Rename(ctx context.Context, params RenameParams) (commandment.Validated[string], error) {
+	if s.fail {
+		return commandment.Validated[string]{}, errors.New("rename failed")
+	}
+	if params.Name == "" {
+		return commandment.Validated[string]{
+			Errors: []commandment.ValidationError{{Field: "Name", Message: "Name is required"}},
+		}, nil
+	}
+	return commandment.Validated[string]{Value: params.Name}, nil
+}
+
+// Test operation returning a Validated result
+type RenameCommand struct {
+	Params  RenameParams
+	Service *RenameService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (c *RenameCommand) Execute(ctx context.Context) (commandment.Validated[string], error) {
+	return commandment.ExecuteValidated(ctx, c, func(ctx context.Context) (commandment.Validated[string], error) {
+		return c.Service.Rename(ctx, c.Params)
+	})
+}
+
+func (c *RenameCommand) Metadata() commandment.OperationMetadata {
+	return c.Meta
+}
+
+func (c *RenameCommand) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{
+		Type:     "RenameCommand",
+		Params:   c.Params,
+		Metadata: c.Meta,
+	}
+}
+
+func (c *RenameCommand) GetMetadata() *commandment.OperationMetadata { return &c.Meta }
+func (c *RenameCommand) GetLogger() commandment.Logger               { return c.Logger }
+
+func executeRename(t *testing.T, service *RenameService, params RenameParams) (commandment.Validated[string], *RecordingLogger, error) {
+	t.Helper()
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService(registry, service)
+
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+
+	cmd, err := commandment.CreateOperation[*RenameCommand](bus, params)
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	result, err := cmd.Execute(context.Background())
+	return result, logger, err
+}
+
+func TestValidatedResultWithErrorsLogsWarning(t *testing.T) {
+	result, logger, err := executeRename(t, &RenameService{}, RenameParams{})
+	if err != nil {
+		t.Fatalf("Validation errors should not be returned as a Go error: %v", err)
+	}
+	if result.Valid() {
+		t.Fatal("Expected result to carry validation errors")
+	}
+
+	entry, ok := logger.Find("Operation returned validation errors")
+	if !ok {
+		t.Fatal("Expected validation warning to be logged")
+	}
+	if entry.Level != "warn" {
+		t.Errorf("Expected warn level, got %q", entry.Level)
+	}
+	if count, _ := entry.Field("validation_error_count"); count != 1 {
+		t.Errorf("Expected validation_error_count 1, got %v", count)
+	}
+}
+
+func TestValidatedResultWithoutErrorsDoesNotWarn(t *testing.T) {
+	result, logger, err := executeRename(t, &RenameService{}, RenameParams{Name: "renamed"})
+	if err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+	if !result.Valid() || result.Value != "renamed" {
+		t.Errorf("Expected valid result %q, got %+v", "renamed", result)
+	}
+	if warnings := logger.Entries("warn"); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestValidatedResultWithGoErrorDoesNotWarn(t *testing.T) {
+	_, logger, err := executeRename(t, &RenameService{fail: true}, RenameParams{})
+	if err == nil {
+		t.Fatal("Expected execution error")
+	}
+	if warnings := logger.Entries("warn"); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+// Test operation validating its params before execution
+type ValidatingOperation struct {
+	TestOperation
+}
+
+func (op *ValidatingOperation) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, op, func(ctx context.Context) (string, error) {
+		return op.Service.DoSomething(ctx, op.Params)
+	})
+}
+
+func (op *ValidatingOperation) Validate() error {
+	if op.Params == "" {
+		return errors.New("params must not be empty")
+	}
+	return nil
+}
+
+func TestInvalidOperationNeverCallsService(t *testing.T) {
+	service := &CountingService{}
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, service)
+	logger := &RecordingLogger{}
+	bus := commandment.NewOperationBus(registry, logger)
+	var middlewareCalled bool
+	bus.Use(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		middlewareCalled = true
+		return next
+	})
+
+	op, err := commandment.CreateOperation[*ValidatingOperation](bus, "")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); !errors.Is(err, commandment.ErrInvalidOperation) {
+		t.Fatalf("Expected ErrInvalidOperation, got %v", err)
+	}
+	if calls := service.calls.Load(); calls != 0 {
+		t.Errorf("Expected invalid operation not to reach the service, got %d calls", calls)
+	}
+	if middlewareCalled {
+		t.Error("Expected invalid operation not to run middleware")
+	}
+	if _, ok := logger.Find("Operation execution rejected"); !ok {
+		t.Error("Expected validation failure to be logged")
+	}
+
+	op.Params = "valid"
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Expected valid operation to execute: %v", err)
+	}
+	if calls := service.calls.Load(); calls != 1 {
+		t.Errorf("Expected valid operation to reach the service once, got %d calls", calls)
+	}
+}
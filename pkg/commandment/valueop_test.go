@@ -0,0 +1,178 @@
+package commandment_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Test operation with value receivers, created as a value
+type ValueOperation struct {
+	Params  string
+	Service TestService
+	Meta    commandment.OperationMetadata
+	Logger  commandment.Logger
+}
+
+func (op ValueOperation) Execute(ctx context.Context) (string, error) {
+	return commandment.ExecuteOperation(ctx, op, func(ctx context.Context) (string, error) {
+		return op.Service.DoSomething(ctx, op.Params)
+	})
+}
+
+func (op ValueOperation) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op ValueOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "ValueOperation", Params: op.Params, Metadata: op.Meta}
+}
+
+func (op ValueOperation) GetMetadata() *commandment.OperationMetadata { return &op.Meta }
+func (op ValueOperation) GetLogger() commandment.Logger               { return op.Logger }
+
+// Test operation lacking the Logger field
+type LoggerlessOperation struct {
+	Params  string
+	Service TestService
+	Meta    commandment.OperationMetadata
+}
+
+func (op *LoggerlessOperation) Execute(ctx context.Context) (string, error) {
+	return op.Service.DoSomething(ctx, op.Params)
+}
+
+func (op *LoggerlessOperation) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op *LoggerlessOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "LoggerlessOperation", Params: op.Params, Metadata: op.Meta}
+}
+
+// Test operation created only by the layout cache test
+type LayoutOperation struct {
+	ValueOperation
+}
+
+// Test operation lacking a Service field
+type ServicelessOperation struct {
+	Params string
+	Meta   commandment.OperationMetadata
+	Logger commandment.Logger
+}
+
+func (op *ServicelessOperation) Execute(ctx context.Context) (string, error) {
+	return op.Params, nil
+}
+
+func (op *ServicelessOperation) Metadata() commandment.OperationMetadata {
+	return op.Meta
+}
+
+func (op *ServicelessOperation) Descriptor() commandment.OperationDescriptor {
+	return commandment.OperationDescriptor{Type: "ServicelessOperation", Params: op.Params, Metadata: op.Meta}
+}
+
+func newValueOpBus(opts ...commandment.Option) *commandment.OperationBus {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &MockTestService{})
+	return commandment.NewOperationBus(registry, &TestLogger{}, opts...)
+}
+
+func TestCreateOperationAcceptsValueAndPointerTypes(t *testing.T) {
+	var seen []string
+	bus := newValueOpBus(commandment.WithMiddleware(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			descriptor, _ := commandment.OperationDescriptorFromContext(ctx)
+			seen = append(seen, descriptor.Type)
+			return next(ctx)
+		}
+	}))
+
+	value, err := commandment.CreateOperation[ValueOperation](bus, "value")
+	if err != nil {
+		t.Fatalf("Failed to create value operation: %v", err)
+	}
+	if value.Meta.UUID == "" || value.Service == nil || value.Logger == nil {
+		t.Fatalf("Expected value operation fields to be filled, got %+v", value)
+	}
+	pointer, err := commandment.CreateOperation[*TestOperation](bus, "pointer")
+	if err != nil {
+		t.Fatalf("Failed to create pointer operation: %v", err)
+	}
+
+	for _, op := range []commandment.Operation[string]{value, pointer} {
+		if _, err := op.Execute(context.Background()); err != nil {
+			t.Fatalf("Execution of %T failed: %v", op, err)
+		}
+	}
+	if len(seen) != 2 || seen[0] != "ValueOperation" || seen[1] != "TestOperation" {
+		t.Errorf("Expected bus middleware to wrap both operations, saw %v", seen)
+	}
+}
+
+func TestCreateOperationRejectsMissingField(t *testing.T) {
+	bus := newValueOpBus()
+
+	_, err := commandment.CreateOperation[*LoggerlessOperation](bus, "x")
+	if !errors.Is(err, commandment.ErrInvalidOperationType) {
+		t.Errorf("Expected ErrInvalidOperationType for missing Logger field, got %v", err)
+	}
+}
+
+func TestCreateOperationRejectsMissingServiceField(t *testing.T) {
+	bus := newValueOpBus()
+
+	_, err := commandment.CreateOperation[*ServicelessOperation](bus, "x")
+	if !errors.Is(err, commandment.ErrInvalidOperationType) || !strings.Contains(err.Error(), "has no Service field") {
+		t.Errorf("Expected missing Service field error, got %v", err)
+	}
+}
+
+func TestOperationLayoutCachedAfterFirstCreation(t *testing.T) {
+	bus := newValueOpBus()
+	opType := reflect.TypeFor[*LayoutOperation]()
+	commandment.ForgetOperationLayouts()
+	if commandment.OperationLayoutCached(opType) {
+		t.Fatal("Expected no cached layout before first creation")
+	}
+
+	for range 2 {
+		op, err := commandment.CreateOperation[*LayoutOperation](bus, "x")
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		if op.Params != "x" || op.Service == nil || op.Logger == nil || op.Meta.UUID == "" {
+			t.Errorf("Expected all fields filled, got %+v", op)
+		}
+	}
+	if !commandment.OperationLayoutCached(opType) {
+		t.Error("Expected layout cached after first creation")
+	}
+}
+
+func BenchmarkCreateOperation(b *testing.B) {
+	bus := newValueOpBus()
+	create := func(b *testing.B) {
+		if _, err := commandment.CreateOperation[*TestOperation](bus, "x"); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		for b.Loop() {
+			commandment.ForgetOperationLayouts()
+			create(b)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for b.Loop() {
+			create(b)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package commandment
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// warningsKey is the context key for the accumulated operation warnings
+const warningsKey contextKey = "commandment:warnings"
+
+// warnings collects the non-fatal warnings raised during a request.
+type warnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// WithWarnings returns a context collecting the warnings raised by operations
+// executed with it, and by the operations they execute, so the caller can read them
+// with WarningsFromContext after execution. Executions without a collector in their
+// context get their own, visible to middleware.
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsKey, &warnings{})
+}
+
+// AddWarning records a non-fatal warning, such as a deprecated parameter or a
+// degraded dependency. It is a no-op when ctx has no warnings collector.
+func AddWarning(ctx context.Context, message string) {
+	w, ok := ctx.Value(warningsKey).(*warnings)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, message)
+}
+
+// WarningsFromContext returns the warnings recorded so far, in order.
+func WarningsFromContext(ctx context.Context) []string {
+	w, ok := ctx.Value(warningsKey).(*warnings)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.messages)
+}
+
+// withExecutionWarnings ensures ctx has a warnings collector.
+func withExecutionWarnings(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(warningsKey).(*warnings); ok {
+		return ctx
+	}
+	return WithWarnings(ctx)
+}
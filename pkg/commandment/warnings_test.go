@@ -0,0 +1,70 @@
+package commandment_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/davidlee/commandment/pkg/commandment"
+)
+
+// Service raising warnings while succeeding
+type WarningService struct{}
+
+func (s *WarningService) DoSomething(ctx context.Context, input string) (string, error) {
+	commandment.AddWarning(ctx, "input "+input+" is deprecated")
+	commandment.AddWarning(ctx, "served from replica")
+	return "result: " + input, nil
+}
+
+func TestWarningsReadableByMiddlewareAfterExecution(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &WarningService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+
+	var header []string
+	bus.Use(func(next commandment.ExecuteFunc) commandment.ExecuteFunc {
+		return func(ctx context.Context) (any, error) {
+			result, err := next(ctx)
+			header = commandment.WarningsFromContext(ctx)
+			return result, err
+		}
+	})
+
+	op, err := commandment.CreateOperation[*TestOperation](bus, "x")
+	if err != nil {
+		t.Fatalf("Failed to create operation: %v", err)
+	}
+	if _, err := op.Execute(context.Background()); err != nil {
+		t.Fatalf("Operation execution failed: %v", err)
+	}
+
+	expected := []string{"input x is deprecated", "served from replica"}
+	if !reflect.DeepEqual(header, expected) {
+		t.Errorf("Expected middleware to read warnings %v, got %v", expected, header)
+	}
+}
+
+func TestWarningsReadableByCallerWithCollector(t *testing.T) {
+	registry := commandment.NewServiceRegistry()
+	commandment.RegisterService[TestService](registry, &WarningService{})
+	bus := commandment.NewOperationBus(registry, &TestLogger{})
+	ctx := commandment.WithWarnings(context.Background())
+
+	for _, input := range []string{"a", "b"} {
+		op, err := commandment.CreateOperation[*TestOperation](bus, input)
+		if err != nil {
+			t.Fatalf("Failed to create operation: %v", err)
+		}
+		if _, err := op.Execute(ctx); err != nil {
+			t.Fatalf("Operation execution failed: %v", err)
+		}
+	}
+
+	if got := commandment.WarningsFromContext(ctx); len(got) != 4 || got[2] != "input b is deprecated" {
+		t.Errorf("Expected warnings from both executions, got %v", got)
+	}
+	if got := commandment.WarningsFromContext(context.Background()); got != nil {
+		t.Errorf("Expected no warnings without a collector, got %v", got)
+	}
+}